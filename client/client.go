@@ -0,0 +1,233 @@
+// Package client — Go-клиент HTTP API сервиса назначения ревьюверов.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"pull-request-api.com/internal/models"
+)
+
+// Client вызывает API по HTTP. Безопасен для параллельного использования.
+type Client struct {
+	baseURL string
+	http    *http.Client
+	token   TokenSource
+	retry   RetryPolicy
+}
+
+// TokenSource возвращает JWT для очередного запроса, например обновляя
+// истёкший. Пустая строка — запрос без заголовка Authorization.
+type TokenSource func(ctx context.Context) (string, error)
+
+// RetryPolicy задаёт повторы при временных ошибках: сетевых, 429, 502–504
+// и IDEMPOTENCY_IN_PROGRESS. GET и PATCH повторяются как есть, POST — с
+// заголовком Idempotency-Key, по которому сервер не выполнит запрос дважды.
+type RetryPolicy struct {
+	// MaxAttempts — число попыток вместе с первой; 1 и меньше — без повторов.
+	MaxAttempts int
+	// MinBackoff — пауза перед первым повтором, дальше она удваивается
+	// до MaxBackoff. Retry-After из ответа имеет приоритет.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy используется, если не задан WithRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, MinBackoff: 100 * time.Millisecond, MaxBackoff: 2 * time.Second}
+
+// Option настраивает Client.
+type Option func(*Client)
+
+// WithHTTPClient задаёт HTTP-клиент (по умолчанию — с таймаутом 30 секунд).
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// WithToken передаёт JWT в заголовке Authorization: Bearer.
+func WithToken(token string) Option {
+	return WithTokenSource(func(context.Context) (string, error) { return token, nil })
+}
+
+// WithTokenSource запрашивает JWT перед каждой попыткой запроса.
+func WithTokenSource(ts TokenSource) Option {
+	return func(c *Client) { c.token = ts }
+}
+
+// WithRetryPolicy задаёт повторы; RetryPolicy{} отключает их.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) { c.retry = p }
+}
+
+// New создаёт клиент для сервиса по адресу baseURL (например, http://localhost:8080).
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    &http.Client{Timeout: 30 * time.Second},
+		retry:   DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+type idempotencyKeyCtx struct{}
+
+// WithIdempotencyKey задаёт Idempotency-Key для POST-запросов с этим
+// контекстом. Без него клиент генерирует случайный ключ на каждый вызов;
+// свой ключ нужен, чтобы повторить вызов после перезапуска процесса.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtx{}, key)
+}
+
+// request — тело запроса: JSON-значение или готовые байты с типом содержимого.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	json        any
+	body        []byte
+	contentType string
+}
+
+func (c *Client) do(ctx context.Context, req request, out any) error {
+	body, contentType := req.body, req.contentType
+	if req.json != nil {
+		data, err := json.Marshal(req.json)
+		if err != nil {
+			return err
+		}
+		body, contentType = data, "application/json"
+	}
+
+	u := c.baseURL + req.path
+	if len(req.query) > 0 {
+		u += "?" + req.query.Encode()
+	}
+
+	var idempotencyKey string
+	if req.method == http.MethodPost {
+		idempotencyKey, _ = ctx.Value(idempotencyKeyCtx{}).(string)
+		if idempotencyKey == "" && c.retry.MaxAttempts > 1 {
+			idempotencyKey = newIdempotencyKey()
+		}
+	}
+
+	backoff := c.retry.MinBackoff
+	for attempt := 1; ; attempt++ {
+		httpReq, err := http.NewRequestWithContext(ctx, req.method, u, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		httpReq.Header.Set("Accept", "application/json")
+		if contentType != "" {
+			httpReq.Header.Set("Content-Type", contentType)
+		}
+		if idempotencyKey != "" {
+			httpReq.Header.Set("Idempotency-Key", idempotencyKey)
+		}
+		if c.token != nil {
+			token, err := c.token(ctx)
+			if err != nil {
+				return fmt.Errorf("get token: %w", err)
+			}
+			if token != "" {
+				httpReq.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+
+		resp, err := c.http.Do(httpReq)
+		if err == nil && resp.StatusCode < 300 {
+			defer resp.Body.Close()
+			if out == nil {
+				return nil
+			}
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return fmt.Errorf("decode %s %s response: %w", req.method, req.path, err)
+			}
+			return nil
+		}
+
+		var wait time.Duration
+		if err == nil {
+			err = decodeError(resp)
+			resp.Body.Close()
+			wait = retryAfter(resp)
+		}
+		if attempt >= c.retry.MaxAttempts || ctx.Err() != nil || !retryable(err) ||
+			(req.method == http.MethodPost && idempotencyKey == "") {
+			return err
+		}
+
+		wait = max(wait, backoff)
+		backoff = min(backoff*2, c.retry.MaxBackoff)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable сообщает, имеет ли смысл повторить запрос: сетевая ошибка
+// или ответ, при котором сервер запрос не выполнил.
+func retryable(err error) bool {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return apiErr.Code == models.IDEMPOTENCYINPROGRESS
+}
+
+func retryAfter(resp *http.Response) time.Duration {
+	secs, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func decodeError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	apiErr := &Error{StatusCode: resp.StatusCode}
+
+	var errResp models.ErrorResponse
+	if json.Unmarshal(data, &errResp) == nil && errResp.Error.Code != "" {
+		apiErr.Code = errResp.Error.Code
+		apiErr.Message = errResp.Error.Message
+		if errResp.Error.Details != nil {
+			apiErr.Details = *errResp.Error.Details
+		}
+		return apiErr
+	}
+
+	// Ответ не от API (прокси, балансировщик).
+	apiErr.Message = strings.TrimSpace(string(data))
+	if apiErr.Message == "" {
+		apiErr.Message = resp.Status
+	}
+	return apiErr
+}
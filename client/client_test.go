@@ -0,0 +1,253 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	openapi "pull-request-api.com"
+	"pull-request-api.com/client"
+	"pull-request-api.com/internal/api"
+	"pull-request-api.com/internal/models"
+)
+
+var noRetry = client.WithRetryPolicy(client.RetryPolicy{})
+
+// Сервис не нужен: невалидные запросы не доходят до обработчиков.
+func newAPIServer(t *testing.T) *httptest.Server {
+	v, err := api.NewRequestValidator(openapi.Spec)
+	require.NoError(t, err)
+
+	r := chi.NewRouter()
+	r.NotFound(api.NotFound)
+	r.Use(api.ValidateRequests(v))
+	api.HandlerFromMux(api.NewServer(nil), r)
+
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func writeError(w http.ResponseWriter, status int, code models.ErrorResponseErrorCode) {
+	var resp models.ErrorResponse
+	resp.Error.Code = code
+	resp.Error.Message = string(code)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func TestClient_ValidationErrorFromAPI(t *testing.T) {
+	srv := newAPIServer(t)
+	c := client.New(srv.URL, noRetry)
+
+	_, err := c.CreatePullRequest(context.Background(), client.CreatePullRequestRequest{PullRequestId: "pr-1", PullRequestName: "x"})
+	require.ErrorIs(t, err, client.ErrValidation)
+
+	var apiErr *client.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	require.Len(t, apiErr.Details, 1)
+	assert.Equal(t, "author_id", apiErr.Details[0].Field)
+
+	_, err = c.GetPullRequest(context.Background(), "")
+	assert.ErrorIs(t, err, client.ErrValidation)
+}
+
+func TestClient_ErrorCodes(t *testing.T) {
+	cases := []struct {
+		code   models.ErrorResponseErrorCode
+		status int
+		want   error
+	}{
+		{models.NOTFOUND, http.StatusNotFound, client.ErrNotFound},
+		{models.METHODNOTALLOWED, http.StatusMethodNotAllowed, client.ErrMethodNotAllowed},
+		{models.PREXISTS, http.StatusConflict, client.ErrPRExists},
+		{models.PRMERGED, http.StatusConflict, client.ErrPRMerged},
+		{models.NOTASSIGNED, http.StatusConflict, client.ErrNotAssigned},
+		{models.NOCANDIDATE, http.StatusConflict, client.ErrNoCandidate},
+		{models.TEAMEXISTS, http.StatusBadRequest, client.ErrTeamExists},
+		{models.TEAMNOTEMPTY, http.StatusConflict, client.ErrTeamNotEmpty},
+		{models.USERHASHISTORY, http.StatusConflict, client.ErrUserHasHistory},
+		{models.UNAUTHORIZED, http.StatusUnauthorized, client.ErrUnauthorized},
+		{models.IDEMPOTENCYKEYREUSED, http.StatusUnprocessableEntity, client.ErrIdempotencyKeyReused},
+		{models.INTERNALERROR, http.StatusInternalServerError, client.ErrInternal},
+	}
+
+	for _, tc := range cases {
+		t.Run(string(tc.code), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeError(w, tc.status, tc.code)
+			}))
+			defer srv.Close()
+
+			_, err := client.New(srv.URL).MergePullRequest(context.Background(), "pr-1")
+			assert.ErrorIs(t, err, tc.want)
+			assert.EqualError(t, err, string(tc.code)+": "+string(tc.code))
+		})
+	}
+}
+
+func TestClient_NonAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream is down", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	_, err := client.New(srv.URL, noRetry).GetTeam(context.Background(), "backend")
+	var apiErr *client.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Empty(t, apiErr.Code)
+	assert.Equal(t, "HTTP 502: upstream is down", err.Error())
+}
+
+func TestClient_Retries(t *testing.T) {
+	policy := client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+	t.Run("POST with idempotency key", func(t *testing.T) {
+		var keys []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			keys = append(keys, r.Header.Get("Idempotency-Key"))
+			switch len(keys) {
+			case 1:
+				writeError(w, http.StatusServiceUnavailable, models.INTERNALERROR)
+			case 2:
+				writeError(w, http.StatusConflict, models.IDEMPOTENCYINPROGRESS)
+			default:
+				json.NewEncoder(w).Encode(models.PullRequest{PullRequestId: "pr-1", Status: models.PullRequestStatusMERGED})
+			}
+		}))
+		defer srv.Close()
+
+		pr, err := client.New(srv.URL, policy).MergePullRequest(context.Background(), "pr-1")
+		require.NoError(t, err)
+		assert.Equal(t, client.PullRequestStatusMerged, pr.Status)
+		require.Len(t, keys, 3)
+		assert.NotEmpty(t, keys[0])
+		assert.Equal(t, keys[0], keys[1])
+		assert.Equal(t, keys[0], keys[2])
+	})
+
+	t.Run("caller key", func(t *testing.T) {
+		var key string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key = r.Header.Get("Idempotency-Key")
+			json.NewEncoder(w).Encode(models.PullRequest{})
+		}))
+		defer srv.Close()
+
+		ctx := client.WithIdempotencyKey(context.Background(), "merge-pr-1")
+		_, err := client.New(srv.URL, noRetry).MergePullRequest(ctx, "pr-1")
+		require.NoError(t, err)
+		assert.Equal(t, "merge-pr-1", key)
+	})
+
+	t.Run("permanent errors are not retried", func(t *testing.T) {
+		attempts := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			writeError(w, http.StatusConflict, models.NOCANDIDATE)
+		}))
+		defer srv.Close()
+
+		_, err := client.New(srv.URL, policy).ReassignReviewer(context.Background(), client.ReassignRequest{PullRequestId: "pr-1", OldUserId: "u2"})
+		assert.ErrorIs(t, err, client.ErrNoCandidate)
+		assert.Equal(t, 1, attempts)
+	})
+
+	t.Run("gives up after MaxAttempts", func(t *testing.T) {
+		attempts := 0
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			writeError(w, http.StatusTooManyRequests, models.RATELIMITED)
+		}))
+		defer srv.Close()
+
+		_, err := client.New(srv.URL, policy).GetUser(context.Background(), "u1")
+		assert.ErrorIs(t, err, client.ErrRateLimited)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		var (
+			attempts int
+			key      string
+		)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			key = r.Header.Get("Idempotency-Key")
+			writeError(w, http.StatusServiceUnavailable, models.INTERNALERROR)
+		}))
+		defer srv.Close()
+
+		_, err := client.New(srv.URL, noRetry).SetUserActive(context.Background(), "u1", false)
+		assert.Error(t, err)
+		assert.Equal(t, 1, attempts)
+		assert.Empty(t, key)
+	})
+}
+
+func TestClient_TokenSource(t *testing.T) {
+	var auth []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode([]models.TeamNode{})
+	}))
+	defer srv.Close()
+
+	n := 0
+	c := client.New(srv.URL, client.WithTokenSource(func(context.Context) (string, error) {
+		n++
+		return fmt.Sprintf("token-%d", n), nil
+	}))
+	_, err := c.GetTeamTree(context.Background(), "")
+	require.NoError(t, err)
+	_, err = c.GetTeamTree(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, auth)
+
+	failing := client.New(srv.URL, client.WithTokenSource(func(context.Context) (string, error) {
+		return "", errors.New("refresh failed")
+	}))
+	_, err = failing.GetTeamTree(context.Background(), "")
+	assert.EqualError(t, err, "get token: refresh failed")
+}
+
+func TestClient_StreamEvents(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "5", r.Header.Get("Last-Event-ID"))
+		assert.Equal(t, "backend", r.URL.Query().Get("team_name"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": ping\n\n")
+		fmt.Fprint(w, "id: 6\nevent: PRCreated\ndata: {\"id\":6,\"type\":\"PRCreated\",\"pull_request_id\":\"pr-1\",\"team_name\":\"backend\",\"occurred_at\":\"2025-01-01T00:00:00Z\"}\n\n")
+		fmt.Fprint(w, "id: 7\nevent: PRMerged\ndata: {\"id\":7,\"type\":\"PRMerged\",\"pull_request_id\":\"pr-1\",\"team_name\":\"backend\",\"occurred_at\":\"2025-01-01T00:00:01Z\"}\n\n")
+	}))
+	defer srv.Close()
+	c := client.New(srv.URL, noRetry)
+
+	team, last := "backend", int64(5)
+	var got []client.Event
+	stop := errors.New("stop")
+	err := c.StreamEvents(context.Background(), client.StreamEventsParams{TeamName: &team, LastEventID: &last}, func(ev client.Event) error {
+		got = append(got, ev)
+		if len(got) == 2 {
+			return stop
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, stop)
+	require.Len(t, got, 2)
+	assert.Equal(t, int64(6), got[0].Id)
+	assert.Equal(t, models.EventTypePRMerged, got[1].Type)
+	assert.Equal(t, "pr-1", *got[1].PullRequestId)
+}
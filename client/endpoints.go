@@ -0,0 +1,343 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"pull-request-api.com/internal/models"
+)
+
+// Типы содержимого файлов оргструктуры для ImportTeams и SyncOrg.
+const (
+	ContentTypeCSV  = "text/csv"
+	ContentTypeYAML = "application/yaml"
+)
+
+// CreatePullRequest создаёт PR и назначает ревьюверов (POST /pullRequest/create).
+func (c *Client) CreatePullRequest(ctx context.Context, req CreatePullRequestRequest) (*PullRequest, error) {
+	var pr PullRequest
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/pullRequest/create", json: req}, &pr); err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// GetPullRequest возвращает PR (GET /pullRequest/get).
+func (c *Client) GetPullRequest(ctx context.Context, prID string) (*PullRequest, error) {
+	var pr PullRequest
+	q := url.Values{"pull_request_id": {prID}}
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/pullRequest/get", query: q}, &pr); err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// GetPullRequestAsOf возвращает состояние PR на момент at, восстановленное
+// по журналу событий (GET /pullRequest/getAsOf).
+func (c *Client) GetPullRequestAsOf(ctx context.Context, prID string, at time.Time) (*PullRequest, error) {
+	var pr PullRequest
+	q := url.Values{"pull_request_id": {prID}, "at": {at.Format(time.RFC3339Nano)}}
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/pullRequest/getAsOf", query: q}, &pr); err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// ListPullRequests возвращает страницу PR с фильтрами по команде и статусу
+// (GET /pullRequest/list). Следующая страница — с Cursor из PullRequestList.NextCursor.
+func (c *Client) ListPullRequests(ctx context.Context, params ListPullRequestsParams) (*PullRequestList, error) {
+	q := url.Values{}
+	setString(q, "team_name", params.TeamName)
+	setBool(q, "include_subteams", params.IncludeSubteams)
+	if params.Status != nil {
+		q.Set("status", string(*params.Status))
+	}
+	setString(q, "cursor", params.Cursor)
+	if params.Limit != nil {
+		q.Set("limit", strconv.Itoa(*params.Limit))
+	}
+
+	var list PullRequestList
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/pullRequest/list", query: q}, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// MergePullRequest помечает PR как MERGED (POST /pullRequest/merge).
+func (c *Client) MergePullRequest(ctx context.Context, prID string) (*PullRequest, error) {
+	var pr PullRequest
+	body := models.PostPullRequestMergeJSONRequestBody{PullRequestId: prID}
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/pullRequest/merge", json: body}, &pr); err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// ReassignReviewer заменяет ревьювера (POST /pullRequest/reassign).
+func (c *Client) ReassignReviewer(ctx context.Context, req ReassignRequest) (*PullRequest, error) {
+	var pr PullRequest
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/pullRequest/reassign", json: req}, &pr); err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// PreviewAssignment показывает, кого назначит создание PR, ничего не сохраняя
+// (POST /pullRequest/previewAssignment).
+func (c *Client) PreviewAssignment(ctx context.Context, req PreviewAssignmentRequest) (*AssignmentPreview, error) {
+	var res AssignmentPreview
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/pullRequest/previewAssignment", json: req}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// AddTeam создаёт команду или дополняет существующую (POST /team/add).
+func (c *Client) AddTeam(ctx context.Context, team Team) (*Team, error) {
+	var res Team
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/team/add", json: team}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetTeam возвращает команду с участниками (GET /team/get).
+func (c *Client) GetTeam(ctx context.Context, teamName string) (*Team, error) {
+	var team Team
+	q := url.Values{"team_name": {teamName}}
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/team/get", query: q}, &team); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// RenameTeam переименовывает команду (POST /team/rename).
+func (c *Client) RenameTeam(ctx context.Context, req RenameTeamRequest) (*Team, error) {
+	var team Team
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/team/rename", json: req}, &team); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// DeleteTeam удаляет команду (POST /team/delete).
+func (c *Client) DeleteTeam(ctx context.Context, req DeleteTeamRequest) (*TeamDeleteResult, error) {
+	var res TeamDeleteResult
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/team/delete", json: req}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// RemoveTeamMember исключает пользователя из дополнительной команды (POST /team/removeMember).
+func (c *Client) RemoveTeamMember(ctx context.Context, req RemoveTeamMemberRequest) (*User, error) {
+	var user User
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/team/removeMember", json: req}, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// SetTeamParent задаёт родительскую команду (POST /team/setParent).
+func (c *Client) SetTeamParent(ctx context.Context, req SetTeamParentRequest) (*Team, error) {
+	var team Team
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/team/setParent", json: req}, &team); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// SetEscalationPolicy задаёт политику эскалации (POST /team/setEscalationPolicy).
+func (c *Client) SetEscalationPolicy(ctx context.Context, req SetEscalationPolicyRequest) (*Team, error) {
+	var team Team
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/team/setEscalationPolicy", json: req}, &team); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// SetReviewCapacity задаёт лимит открытых ревью по умолчанию и политику при его
+// достижении (POST /team/setReviewCapacity).
+func (c *Client) SetReviewCapacity(ctx context.Context, req SetReviewCapacityRequest) (*Team, error) {
+	var team Team
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/team/setReviewCapacity", json: req}, &team); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// GetTeamTree возвращает иерархию команд (GET /team/tree). Пустой teamName —
+// все деревья.
+func (c *Client) GetTeamTree(ctx context.Context, teamName string) ([]TeamNode, error) {
+	q := url.Values{}
+	if teamName != "" {
+		q.Set("team_name", teamName)
+	}
+
+	var tree []TeamNode
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/team/tree", query: q}, &tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// ImportTeams импортирует команды из файла CSV или YAML (POST /team/import).
+// contentType — ContentTypeCSV или ContentTypeYAML.
+func (c *Client) ImportTeams(ctx context.Context, data []byte, contentType string, dryRun bool) (*OrgPlan, error) {
+	var plan OrgPlan
+	req := request{
+		method:      http.MethodPost,
+		path:        "/team/import",
+		query:       url.Values{"dry_run": {strconv.FormatBool(dryRun)}},
+		body:        data,
+		contentType: contentType,
+	}
+	if err := c.do(ctx, req, &plan); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// SyncOrg приводит оргструктуру к описанной в файле (POST /org/sync).
+func (c *Client) SyncOrg(ctx context.Context, data []byte, contentType string, absentUsers AbsentUsersMode, dryRun bool) (*OrgPlan, error) {
+	q := url.Values{"dry_run": {strconv.FormatBool(dryRun)}}
+	if absentUsers != "" {
+		q.Set("absent_users", string(absentUsers))
+	}
+
+	var plan OrgPlan
+	req := request{method: http.MethodPost, path: "/org/sync", query: q, body: data, contentType: contentType}
+	if err := c.do(ctx, req, &plan); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// GetUser возвращает пользователя (GET /users/get).
+func (c *Client) GetUser(ctx context.Context, userID string) (*User, error) {
+	var user User
+	q := url.Values{"user_id": {userID}}
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/users/get", query: q}, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ListUsers возвращает страницу списка пользователей (GET /users/list).
+// Следующая страница — с Cursor из UserList.NextCursor.
+func (c *Client) ListUsers(ctx context.Context, params ListUsersParams) (*UserList, error) {
+	q := url.Values{}
+	setString(q, "team_name", params.TeamName)
+	setBool(q, "is_active", params.IsActive)
+	setString(q, "name_prefix", params.NamePrefix)
+	setString(q, "cursor", params.Cursor)
+	if params.Limit != nil {
+		q.Set("limit", strconv.Itoa(*params.Limit))
+	}
+
+	var list UserList
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/users/list", query: q}, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// UpdateUser меняет имя пользователя (PATCH /users/update).
+func (c *Client) UpdateUser(ctx context.Context, req UpdateUserRequest) (*User, error) {
+	var user User
+	if err := c.do(ctx, request{method: http.MethodPatch, path: "/users/update", json: req}, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DeleteUser удаляет или обезличивает пользователя (POST /users/delete).
+func (c *Client) DeleteUser(ctx context.Context, req DeleteUserRequest) (*UserDeleteResult, error) {
+	var res UserDeleteResult
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/users/delete", json: req}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// SetUserActive включает или выключает пользователя (POST /users/setIsActive).
+func (c *Client) SetUserActive(ctx context.Context, userID string, active bool) (*User, error) {
+	var user User
+	body := models.PostUsersSetIsActiveJSONRequestBody{UserId: userID, IsActive: active}
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/users/setIsActive", json: body}, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// SetMaxOpenReviews задаёт лимит открытых ревью пользователя; nil — действует
+// лимит основной команды (POST /users/setMaxOpenReviews).
+func (c *Client) SetMaxOpenReviews(ctx context.Context, userID string, limit *int) (*User, error) {
+	var user User
+	body := models.PostUsersSetMaxOpenReviewsJSONRequestBody{UserId: userID, MaxOpenReviews: limit}
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/users/setMaxOpenReviews", json: body}, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// MoveUser переводит пользователя в другую команду (POST /users/moveTeam).
+func (c *Client) MoveUser(ctx context.Context, req MoveUserRequest) (*UserMoveResult, error) {
+	var res UserMoveResult
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/users/moveTeam", json: req}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// GetUserReviews возвращает PR, где пользователь назначен ревьювером
+// (GET /users/getReview).
+func (c *Client) GetUserReviews(ctx context.Context, userID string) ([]PullRequestShort, error) {
+	q := url.Values{"user_id": {userID}}
+
+	var prs []PullRequestShort
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/users/getReview", query: q}, &prs); err != nil {
+		return nil, err
+	}
+	return prs, nil
+}
+
+// GetAssignmentStats возвращает число назначений по ревьюверам
+// (GET /users/getAssignmentStats).
+func (c *Client) GetAssignmentStats(ctx context.Context, params AssignmentStatsParams) ([]AssignmentStats, error) {
+	q := url.Values{}
+	setString(q, "team_name", params.TeamName)
+	setBool(q, "include_subteams", params.IncludeSubteams)
+
+	var stats []AssignmentStats
+	if err := c.do(ctx, request{method: http.MethodGet, path: "/users/getAssignmentStats", query: q}, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// Batch выполняет пакет операций (POST /batch). Ошибки отдельных операций
+// возвращаются в BatchResult.Results, а не ошибкой метода.
+func (c *Client) Batch(ctx context.Context, req BatchRequest) (*BatchResult, error) {
+	var res BatchResult
+	if err := c.do(ctx, request{method: http.MethodPost, path: "/batch", json: req}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func setString(q url.Values, key string, v *string) {
+	if v != nil {
+		q.Set(key, *v)
+	}
+}
+
+func setBool(q url.Values, key string, v *bool) {
+	if v != nil {
+		q.Set(key, strconv.FormatBool(*v))
+	}
+}
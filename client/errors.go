@@ -0,0 +1,75 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"pull-request-api.com/internal/models"
+)
+
+// Ошибки API по кодам ErrorResponse. Проверяются через errors.Is:
+//
+//	if errors.Is(err, client.ErrPRMerged) { ... }
+//
+// Подробности (HTTP-статус, сообщение, ошибки полей) — в *Error через errors.As.
+var (
+	ErrNotFound              = errors.New("not found")
+	ErrMethodNotAllowed      = errors.New("method not allowed")
+	ErrPRExists              = errors.New("pull request already exists")
+	ErrPRMerged              = errors.New("pull request is merged")
+	ErrNotAssigned           = errors.New("reviewer is not assigned")
+	ErrNoCandidate           = errors.New("no replacement candidate")
+	ErrTeamExists            = errors.New("team already exists")
+	ErrTeamNotEmpty          = errors.New("team has members")
+	ErrUserHasHistory        = errors.New("user has pull requests or reviews")
+	ErrValidation            = errors.New("validation error")
+	ErrUnauthorized          = errors.New("unauthorized")
+	ErrRateLimited           = errors.New("rate limited")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key reused")
+	ErrIdempotencyInProgress = errors.New("idempotent request in progress")
+	ErrInternal              = errors.New("internal server error")
+)
+
+var codeErrors = map[ErrorCode]error{
+	models.NOTFOUND:              ErrNotFound,
+	models.METHODNOTALLOWED:      ErrMethodNotAllowed,
+	models.PREXISTS:              ErrPRExists,
+	models.PRMERGED:              ErrPRMerged,
+	models.NOTASSIGNED:           ErrNotAssigned,
+	models.NOCANDIDATE:           ErrNoCandidate,
+	models.TEAMEXISTS:            ErrTeamExists,
+	models.TEAMNOTEMPTY:          ErrTeamNotEmpty,
+	models.USERHASHISTORY:        ErrUserHasHistory,
+	models.VALIDATIONERROR:       ErrValidation,
+	models.UNAUTHORIZED:          ErrUnauthorized,
+	models.RATELIMITED:           ErrRateLimited,
+	models.IDEMPOTENCYKEYREUSED:  ErrIdempotencyKeyReused,
+	models.IDEMPOTENCYINPROGRESS: ErrIdempotencyInProgress,
+	models.INTERNALERROR:         ErrInternal,
+}
+
+// Error — ответ API с ошибкой (models.ErrorResponse).
+type Error struct {
+	StatusCode int
+	// Code пуст, если ответ пришёл не от API (прокси, балансировщик).
+	Code    ErrorCode
+	Message string
+	Details []FieldError
+}
+
+func (e *Error) Error() string {
+	code := string(e.Code)
+	if code == "" {
+		code = fmt.Sprintf("HTTP %d", e.StatusCode)
+	}
+	msg := fmt.Sprintf("%s: %s", code, e.Message)
+	for _, d := range e.Details {
+		msg += fmt.Sprintf("; %s: %s", d.Field, d.Message)
+	}
+	return msg
+}
+
+// Unwrap возвращает ошибку, соответствующую коду (ErrNotFound и т. д.).
+func (e *Error) Unwrap() error {
+	return codeErrors[e.Code]
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// StreamEventsParams — фильтры и позиция потока событий.
+type StreamEventsParams struct {
+	TeamName *string
+	UserID   *string
+	// LastEventID — номер последнего полученного события; поток продолжится
+	// после него. nil — только новые события.
+	LastEventID *int64
+}
+
+// StreamEvents подписывается на поток событий (GET /events/stream) и вызывает
+// fn для каждого события по порядку. Возвращает ошибку fn, ошибку соединения
+// или ctx.Err() после отмены. Повторов нет: чтобы продолжить после обрыва,
+// вызовите StreamEvents снова с LastEventID последнего обработанного события.
+func (c *Client) StreamEvents(ctx context.Context, params StreamEventsParams, fn func(Event) error) error {
+	q := url.Values{}
+	setString(q, "team_name", params.TeamName)
+	setString(q, "user_id", params.UserID)
+	u := c.baseURL + "/events/stream"
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if params.LastEventID != nil {
+		req.Header.Set("Last-Event-ID", strconv.FormatInt(*params.LastEventID, 10))
+	}
+	if c.token != nil {
+		token, err := c.token(ctx)
+		if err != nil {
+			return fmt.Errorf("get token: %w", err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	// Таймаут клиента ограничивает весь ответ, а поток бесконечен:
+	// соединение держится до отмены ctx.
+	hc := *c.http
+	hc.Timeout = 0
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return decodeError(resp)
+	}
+
+	var data strings.Builder
+	sc := bufio.NewScanner(resp.Body)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case line == "":
+			if data.Len() == 0 {
+				continue
+			}
+			var ev Event
+			if err := json.Unmarshal([]byte(data.String()), &ev); err != nil {
+				return fmt.Errorf("decode event: %w", err)
+			}
+			data.Reset()
+			if err := fn(ev); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+		// id, event и комментарии-пинги не нужны: номер и тип есть в данных.
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("event stream closed by server")
+}
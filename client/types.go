@@ -0,0 +1,109 @@
+package client
+
+import "pull-request-api.com/internal/models"
+
+// Типы запросов и ответов API. Объявлены псевдонимами, чтобы их можно было
+// использовать за пределами модуля.
+type (
+	Team                = models.Team
+	TeamMember          = models.TeamMember
+	TeamNode            = models.TeamNode
+	TeamDeleteResult    = models.TeamDeleteResult
+	User                = models.User
+	UserList            = models.UserList
+	PullRequestList     = models.PullRequestList
+	UserMoveResult      = models.UserMoveResult
+	UserDeleteResult    = models.UserDeleteResult
+	PullRequest         = models.PullRequest
+	PullRequestShort    = models.PullRequestShort
+	ReviewReassignment  = models.ReviewReassignment
+	AssignmentStats     = models.AssignmentStats
+	OrgPlan             = models.OrgPlan
+	OrgChange           = models.OrgChange
+	FieldError          = models.FieldError
+	Event               = models.Event
+	EventType           = models.EventType
+	BatchOperation      = models.BatchOperation
+	BatchResult         = models.BatchResult
+	BatchItemResult     = models.BatchItemResult
+	AssignmentPreview   = models.AssignmentPreview
+	AssignmentCandidate = models.AssignmentCandidate
+	ErrorCode           = models.ErrorResponseErrorCode
+
+	PullRequestStatus = models.PullRequestStatus
+	EscalationPolicy  = models.EscalationPolicy
+	CapacityPolicy    = models.CapacityPolicy
+	ReviewPolicy      = models.ReviewPolicy
+	TeamMemberPolicy  = models.TeamMemberPolicy
+	UserDeleteMode    = models.UserDeleteMode
+	AbsentUsersMode   = models.PostOrgSyncParamsAbsentUsers
+	BatchOp           = models.BatchOperationOp
+	BatchItemStatus   = models.BatchItemResultStatus
+	CandidateReason   = models.AssignmentCandidateReason
+
+	CreatePullRequestRequest   = models.PostPullRequestCreateJSONRequestBody
+	ReassignRequest            = models.PostPullRequestReassignJSONRequestBody
+	PreviewAssignmentRequest   = models.PostPullRequestPreviewAssignmentJSONRequestBody
+	RenameTeamRequest          = models.PostTeamRenameJSONRequestBody
+	DeleteTeamRequest          = models.PostTeamDeleteJSONRequestBody
+	RemoveTeamMemberRequest    = models.PostTeamRemoveMemberJSONRequestBody
+	SetTeamParentRequest       = models.PostTeamSetParentJSONRequestBody
+	SetEscalationPolicyRequest = models.PostTeamSetEscalationPolicyJSONRequestBody
+	SetReviewCapacityRequest   = models.PostTeamSetReviewCapacityJSONRequestBody
+	MoveUserRequest            = models.PostUsersMoveTeamJSONRequestBody
+	UpdateUserRequest          = models.PatchUsersUpdateJSONRequestBody
+	DeleteUserRequest          = models.PostUsersDeleteJSONRequestBody
+	BatchRequest               = models.PostBatchJSONRequestBody
+
+	AssignmentStatsParams  = models.GetAssignmentStatsParams
+	ListPullRequestsParams = models.GetPullRequestListParams
+	ListUsersParams        = models.GetUsersListParams
+)
+
+// Значения перечислений API.
+const (
+	PullRequestStatusOpen   = models.PullRequestStatusOPEN
+	PullRequestStatusMerged = models.PullRequestStatusMERGED
+
+	EscalationPolicyNone               = models.EscalationPolicyNone
+	EscalationPolicyParent             = models.EscalationPolicyParent
+	EscalationPolicySiblings           = models.EscalationPolicySiblings
+	EscalationPolicySiblingsThenParent = models.EscalationPolicySiblingsThenParent
+
+	CapacityPolicyLeastLoaded = models.CapacityPolicyLeastLoaded
+	CapacityPolicyQueue       = models.CapacityPolicyQueue
+	CapacityPolicyFail        = models.CapacityPolicyFail
+
+	TeamMembershipPrimary    = models.TeamMembershipPrimary
+	TeamMembershipAdditional = models.TeamMembershipAdditional
+
+	ReviewPolicyKeep     = models.ReviewPolicyKeep
+	ReviewPolicyReassign = models.ReviewPolicyReassign
+	ReviewPolicyUnassign = models.ReviewPolicyUnassign
+
+	TeamMemberPolicyMove   = models.TeamMemberPolicyMove
+	TeamMemberPolicyReject = models.TeamMemberPolicyReject
+
+	UserDeleteModeAnonymize = models.UserDeleteModeAnonymize
+	UserDeleteModeBlock     = models.UserDeleteModeBlock
+
+	AbsentUsersDeactivate = models.PostOrgSyncParamsAbsentUsersDeactivate
+	AbsentUsersDelete     = models.PostOrgSyncParamsAbsentUsersDelete
+
+	BatchOpCreate      = models.BatchOperationOpCreate
+	BatchOpMerge       = models.BatchOperationOpMerge
+	BatchOpReassign    = models.BatchOperationOpReassign
+	BatchOpSetIsActive = models.BatchOperationOpSetIsActive
+
+	BatchItemOk         = models.BatchItemResultStatusOk
+	BatchItemFailed     = models.BatchItemResultStatusFailed
+	BatchItemRolledBack = models.BatchItemResultStatusRolledBack
+	BatchItemSkipped    = models.BatchItemResultStatusSkipped
+
+	CandidateSelected        = models.AssignmentCandidateReasonSelected
+	CandidateEligible        = models.AssignmentCandidateReasonEligible
+	CandidateAuthor          = models.AssignmentCandidateReasonAuthor
+	CandidateInactive        = models.AssignmentCandidateReasonInactive
+	CandidateAlreadyAssigned = models.AssignmentCandidateReasonAlreadyAssigned
+	CandidateAtCapacity      = models.AssignmentCandidateReasonAtCapacity
+)
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"pull-request-api.com/client"
+	"pull-request-api.com/internal/orgfile"
+)
+
+type command struct {
+	api *client.Client
+	out *printer
+}
+
+func (c *command) run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "team":
+		return c.team(ctx, args[1:])
+	case "user":
+		return c.user(ctx, args[1:])
+	case "pr":
+		return c.pr(ctx, args[1:])
+	case "stats":
+		return c.stats(ctx, args[1:])
+	case "import":
+		return c.importTeams(ctx, args[1:])
+	}
+	return errUsage
+}
+
+func (c *command) team(ctx context.Context, args []string) error {
+	switch {
+	case len(args) >= 3 && args[0] == "add":
+		team := client.Team{TeamName: args[1]}
+		for _, m := range args[2:] {
+			id, name, ok := strings.Cut(m, "=")
+			if !ok || id == "" || name == "" {
+				return fmt.Errorf("%w: member must be USER_ID=USERNAME, got %q", errUsage, m)
+			}
+			team.Members = append(team.Members, client.TeamMember{UserId: id, Username: name, IsActive: true})
+		}
+		res, err := c.api.AddTeam(ctx, team)
+		if err != nil {
+			return err
+		}
+		return c.out.team(res)
+	case len(args) == 2 && args[0] == "get":
+		res, err := c.api.GetTeam(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		return c.out.team(res)
+	}
+	return errUsage
+}
+
+func (c *command) user(ctx context.Context, args []string) error {
+	if len(args) != 2 || (args[0] != "activate" && args[0] != "deactivate") {
+		return errUsage
+	}
+	res, err := c.api.SetUserActive(ctx, args[1], args[0] == "activate")
+	if err != nil {
+		return err
+	}
+	return c.out.user(res)
+}
+
+func (c *command) pr(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	var (
+		pr  *client.PullRequest
+		err error
+	)
+	switch sub, args := args[0], args[1:]; {
+	case sub == "create":
+		fs := flag.NewFlagSet("pr create", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		team := fs.String("team", "", "team of the pull request (default: author's primary team)")
+		if err := fs.Parse(args); err != nil || fs.NArg() != 3 {
+			return errUsage
+		}
+		req := client.CreatePullRequestRequest{
+			PullRequestId:   fs.Arg(0),
+			PullRequestName: fs.Arg(1),
+			AuthorId:        fs.Arg(2),
+		}
+		if *team != "" {
+			req.TeamName = team
+		}
+		pr, err = c.api.CreatePullRequest(ctx, req)
+	case sub == "get" && len(args) == 1:
+		pr, err = c.api.GetPullRequest(ctx, args[0])
+	case sub == "merge" && len(args) == 1:
+		pr, err = c.api.MergePullRequest(ctx, args[0])
+	case sub == "reassign" && len(args) == 2:
+		pr, err = c.api.ReassignReviewer(ctx, client.ReassignRequest{PullRequestId: args[0], OldUserId: args[1]})
+	default:
+		return errUsage
+	}
+	if err != nil {
+		return err
+	}
+	return c.out.pullRequest(pr)
+}
+
+func (c *command) stats(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	team := fs.String("team", "", "only this team")
+	subteams := fs.Bool("subteams", false, "include subteams of -team")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		return errUsage
+	}
+
+	var params client.AssignmentStatsParams
+	if *team != "" {
+		params.TeamName = team
+		params.IncludeSubteams = subteams
+	}
+	stats, err := c.api.GetAssignmentStats(ctx, params)
+	if err != nil {
+		return err
+	}
+	return c.out.stats(stats)
+}
+
+func (c *command) importTeams(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	dryRun := fs.Bool("dry-run", false, "only show changes")
+	formatFlag := fs.String("format", "", "csv or yaml (default: by file extension)")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return errUsage
+	}
+	path := fs.Arg(0)
+
+	format := orgfile.Format(*formatFlag)
+	if format == "" {
+		var ok bool
+		if format, ok = orgfile.FormatFromPath(path); !ok {
+			return fmt.Errorf("cannot detect format of %s, use -format", path)
+		}
+	}
+	contentType := client.ContentTypeCSV
+	if format == orgfile.YAML {
+		contentType = client.ContentTypeYAML
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	plan, err := c.api.ImportTeams(ctx, data, contentType, *dryRun)
+	if err != nil {
+		return err
+	}
+	return c.out.plan(plan)
+}
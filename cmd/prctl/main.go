@@ -0,0 +1,82 @@
+// Команда prctl — административная утилита для работы с сервисом через HTTP API.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"pull-request-api.com/client"
+)
+
+const usage = `usage: prctl [-server URL] [-token JWT] [-o table|json] COMMAND [ARGS]
+
+commands:
+  team add TEAM USER_ID=USERNAME...   create a team or add members to it
+  team get TEAM                       show a team with its members
+  user activate USER_ID               mark a user as active
+  user deactivate USER_ID             mark a user as inactive
+  pr create [-team TEAM] ID NAME AUTHOR_ID
+  pr get ID
+  pr merge ID
+  pr reassign ID OLD_USER_ID
+  stats [-team TEAM] [-subteams]      reviewer assignment counts
+  import [-dry-run] [-format csv|yaml] FILE
+
+Defaults for -server and -token are taken from PRCTL_SERVER and PRCTL_TOKEN.
+`
+
+// errUsage — неверные аргументы; prctl печатает справку и выходит с кодом 2.
+var errUsage = errors.New("invalid usage")
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("prctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	server := fs.String("server", getEnv("PRCTL_SERVER", "http://localhost:8080"), "service base URL")
+	token := fs.String("token", getEnv("PRCTL_TOKEN", ""), "bearer JWT")
+	format := fs.String("o", "table", "output format: table or json")
+	fs.Usage = func() { fmt.Fprint(stderr, usage) }
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(stderr, "unknown output format %q\n", *format)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	cmd := &command{
+		api: client.New(*server, client.WithToken(*token)),
+		out: &printer{w: stdout, json: *format == "json"},
+	}
+	err := cmd.run(ctx, fs.Args())
+	if errors.Is(err, errUsage) {
+		if err != errUsage {
+			fmt.Fprintln(stderr, "error:", err)
+		}
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "error:", err)
+		return 1
+	}
+	return 0
+}
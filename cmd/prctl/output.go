@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"pull-request-api.com/client"
+	"pull-request-api.com/internal/orgfile"
+)
+
+// printer выводит результаты таблицей или JSON (как вернул API).
+type printer struct {
+	w    io.Writer
+	json bool
+}
+
+func (p *printer) writeJSON(v any) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// table печатает строки с выравниванием колонок; первая строка — заголовок.
+func (p *printer) table(rows [][]string) error {
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func (p *printer) team(t *client.Team) error {
+	if p.json {
+		return p.writeJSON(t)
+	}
+	fmt.Fprintf(p.w, "team: %s\n", t.TeamName)
+	if t.ParentTeam != nil {
+		fmt.Fprintf(p.w, "parent: %s\n", *t.ParentTeam)
+	}
+	if t.EscalationPolicy != nil {
+		fmt.Fprintf(p.w, "escalation: %s\n", *t.EscalationPolicy)
+	}
+	rows := [][]string{{"USER_ID", "USERNAME", "ACTIVE"}}
+	for _, m := range t.Members {
+		rows = append(rows, []string{m.UserId, m.Username, strconv.FormatBool(m.IsActive)})
+	}
+	return p.table(rows)
+}
+
+func (p *printer) user(u *client.User) error {
+	if p.json {
+		return p.writeJSON(u)
+	}
+	return p.table([][]string{
+		{"USER_ID", "USERNAME", "TEAM", "ACTIVE", "TEAMS"},
+		{u.UserId, u.Username, dash(u.TeamName), strconv.FormatBool(u.IsActive), dash(strings.Join(u.Teams, ","))},
+	})
+}
+
+func (p *printer) pullRequest(pr *client.PullRequest) error {
+	if p.json {
+		return p.writeJSON(pr)
+	}
+	team := ""
+	if pr.TeamName != nil {
+		team = *pr.TeamName
+	}
+	return p.table([][]string{
+		{"ID", "NAME", "AUTHOR", "TEAM", "STATUS", "REVIEWERS"},
+		{pr.PullRequestId, pr.PullRequestName, pr.AuthorId, dash(team), string(pr.Status), dash(strings.Join(pr.AssignedReviewers, ","))},
+	})
+}
+
+func (p *printer) stats(stats []client.AssignmentStats) error {
+	if p.json {
+		return p.writeJSON(stats)
+	}
+	rows := [][]string{{"USER_ID", "ASSIGNMENTS"}}
+	for _, s := range stats {
+		rows = append(rows, []string{s.UserId, strconv.Itoa(s.Count)})
+	}
+	return p.table(rows)
+}
+
+func (p *printer) plan(plan *client.OrgPlan) error {
+	if p.json {
+		return p.writeJSON(plan)
+	}
+	return orgfile.WritePlan(p.w, plan)
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
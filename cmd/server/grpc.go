@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	"pull-request-api.com/internal/auth"
+	"pull-request-api.com/internal/grpcapi"
+	"pull-request-api.com/internal/notify"
+	"pull-request-api.com/internal/service"
+	reviewerv1 "pull-request-api.com/proto/reviewer/v1"
+)
+
+// listenGRPC открывает порт gRPC API (proto/reviewer/v1). Аутентификация та же,
+// что у REST: без verifier вызовы не проверяются.
+func listenGRPC(addr string, ser *service.Service, hub *notify.Hub, verifier *auth.Verifier) (*grpc.Server, net.Listener, error) {
+	var opts []grpc.ServerOption
+	if verifier != nil {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(grpcapi.UnaryAuth(verifier)),
+			grpc.ChainStreamInterceptor(grpcapi.StreamAuth(verifier)),
+		)
+	}
+	gs := grpc.NewServer(opts...)
+	reviewerv1.RegisterReviewerServiceServer(gs, grpcapi.NewServer(ser).WithEventHub(hub))
+	reflection.Register(gs)
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listen %s: %w", addr, err)
+	}
+	return gs, lis, nil
+}
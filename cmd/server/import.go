@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"pull-request-api.com/internal/models"
+	"pull-request-api.com/internal/orgfile"
+)
+
+// runImport — подкоманда `import`: массовый импорт команд из CSV/YAML напрямую
+// в базу (без HTTP). Формат определяется по расширению файла или -format.
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "only print changes, do not apply them")
+	formatFlag := fs.String("format", "", "file format: csv or yaml (default: by file extension)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s import [-dry-run] [-format csv|yaml] FILE\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	teams, code := readOrgFile(fs.Arg(0), *formatFlag, orgfile.Parse)
+	if teams == nil {
+		return code
+	}
+
+	dbConn := openCheckedDB("import")
+	if dbConn == nil {
+		return 1
+	}
+	defer dbConn.Close()
+
+	plan, err := newService(dbConn).ImportTeams(context.Background(), teams, *dryRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import failed: %v\n", err)
+		return 1
+	}
+	if err := orgfile.WritePlan(os.Stdout, plan); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// runSync — подкоманда `sync`: приводит оргструктуру в базе к описанному в
+// файле состоянию (см. POST /org/sync).
+func runSync(args []string) int {
+	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "only print changes, do not apply them")
+	formatFlag := fs.String("format", "", "file format: csv or yaml (default: by file extension)")
+	absentUsers := fs.String("absent-users", string(models.PostOrgSyncParamsAbsentUsersDeactivate),
+		"what to do with users missing from the file: deactivate or delete")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s sync [-dry-run] [-absent-users deactivate|delete] [-format csv|yaml] FILE\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	teams, code := readOrgFile(fs.Arg(0), *formatFlag, orgfile.ParseDesired)
+	if teams == nil {
+		return code
+	}
+
+	dbConn := openCheckedDB("sync")
+	if dbConn == nil {
+		return 1
+	}
+	defer dbConn.Close()
+
+	plan, err := newService(dbConn).SyncOrg(context.Background(), teams,
+		models.PostOrgSyncParamsAbsentUsers(*absentUsers), *dryRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sync failed: %v\n", err)
+		return 1
+	}
+	if err := orgfile.WritePlan(os.Stdout, plan); err != nil {
+		return 1
+	}
+	return 0
+}
+
+// readOrgFile читает файл (или stdin для "-") и разбирает его функцией parse.
+// При ошибке печатает её и возвращает nil и код выхода.
+func readOrgFile(path, formatFlag string, parse func([]byte, orgfile.Format) ([]models.Team, error)) ([]models.Team, int) {
+	format := orgfile.Format(formatFlag)
+	if format == "" {
+		var ok bool
+		if format, ok = orgfile.FormatFromPath(path); !ok {
+			fmt.Fprintln(os.Stderr, "cannot detect file format, use -format")
+			return nil, 2
+		}
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, 1
+	}
+
+	teams, err := parse(data, format)
+	var verr *orgfile.ValidationError
+	if errors.As(err, &verr) {
+		fmt.Fprintln(os.Stderr, "org file is invalid:")
+		for _, d := range verr.Details {
+			fmt.Fprintf(os.Stderr, "  %s: %s\n", d.Field, d.Message)
+		}
+		return nil, 1
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, 1
+	}
+	if teams == nil {
+		teams = []models.Team{}
+	}
+	return teams, 0
+}
@@ -1,16 +1,30 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/chi/v5"
+	openapi "pull-request-api.com"
 	"pull-request-api.com/internal/api"
+	"pull-request-api.com/internal/auth"
 	database "pull-request-api.com/internal/database"
+	"pull-request-api.com/internal/graphqlapi"
+	"pull-request-api.com/internal/idempotency"
+	"pull-request-api.com/internal/notify"
+	"pull-request-api.com/internal/ratelimit"
+	"pull-request-api.com/internal/scim"
 	"pull-request-api.com/internal/service"
 )
 
@@ -22,35 +36,378 @@ func getEnv(key, fallback string) string {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "import":
+			os.Exit(runImport(os.Args[2:]))
+		case "sync":
+			os.Exit(runSync(os.Args[2:]))
+		case "migrate":
+			os.Exit(runMigrate(os.Args[2:]))
+		case "rebuild":
+			os.Exit(runRebuild(os.Args[2:]))
+		case "serve":
+		default:
+			fmt.Fprintf(os.Stderr, "unknown command %q\nusage: %s [serve | import [-dry-run] FILE | sync [-dry-run] FILE | migrate COMMAND | rebuild]\n", os.Args[1], os.Args[0])
+			os.Exit(2)
+		}
+	}
+	serve()
+}
+
+// openDB подключается к базе по переменным окружения DB_*. DB_DRIVER выбирает
+// СУБД: postgres (по умолчанию) или sqlite — файл DB_PATH, без отдельного сервера.
+func openDB() (*sql.DB, string) {
+	switch driver := getEnv("DB_DRIVER", "postgres"); driver {
+	case "postgres":
+	case "sqlite":
+		dbPath := getEnv("DB_PATH", "prdb.sqlite")
+		dbConn, err := database.ConnectSQLite(dbPath)
+		if err != nil {
+			log.Fatalf("Infrastructure initialization failed: %v", err)
+		}
+		return dbConn, dbPath
+	default:
+		log.Fatalf("unknown DB_DRIVER %q", driver)
+	}
+
+	dbConn, err := database.Connect(postgresDSN())
+	if err != nil {
+		log.Fatalf("Infrastructure initialization failed: %v", err)
+	}
+	return dbConn, getEnv("DB_NAME", "prdb")
+}
+
+// postgresDSN — строка подключения к PostgreSQL по переменным окружения DB_*.
+func postgresDSN() string {
 	dbHost := getEnv("DB_HOST", "localhost")
 	dbPort := getEnv("DB_PORT", "5432")
 	dbUser := getEnv("DB_USER", "postgres")
 	dbPassword := getEnv("DB_PASSWORD", "postgres")
 	dbName := getEnv("DB_NAME", "prdb")
 
-	psqlInfo := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		dbHost, dbPort, dbUser, dbPassword, dbName)
+}
 
-	dbConn, err := database.Connect(psqlInfo)
-	if err != nil {
-		log.Fatalf("Infrastructure initialization failed: %v", err)
+// newEventHub запускает источник сигналов о новых событиях для
+// GET /events/stream: LISTEN на PostgreSQL, опрос журнала раз в секунду на SQLite.
+func newEventHub(ctx context.Context, db *sql.DB) (*notify.Hub, error) {
+	hub := notify.NewHub()
+	if database.DialectOf(db) == database.SQLite {
+		go hub.Poll(ctx, time.Second)
+		return hub, nil
+	}
+	if err := hub.ListenPostgres(ctx, postgresDSN()); err != nil {
+		return nil, err
+	}
+	return hub, nil
+}
+
+// newService создаёт сервис; SELECTION_SEED задаёт фиксированное зерно выбора
+// ревьюверов (для тестовых окружений), чтобы назначения повторялись.
+func newService(db *sql.DB) *service.Service {
+	var opts []service.Option
+	if v := getEnv("SELECTION_SEED", ""); v != "" {
+		seed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			log.Fatalf("SELECTION_SEED: %v", err)
+		}
+		slog.Warn("Reviewer selection is deterministic: SELECTION_SEED is set", "seed", seed)
+		opts = append(opts, service.WithSeed(seed))
 	}
+	return service.NewService(db, opts...)
+}
+
+func serve() {
+	dbConn, dbName := openDB()
 	defer dbConn.Close()
 
-	if err := database.Migrate(dbConn, dbName, "file://migrations"); err != nil {
-		log.Fatalf("Migration failed: %v", err)
+	autoMigrate, err := strconv.ParseBool(getEnv("DB_AUTO_MIGRATE", "true"))
+	if err != nil {
+		log.Fatalf("DB_AUTO_MIGRATE: %v", err)
+	}
+	if err := prepareSchema(dbConn, dbName, autoMigrate); err != nil {
+		log.Fatalf("Schema check failed: %v", err)
+	}
+
+	ser := newService(dbConn)
+	hub, err := newEventHub(context.Background(), dbConn)
+	if err != nil {
+		log.Fatalf("Event listener initialization failed: %v", err)
+	}
+	server := api.NewServer(ser).WithEventHub(hub)
+
+	pendingInterval, err := time.ParseDuration(getEnv("PENDING_FILL_INTERVAL", "1m"))
+	if err != nil {
+		log.Fatalf("PENDING_FILL_INTERVAL: %v", err)
 	}
+	go fillPendingReviewers(context.Background(), ser, hub, pendingInterval)
 
-	ser := service.NewService(dbConn)
-	server := api.NewServer(ser)
+	validator, err := api.NewRequestValidator(openapi.Spec)
+	if err != nil {
+		log.Fatalf("OpenAPI spec is invalid: %v", err)
+	}
 
 	r := chi.NewRouter()
+	r.NotFound(api.NotFound)
+	r.MethodNotAllowed(api.MethodNotAllowed)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 
-	api.HandlerFromMux(server, r)
+	// Документация доступна без аутентификации и лимитов.
+	if err := api.RegisterDocs(r, openapi.Spec); err != nil {
+		log.Fatalf("Docs initialization failed: %v", err)
+	}
+
+	verifier, err := newVerifier(context.Background())
+	if err != nil {
+		log.Fatalf("Auth initialization failed: %v", err)
+	}
+	if verifier == nil {
+		slog.Warn("Authentication is disabled: AUTH_JWKS_URL and AUTH_JWKS_FILE are not set")
+	}
+
+	limiter, groups, err := newRateLimiter(context.Background(), dbConn)
+	if err != nil {
+		log.Fatalf("Rate limiter initialization failed: %v", err)
+	}
+
+	idempotencyTTL, err := time.ParseDuration(getEnv("IDEMPOTENCY_TTL", "24h"))
+	if err != nil {
+		log.Fatalf("IDEMPOTENCY_TTL: %v", err)
+	}
+	idempotencyLease, err := time.ParseDuration(getEnv("IDEMPOTENCY_LEASE", "5m"))
+	if err != nil {
+		log.Fatalf("IDEMPOTENCY_LEASE: %v", err)
+	}
+	idempotencyStore := idempotency.NewStore(dbConn)
+	go runPeriodically(context.Background(), 10*time.Minute, "idempotency cleanup", idempotencyStore.Cleanup)
+
+	r.Group(func(r chi.Router) {
+		if verifier != nil {
+			r.Use(api.Authenticate(verifier))
+		}
+		r.Use(api.RateLimit(limiter, groups))
+		r.Use(api.ValidateRequests(validator))
+		r.Use(api.Idempotency(idempotencyStore, idempotencyTTL, idempotencyLease))
+
+		api.HandlerFromMux(server, r)
+	})
+
+	// SCIM-провижининг из IdP: тот же JWT и лимиты, но запросы описаны RFC 7644,
+	// а не openapi.yml.
+	r.Group(func(r chi.Router) {
+		if verifier != nil {
+			r.Use(api.Authenticate(verifier))
+		}
+		r.Use(api.RateLimit(limiter, groups))
+
+		r.Mount("/scim/v2", scim.NewHandler(ser, "/scim/v2"))
+	})
+
+	// GraphQL для дашбордов: запросы проверяются по schema.graphql.
+	graphqlHandler, err := graphqlapi.NewHandler(ser)
+	if err != nil {
+		log.Fatalf("GraphQL schema is invalid: %v", err)
+	}
+	r.Group(func(r chi.Router) {
+		if verifier != nil {
+			r.Use(api.Authenticate(verifier))
+		}
+		r.Use(api.RateLimit(limiter, groups))
+
+		r.Handle("/graphql", graphqlHandler)
+	})
+
+	// gRPC API на отдельном порту; пустой GRPC_ADDR отключает его.
+	if grpcAddr := getEnv("GRPC_ADDR", ":9090"); grpcAddr != "" {
+		gs, lis, err := listenGRPC(grpcAddr, ser, hub, verifier)
+		if err != nil {
+			log.Fatalf("gRPC initialization failed: %v", err)
+		}
+		slog.Info("gRPC server starting on " + grpcAddr)
+		go func() {
+			if err := gs.Serve(lis); err != nil {
+				log.Fatalf("gRPC server failed: %v", err)
+			}
+		}()
+	}
+
 	slog.Info("Server starting on :8080")
 	if err := http.ListenAndServe(":8080", r); err != nil {
 		log.Fatalf("Server failed: %v", err)
 	}
 }
+
+// prepareSchema проверяет схему перед запуском: dirty или более новая, чем
+// знает бинарник, схема — ошибка. Устаревшая схема обновляется, если
+// autoMigrate, иначе это тоже ошибка.
+func prepareSchema(db *sql.DB, dbName string, autoMigrate bool) error {
+	m, err := database.NewMigrator(db, dbName, migrationsURL(db))
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	err = m.Check()
+	if errors.Is(err, database.ErrSchemaOutdated) && autoMigrate {
+		if err := m.Up(); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+		err = m.Check()
+	}
+	return err
+}
+
+// openCheckedDB открывает базу для офлайн-подкоманд и проверяет схему так же,
+// как serve, но без автоматических миграций: писать в dirty или устаревшую
+// схему нельзя. При ошибке печатает её и возвращает nil.
+func openCheckedDB(cmd string) *sql.DB {
+	dbConn, dbName := openDB()
+	if err := prepareSchema(dbConn, dbName, false); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", cmd, err)
+		dbConn.Close()
+		return nil
+	}
+	return dbConn
+}
+
+// newVerifier включает проверку JWT, если задан источник JWKS. Без него возвращает nil.
+func newVerifier(ctx context.Context) (*auth.Verifier, error) {
+	source := getEnv("AUTH_JWKS_URL", getEnv("AUTH_JWKS_FILE", ""))
+	if source == "" {
+		return nil, nil
+	}
+
+	refresh, err := time.ParseDuration(getEnv("AUTH_JWKS_REFRESH", "15m"))
+	if err != nil {
+		return nil, fmt.Errorf("AUTH_JWKS_REFRESH: %w", err)
+	}
+
+	keys, err := auth.NewKeySet(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	go keys.Run(ctx, refresh)
+
+	return auth.NewVerifier(keys, auth.Config{
+		Issuer:     getEnv("AUTH_ISSUER", ""),
+		Audience:   getEnv("AUTH_AUDIENCE", ""),
+		UserClaim:  getEnv("AUTH_USER_CLAIM", "sub"),
+		RolesClaim: getEnv("AUTH_ROLES_CLAIM", "roles"),
+		Leeway:     30 * time.Second,
+	}), nil
+}
+
+// newRateLimiter собирает группы лимитов из RATE_LIMIT_* (формат "RATE/BURST").
+// Группа с пустым лимитом не ограничивается.
+func newRateLimiter(ctx context.Context, db *sql.DB) (ratelimit.Store, []api.RateLimitGroup, error) {
+	groups := []api.RateLimitGroup{
+		{Name: "pr-create", Methods: []string{http.MethodPost}, PathPrefixes: []string{"/pullRequest/create"}},
+		{Name: "write", Methods: []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}},
+		{Name: "read", Methods: []string{http.MethodGet}},
+	}
+	for i := range groups {
+		env := "RATE_LIMIT_" + strings.ToUpper(strings.ReplaceAll(groups[i].Name, "-", "_"))
+		limit, err := ratelimit.ParseLimit(getEnv(env, ""))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", env, err)
+		}
+		groups[i].Limit = limit
+	}
+
+	switch backend := getEnv("RATE_LIMIT_STORE", "memory"); backend {
+	case "memory":
+		return ratelimit.NewMemoryStore(), groups, nil
+	case "postgres":
+		if database.DialectOf(db) != database.Postgres {
+			return nil, nil, fmt.Errorf("RATE_LIMIT_STORE=postgres requires DB_DRIVER=postgres")
+		}
+		store := ratelimit.NewPostgresStore(db)
+		// Корзину можно удалить, только когда она успела бы заполниться при
+		// самом медленном лимите.
+		var refill time.Duration
+		for _, g := range groups {
+			refill = max(refill, g.Limit.RefillTime())
+		}
+		go runPeriodically(ctx, 10*time.Minute, "rate limit cleanup", func(ctx context.Context) error {
+			return store.Cleanup(ctx, refill)
+		})
+		return store, groups, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown RATE_LIMIT_STORE %q", backend)
+	}
+}
+
+// pendingDebounce — сколько fillPendingReviewers собирает сигналы о новых
+// событиях перед тем, как прочитать журнал: всплеск событий даёт одно чтение.
+const pendingDebounce = time.Second
+
+// fillPendingReviewers заполняет ожидающие слоты ревьюверов после событий,
+// освобождающих кандидатов (см. service.FreesCapacity), и раз в interval — для
+// изменений без событий: новых участников команды и повышенных лимитов.
+// Сигналы hub копятся pendingDebounce, затем журнал дочитывается курсором.
+func fillPendingReviewers(ctx context.Context, ser *service.Service, hub *notify.Hub, interval time.Duration) {
+	signals, unsubscribe := hub.Subscribe()
+	defer unsubscribe()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	cursor, err := ser.NewEventCursor(ctx, service.EventFilter{}, nil)
+	if err != nil {
+		slog.Error("Pending reviewers filler failed to start", "error", err)
+		return
+	}
+
+	var debounce <-chan time.Time
+	for {
+		fill := false
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			if debounce == nil {
+				debounce = time.After(pendingDebounce)
+			}
+			continue
+		case <-debounce:
+			debounce = nil
+			events, err := cursor.Next(ctx)
+			if err != nil {
+				slog.Error("Reading events for pending reviewers failed", "error", err)
+				continue
+			}
+			fill = slices.ContainsFunc(events, service.FreesCapacity)
+		case <-ticker.C:
+			fill = true
+		}
+		if !fill {
+			continue
+		}
+
+		n, err := ser.FillPendingReviewers(ctx)
+		if err != nil {
+			slog.Error("Filling pending reviewers failed", "error", err)
+		}
+		if n > 0 {
+			slog.Info("Pending reviewers assigned", "count", n)
+		}
+	}
+}
+
+func runPeriodically(ctx context.Context, interval time.Duration, name string, fn func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fn(ctx); err != nil {
+				slog.Error("Periodic task failed", "task", name, "error", err)
+			}
+		}
+	}
+}
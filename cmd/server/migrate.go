@@ -0,0 +1,118 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"pull-request-api.com/internal/database"
+)
+
+// migrationsURL — набор миграций для СУБД подключения.
+func migrationsURL(db *sql.DB) string {
+	if database.DialectOf(db) == database.SQLite {
+		return "file://migrations/sqlite"
+	}
+	return "file://migrations"
+}
+
+// runMigrate — подкоманда `migrate`: управление версией схемы без запуска сервера.
+func runMigrate(args []string) int {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s migrate up | down [N] | goto VERSION | force VERSION | status\n", os.Args[0])
+		fmt.Fprintln(fs.Output(), "  up             apply all pending migrations")
+		fmt.Fprintln(fs.Output(), "  down [N]       roll back the last N migrations (default 1)")
+		fmt.Fprintln(fs.Output(), "  goto VERSION   migrate up or down to VERSION")
+		fmt.Fprintln(fs.Output(), "  force VERSION  set VERSION and clear the dirty flag without running migrations")
+		fmt.Fprintln(fs.Output(), "  status         show the current and known versions")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cmd, cmdArgs := fs.Arg(0), fs.Args()
+	if len(cmdArgs) > 0 {
+		cmdArgs = cmdArgs[1:]
+	}
+	var (
+		n        int
+		parseErr error
+	)
+	switch {
+	case cmd == "up" && len(cmdArgs) == 0, cmd == "status" && len(cmdArgs) == 0:
+	case cmd == "down" && len(cmdArgs) == 0:
+		n = 1
+	case cmd == "down" && len(cmdArgs) == 1:
+		n, parseErr = strconv.Atoi(cmdArgs[0])
+		if parseErr == nil && n < 1 {
+			parseErr = fmt.Errorf("N must be positive")
+		}
+	case (cmd == "goto" || cmd == "force") && len(cmdArgs) == 1:
+		n, parseErr = strconv.Atoi(cmdArgs[0])
+		if parseErr == nil && (n < -1 || (cmd == "goto" && n < 0)) {
+			parseErr = fmt.Errorf("invalid version %d", n)
+		}
+	default:
+		fs.Usage()
+		return 2
+	}
+	if parseErr != nil {
+		fmt.Fprintf(os.Stderr, "migrate %s: %v\n", cmd, parseErr)
+		return 2
+	}
+
+	dbConn, dbName := openDB()
+	defer dbConn.Close()
+
+	m, err := database.NewMigrator(dbConn, dbName, migrationsURL(dbConn))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+		return 1
+	}
+	defer m.Close()
+
+	switch cmd {
+	case "up":
+		err = m.Up()
+	case "down":
+		err = m.Down(n)
+	case "goto":
+		err = m.Goto(uint(n))
+	case "force":
+		err = m.Force(n)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate %s failed: %v\n", cmd, err)
+		return 1
+	}
+
+	status, err := m.Status()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "migrate status: %v\n", err)
+		return 1
+	}
+	writeStatus(status)
+	return 0
+}
+
+func writeStatus(status *database.SchemaStatus) {
+	dirty := ""
+	if status.Dirty {
+		dirty = " (dirty)"
+	}
+	fmt.Printf("version: %d%s, latest: %d\n", status.Version, dirty, status.Latest)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, mg := range status.Migrations {
+		state := "pending"
+		if mg.Applied {
+			state = "applied"
+		}
+		fmt.Fprintf(tw, "%06d\t%s\t%s\n", mg.Version, mg.Name, state)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// runRebuild — подкоманда `rebuild`: пересобирает pull_requests и pr_reviewers
+// по журналу событий.
+func runRebuild(args []string) int {
+	fs := flag.NewFlagSet("rebuild", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s rebuild\n", os.Args[0])
+		fmt.Fprintln(fs.Output(), "regenerate pull_requests and pr_reviewers from the event log")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 2
+	}
+
+	dbConn := openCheckedDB("rebuild")
+	if dbConn == nil {
+		return 1
+	}
+	defer dbConn.Close()
+
+	stats, err := newService(dbConn).RebuildProjections(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rebuild failed: %v\n", err)
+		return 1
+	}
+	fmt.Printf("rebuilt %d pull requests from %d events\n", stats.PullRequests, stats.Events)
+	return 0
+}
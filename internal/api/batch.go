@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"pull-request-api.com/internal/models"
+	"pull-request-api.com/internal/service"
+)
+
+// Выполнить пакет операций над PR и пользователями
+// (POST /batch)
+func (s *Server) PostBatch(w http.ResponseWriter, r *http.Request) {
+	var body models.PostBatchJSONRequestBody
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	atomic := body.Atomic != nil && *body.Atomic
+	items, committed, err := s.ser.RunBatch(r.Context(), body.Operations, atomic)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	res := models.BatchResult{Committed: committed, Results: make([]models.BatchItemResult, len(items))}
+	for i, item := range items {
+		res.Results[i] = models.BatchItemResult{
+			Index:  i,
+			Status: item.Status,
+			Pr:     item.PullRequest,
+			User:   item.User,
+		}
+		if item.Err != nil {
+			code, msg := batchItemError(item.Op, item.Err)
+			res.Results[i].Error = &struct {
+				Code    models.ErrorResponseErrorCode `json:"code"`
+				Message string                        `json:"message"`
+			}{Code: code, Message: msg}
+		}
+	}
+	sendJSON(w, http.StatusOK, res)
+}
+
+// batchItemError переводит ошибку операции пакета в код так же, как это
+// делает эндпоинт операции.
+func batchItemError(op models.BatchOperationOp, err error) (models.ErrorResponseErrorCode, string) {
+	switch {
+	case op == models.BatchOperationOpReassign && errors.Is(err, service.ErrPrecondition):
+		return models.PRMERGED, "PR is merged"
+	case op == models.BatchOperationOpReassign && errors.Is(err, service.ErrInvalidInput):
+		return models.NOTASSIGNED, "User not assigned"
+	case op == models.BatchOperationOpReassign && errors.Is(err, service.ErrConflict):
+		return models.NOCANDIDATE, "No candidate available"
+	case errors.Is(err, service.ErrNotFound):
+		return models.NOTFOUND, "Not found"
+	case errors.Is(err, service.ErrConflict):
+		return models.PREXISTS, "Already exists"
+	case errors.Is(err, service.ErrInvalidInput):
+		return models.VALIDATIONERROR, err.Error()
+	case errors.Is(err, service.ErrNoCandidate):
+		return models.NOCANDIDATE, "No candidate available"
+	default:
+		slog.Error("Batch operation failed", "op", op, "error", err)
+		return models.INTERNALERROR, "Internal Server Error"
+	}
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-chi/chi/v5"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+// swaggerUI — статика swagger-ui-dist (версия в swagger-ui/NOTICE, лицензия —
+// swagger-ui/LICENSE). Она встроена в бинарник, чтобы документация работала без
+// доступа к CDN.
+//
+//go:embed swagger-ui/*.js swagger-ui/*.css swagger-ui/*.png
+var swaggerUI embed.FS
+
+// RegisterDocs публикует спецификацию (/openapi.yml, /openapi.json) и страницу
+// документации Swagger UI (/docs, статика — /docs/*).
+func RegisterDocs(r chi.Router, spec []byte) error {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return fmt.Errorf("load openapi spec: %w", err)
+	}
+	specJSON, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("convert openapi spec to json: %w", err)
+	}
+
+	assets, err := fs.Sub(swaggerUI, "swagger-ui")
+	if err != nil {
+		return err
+	}
+
+	r.Get("/openapi.yml", serveBytes("application/yaml", spec))
+	r.Get("/openapi.json", serveBytes("application/json", specJSON))
+	r.Get("/docs", serveBytes("text/html; charset=utf-8", docsPage))
+	// Без этого FileServer отдал бы на /docs/ список встроенных файлов.
+	r.Get("/docs/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/docs", http.StatusMovedPermanently)
+	})
+	r.Handle("/docs/*", http.StripPrefix("/docs/", http.FileServerFS(assets)))
+	return nil
+}
+
+func serveBytes(contentType string, data []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Write(data)
+	}
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"pull-request-api.com/internal/auth"
+	"pull-request-api.com/internal/idempotency"
+	"pull-request-api.com/internal/models"
+	"pull-request-api.com/internal/ratelimit"
+)
+
+// Authenticate проверяет bearer JWT и кладёт вызывающего в контекст запроса
+// (см. auth.PrincipalFromContext).
+func Authenticate(v *auth.Verifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := bearerToken(r)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Bearer`)
+				sendError(w, http.StatusUnauthorized, models.UNAUTHORIZED, "Missing bearer token")
+				return
+			}
+
+			principal, err := v.Verify(r.Context(), token)
+			if err != nil {
+				slog.Debug("Token rejected", "error", err)
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				sendError(w, http.StatusUnauthorized, models.UNAUTHORIZED, "Invalid token")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	h := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(h, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// RateLimitGroup задаёт лимит для группы маршрутов. Пустые Methods/PathPrefixes
+// означают «любой метод»/«любой путь».
+type RateLimitGroup struct {
+	Name         string
+	Methods      []string
+	PathPrefixes []string
+	Limit        ratelimit.Limit
+}
+
+func (g RateLimitGroup) matches(r *http.Request) bool {
+	if len(g.Methods) > 0 && !slices.Contains(g.Methods, r.Method) {
+		return false
+	}
+	if len(g.PathPrefixes) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(g.PathPrefixes, func(p string) bool {
+		return strings.HasPrefix(r.URL.Path, p)
+	})
+}
+
+// RateLimit ограничивает частоту запросов клиента. Запрос относится к первой
+// подходящей группе; клиент определяется по аутентифицированному пользователю
+// или IP. Должен стоять после Authenticate.
+func RateLimit(store ratelimit.Store, groups []RateLimitGroup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			idx := slices.IndexFunc(groups, func(g RateLimitGroup) bool { return g.matches(r) })
+			if idx < 0 || !groups[idx].Limit.Enabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			group := groups[idx]
+
+			res, err := store.Take(r.Context(), group.Name+":"+clientKey(r), group.Limit)
+			if err != nil {
+				// Лимитер не должен ронять сервис: при ошибке хранилища пропускаем запрос.
+				slog.Error("Rate limiter failed", "group", group.Name, "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !res.Allowed {
+				retry := int(math.Ceil(res.RetryAfter.Seconds()))
+				w.Header().Set("Retry-After", strconv.Itoa(max(retry, 1)))
+				sendError(w, http.StatusTooManyRequests, models.RATELIMITED, "Too many requests")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientKey различает клиентов по проверенному пользователю, а без него — по IP.
+// Непроверенный bearer-токен для этого не годится: со случайным токеном в каждом
+// запросе клиент получал бы новую корзину и обходил лимит.
+func clientKey(r *http.Request) string {
+	if p := auth.PrincipalFromContext(r.Context()); p != nil {
+		return "user:" + p.UserID
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+const maxIdempotentBody = 1 << 20
+
+// Idempotency сохраняет ответы на POST-запросы с заголовком Idempotency-Key
+// и воспроизводит их при повторе. Повтор ключа с другим телом отклоняется.
+// Ответы 5xx не сохраняются — повтор выполнит запрос заново. Выполняющийся
+// запрос держит ключ не дольше lease: если процесс умер, не успев освободить
+// ключ, повтор после истечения срока выполнит запрос заново.
+func Idempotency(store *idempotency.Store, ttl, lease time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("Idempotency-Key")
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Лишний байт отличает тело ровно в лимит от обрезанного: обрезанное
+			// тело нельзя ни передавать обработчику, ни хэшировать под ключом.
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentBody+1))
+			if err != nil {
+				sendValidationError(w, "Invalid body", nil)
+				return
+			}
+			if len(body) > maxIdempotentBody {
+				sendError(w, http.StatusRequestEntityTooLarge, models.PAYLOADTOOLARGE, "Request body is too large for an idempotent request")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			sum := sha256.Sum256(body)
+			hash := hex.EncodeToString(sum[:])
+			scope := clientKey(r) + " " + r.Method + " " + r.URL.Path
+
+			saved, err := store.Begin(r.Context(), scope, key, hash, ttl, lease)
+			switch {
+			case errors.Is(err, idempotency.ErrKeyReused):
+				sendError(w, http.StatusUnprocessableEntity, models.IDEMPOTENCYKEYREUSED, "Idempotency-Key was used with a different request body")
+				return
+			case errors.Is(err, idempotency.ErrInProgress):
+				w.Header().Set("Retry-After", "1")
+				sendError(w, http.StatusConflict, models.IDEMPOTENCYINPROGRESS, "Request with this Idempotency-Key is in progress")
+				return
+			case err != nil:
+				slog.Error("Idempotency store failed", "error", err)
+				sendError(w, http.StatusInternalServerError, models.INTERNALERROR, "Internal Server Error")
+				return
+			}
+
+			if saved != nil {
+				w.Header().Set("Idempotent-Replayed", "true")
+				if saved.ContentType != "" {
+					w.Header().Set("Content-Type", saved.ContentType)
+				}
+				w.WriteHeader(saved.StatusCode)
+				w.Write(saved.Body)
+				return
+			}
+
+			// Результат сохраняем даже если клиент уже отключился: он придёт с повтором.
+			ctx := context.WithoutCancel(r.Context())
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			defer func() {
+				// Recoverer стоит снаружи: при панике ключ освобождаем здесь,
+				// иначе он висел бы в состоянии «выполняется» до конца lease.
+				if p := recover(); p != nil {
+					if err := store.Release(ctx, scope, key); err != nil {
+						slog.Error("Failed to release idempotency key", "error", err)
+					}
+					panic(p)
+				}
+			}()
+			next.ServeHTTP(rec, r)
+
+			if rec.status >= http.StatusInternalServerError {
+				err = store.Release(ctx, scope, key)
+			} else {
+				err = store.Complete(ctx, scope, key, idempotency.Response{
+					StatusCode:  rec.status,
+					ContentType: rec.Header().Get("Content-Type"),
+					Body:        rec.body.Bytes(),
+				})
+			}
+			if err != nil {
+				slog.Error("Failed to save idempotent response", "error", err)
+			}
+		})
+	}
+}
+
+// responseRecorder пишет ответ клиенту и одновременно запоминает его.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
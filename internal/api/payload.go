@@ -2,24 +2,30 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 
 	"pull-request-api.com/internal/models"
+	"pull-request-api.com/internal/orgfile"
 )
 
 func sendError(w http.ResponseWriter, status int, code models.ErrorResponseErrorCode, msg string) {
-	errResp := models.ErrorResponse{
-		Error: struct {
-			Code    models.ErrorResponseErrorCode `json:"code"`
-			Message string                        `json:"message"`
-		}{
-			Code:    code,
-			Message: msg,
-		},
+	var errResp models.ErrorResponse
+	errResp.Error.Code = code
+	errResp.Error.Message = msg
+	sendJSON(w, status, errResp)
+}
+
+func sendValidationError(w http.ResponseWriter, msg string, details []models.FieldError) {
+	var errResp models.ErrorResponse
+	errResp.Error.Code = models.VALIDATIONERROR
+	errResp.Error.Message = msg
+	if len(details) > 0 {
+		errResp.Error.Details = &details
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(errResp)
+	sendJSON(w, http.StatusBadRequest, errResp)
 }
 
 func sendJSON(w http.ResponseWriter, status int, data any) {
@@ -27,3 +33,57 @@ func sendJSON(w http.ResponseWriter, status int, data any) {
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(data)
 }
+
+// decodeBody разбирает JSON-тело запроса, отклоняя неизвестные поля.
+// При ошибке отправляет VALIDATION_ERROR и возвращает false.
+func decodeBody(w http.ResponseWriter, r *http.Request, dst any) bool {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(dst)
+	if err == nil && dec.More() {
+		err = errors.New("unexpected data after JSON body")
+	}
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = errors.New("request body is empty")
+		}
+		sendValidationError(w, fmt.Sprintf("Invalid body: %v", err), nil)
+		return false
+	}
+	return true
+}
+
+const maxOrgFileSize = 5 << 20
+
+// decodeOrgFile разбирает описание оргструктуры в формате CSV или YAML (по
+// Content-Type) функцией parse. При ошибке отправляет VALIDATION_ERROR со
+// всеми ошибками файла.
+func decodeOrgFile(w http.ResponseWriter, r *http.Request, parse func([]byte, orgfile.Format) ([]models.Team, error)) ([]models.Team, bool) {
+	format, ok := orgfile.FormatFromContentType(r.Header.Get("Content-Type"))
+	if !ok {
+		sendValidationError(w, "Content-Type must be text/csv or application/yaml", nil)
+		return nil, false
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxOrgFileSize+1))
+	if err != nil {
+		sendValidationError(w, fmt.Sprintf("Invalid body: %v", err), nil)
+		return nil, false
+	}
+	if len(data) > maxOrgFileSize {
+		sendValidationError(w, "File is too large", nil)
+		return nil, false
+	}
+
+	teams, err := parse(data, format)
+	var verr *orgfile.ValidationError
+	if errors.As(err, &verr) {
+		sendValidationError(w, "Org file is invalid", verr.Details)
+		return nil, false
+	} else if err != nil {
+		sendValidationError(w, err.Error(), nil)
+		return nil, false
+	}
+	return teams, true
+}
@@ -4,14 +4,16 @@
 package api
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/oapi-codegen/runtime"
 	"pull-request-api.com/internal/models"
+	"pull-request-api.com/internal/notify"
+	"pull-request-api.com/internal/orgfile"
 	"pull-request-api.com/internal/service"
 )
 
@@ -20,32 +22,96 @@ type ServerInterface interface {
 	// Создать PR и автоматически назначить до 2 ревьюверов из команды автора
 	// (POST /pullRequest/create)
 	PostPullRequestCreate(w http.ResponseWriter, r *http.Request)
+	// Получить PR по идентификатору
+	// (GET /pullRequest/get)
+	GetPullRequestGet(w http.ResponseWriter, r *http.Request, params models.GetPullRequestGetParams)
+	// Состояние PR на момент времени
+	// (GET /pullRequest/getAsOf)
+	GetPullRequestGetAsOf(w http.ResponseWriter, r *http.Request, params models.GetPullRequestGetAsOfParams)
 	// Пометить PR как MERGED (идемпотентная операция)
 	// (POST /pullRequest/merge)
 	PostPullRequestMerge(w http.ResponseWriter, r *http.Request)
 	// Переназначить конкретного ревьювера на другого из его команды
 	// (POST /pullRequest/reassign)
 	PostPullRequestReassign(w http.ResponseWriter, r *http.Request)
+	// Показать, кого назначит создание PR, ничего не сохраняя
+	// (POST /pullRequest/previewAssignment)
+	PostPullRequestPreviewAssignment(w http.ResponseWriter, r *http.Request)
 	// Создать команду с участниками (создаёт/обновляет пользователей)
 	// (POST /team/add)
 	PostTeamAdd(w http.ResponseWriter, r *http.Request)
 	// Получить команду с участниками
 	// (GET /team/get)
 	GetTeamGet(w http.ResponseWriter, r *http.Request, params models.GetTeamGetParams)
+	// Переименовать команду
+	// (POST /team/rename)
+	PostTeamRename(w http.ResponseWriter, r *http.Request)
+	// Удалить команду
+	// (POST /team/delete)
+	PostTeamDelete(w http.ResponseWriter, r *http.Request)
+	// Исключить пользователя из дополнительной команды
+	// (POST /team/removeMember)
+	PostTeamRemoveMember(w http.ResponseWriter, r *http.Request)
+	// Перевести пользователя в другую команду
+	// (POST /users/moveTeam)
+	PostUsersMoveTeam(w http.ResponseWriter, r *http.Request)
 	// Получить PR'ы, где пользователь назначен ревьювером
 	// (GET /users/getReview)
 	GetUsersGetReview(w http.ResponseWriter, r *http.Request, params models.GetUsersGetReviewParams)
 	// Установить флаг активности пользователя
 	// (POST /users/setIsActive)
 	PostUsersSetIsActive(w http.ResponseWriter, r *http.Request)
-	// эндпоинт статистики (например, количество назначений по пользователям)
-	// (GET /users/getAssignmentStats
-	GetAssignmentStats(w http.ResponseWriter, r *http.Request)
+	// Задать лимит открытых ревью пользователя
+	// (POST /users/setMaxOpenReviews)
+	PostUsersSetMaxOpenReviews(w http.ResponseWriter, r *http.Request)
+	// Статистика назначений ревьюверов по пользователям
+	// (GET /users/getAssignmentStats)
+	GetAssignmentStats(w http.ResponseWriter, r *http.Request, params models.GetAssignmentStatsParams)
+	// Задать родительскую команду
+	// (POST /team/setParent)
+	PostTeamSetParent(w http.ResponseWriter, r *http.Request)
+	// Задать политику эскалации выбора ревьюверов
+	// (POST /team/setEscalationPolicy)
+	PostTeamSetEscalationPolicy(w http.ResponseWriter, r *http.Request)
+	// Задать лимит открытых ревью по умолчанию и политику при его достижении
+	// (POST /team/setReviewCapacity)
+	PostTeamSetReviewCapacity(w http.ResponseWriter, r *http.Request)
+	// Получить иерархию команд
+	// (GET /team/tree)
+	GetTeamTree(w http.ResponseWriter, r *http.Request, params models.GetTeamTreeParams)
+	// Список PR с фильтрами по команде и статусу
+	// (GET /pullRequest/list)
+	GetPullRequestList(w http.ResponseWriter, r *http.Request, params models.GetPullRequestListParams)
+	// Получить пользователя
+	// (GET /users/get)
+	GetUsersGet(w http.ResponseWriter, r *http.Request, params models.GetUsersGetParams)
+	// Список пользователей с фильтрами и постраничной выдачей
+	// (GET /users/list)
+	GetUsersList(w http.ResponseWriter, r *http.Request, params models.GetUsersListParams)
+	// Изменить имя пользователя
+	// (PATCH /users/update)
+	PatchUsersUpdate(w http.ResponseWriter, r *http.Request)
+	// Удалить или обезличить пользователя
+	// (POST /users/delete)
+	PostUsersDelete(w http.ResponseWriter, r *http.Request)
+	// Массовый импорт команд и пользователей из CSV или YAML
+	// (POST /team/import)
+	PostTeamImport(w http.ResponseWriter, r *http.Request, params models.PostTeamImportParams)
+	// Привести оргструктуру к описанному в файле состоянию
+	// (POST /org/sync)
+	PostOrgSync(w http.ResponseWriter, r *http.Request, params models.PostOrgSyncParams)
+	// Поток изменений PR и активности пользователей (Server-Sent Events)
+	// (GET /events/stream)
+	GetEventsStream(w http.ResponseWriter, r *http.Request, params models.GetEventsStreamParams)
+	// Выполнить пакет операций над PR и пользователями
+	// (POST /batch)
+	PostBatch(w http.ResponseWriter, r *http.Request)
 }
 
 // Unimplemented server implementation that returns http.StatusNotImplemented for each endpoint.
 type Server struct {
-	ser *service.Service
+	ser    *service.Service
+	events *notify.Hub
 }
 
 func NewServer(ser *service.Service) *Server {
@@ -56,8 +122,7 @@ func NewServer(ser *service.Service) *Server {
 // (POST /pullRequest/create)
 func (s *Server) PostPullRequestCreate(w http.ResponseWriter, r *http.Request) {
 	var body models.PostPullRequestCreateJSONRequestBody
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		sendError(w, http.StatusBadRequest, models.NOTFOUND, "Invalid body")
+	if !decodeBody(w, r, &body) {
 		return
 	}
 
@@ -70,12 +135,33 @@ func (s *Server) PostPullRequestCreate(w http.ResponseWriter, r *http.Request) {
 	sendJSON(w, http.StatusOK, pr)
 }
 
+// Получить PR по идентификатору
+// (GET /pullRequest/get)
+func (s *Server) GetPullRequestGet(w http.ResponseWriter, r *http.Request, params models.GetPullRequestGetParams) {
+	pr, err := s.ser.GetPullRequest(r.Context(), params.PullRequestId)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, pr)
+}
+
+// Состояние PR на момент времени
+// (GET /pullRequest/getAsOf)
+func (s *Server) GetPullRequestGetAsOf(w http.ResponseWriter, r *http.Request, params models.GetPullRequestGetAsOfParams) {
+	pr, err := s.ser.GetPullRequestAsOf(r.Context(), params.PullRequestId, params.At)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, pr)
+}
+
 // Пометить PR как MERGED (идемпотентная операция)
 // (POST /pullRequest/merge)
 func (s *Server) PostPullRequestMerge(w http.ResponseWriter, r *http.Request) {
 	var body models.PostPullRequestMergeJSONRequestBody
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		sendError(w, http.StatusBadRequest, models.NOTFOUND, "Invalid body")
+	if !decodeBody(w, r, &body) {
 		return
 	}
 
@@ -92,8 +178,7 @@ func (s *Server) PostPullRequestMerge(w http.ResponseWriter, r *http.Request) {
 // (POST /pullRequest/reassign)
 func (s *Server) PostPullRequestReassign(w http.ResponseWriter, r *http.Request) {
 	var body models.PostPullRequestReassignJSONRequestBody
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		sendError(w, http.StatusBadRequest, models.NOTFOUND, "Invalid body")
+	if !decodeBody(w, r, &body) {
 		return
 	}
 
@@ -117,100 +202,860 @@ func (s *Server) PostPullRequestReassign(w http.ResponseWriter, r *http.Request)
 	sendJSON(w, http.StatusOK, pr)
 }
 
-// Создать команду с участниками (создаёт/обновляет пользователей)
-// (POST /team/add)
-func (s *Server) PostTeamAdd(w http.ResponseWriter, r *http.Request) {
-	var body models.Team
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		sendError(w, http.StatusBadRequest, models.NOTFOUND, "Invalid body")
+// Показать, кого назначит создание PR, ничего не сохраняя
+// (POST /pullRequest/previewAssignment)
+func (s *Server) PostPullRequestPreviewAssignment(w http.ResponseWriter, r *http.Request) {
+	var body models.PostPullRequestPreviewAssignmentJSONRequestBody
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	preview, err := s.ser.PreviewAssignment(r.Context(), body)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, preview)
+}
+
+// Создать команду с участниками (создаёт/обновляет пользователей)
+// (POST /team/add)
+func (s *Server) PostTeamAdd(w http.ResponseWriter, r *http.Request) {
+	var body models.Team
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	err := s.ser.AddTeam(r.Context(), body)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	sendJSON(w, http.StatusOK, body)
+}
+
+// Получить команду с участниками
+// (GET /team/get)
+func (s *Server) GetTeamGet(w http.ResponseWriter, r *http.Request, params models.GetTeamGetParams) {
+	team, err := s.ser.GetTeam(r.Context(), params.TeamName)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, team)
+}
+
+// Переименовать команду
+// (POST /team/rename)
+func (s *Server) PostTeamRename(w http.ResponseWriter, r *http.Request) {
+	var body models.PostTeamRenameJSONRequestBody
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	team, err := s.ser.RenameTeam(r.Context(), body)
+	if err != nil {
+		if errors.Is(err, service.ErrConflict) {
+			sendError(w, http.StatusConflict, models.TEAMEXISTS, "Team with new_team_name already exists")
+			return
+		}
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, team)
+}
+
+// Удалить команду
+// (POST /team/delete)
+func (s *Server) PostTeamDelete(w http.ResponseWriter, r *http.Request) {
+	var body models.PostTeamDeleteJSONRequestBody
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	res, err := s.ser.DeleteTeam(r.Context(), body)
+	if err != nil {
+		if errors.Is(err, service.ErrPrecondition) {
+			sendError(w, http.StatusConflict, models.TEAMNOTEMPTY, "Team has members; use member_policy=move")
+			return
+		}
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, res)
+}
+
+// Исключить пользователя из дополнительной команды
+// (POST /team/removeMember)
+func (s *Server) PostTeamRemoveMember(w http.ResponseWriter, r *http.Request) {
+	var body models.PostTeamRemoveMemberJSONRequestBody
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	user, err := s.ser.RemoveTeamMember(r.Context(), body)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, user)
+}
+
+// Перевести пользователя в другую команду
+// (POST /users/moveTeam)
+func (s *Server) PostUsersMoveTeam(w http.ResponseWriter, r *http.Request) {
+	var body models.PostUsersMoveTeamJSONRequestBody
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	res, err := s.ser.MoveUser(r.Context(), body)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, res)
+}
+
+// Статистика назначений ревьюверов по пользователям
+// (GET /users/getAssignmentStats)
+func (s *Server) GetAssignmentStats(w http.ResponseWriter, r *http.Request, params models.GetAssignmentStatsParams) {
+	includeSubteams := params.IncludeSubteams != nil && *params.IncludeSubteams
+	stats, err := s.ser.GetAssignmentStats(r.Context(), params.TeamName, includeSubteams)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, stats)
+}
+
+// Получить PR'ы, где пользователь назначен ревьювером
+// (GET /users/getReview)
+func (s *Server) GetUsersGetReview(w http.ResponseWriter, r *http.Request, params models.GetUsersGetReviewParams) {
+	prs, err := s.ser.GetUsersReviews(r.Context(), params.UserId)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, prs)
+}
+
+// Установить флаг активности пользователя
+// (POST /users/setIsActive)
+func (s *Server) PostUsersSetIsActive(w http.ResponseWriter, r *http.Request) {
+	var body models.PostUsersSetIsActiveJSONRequestBody
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	user, err := s.ser.SetUserActive(r.Context(), body)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	sendJSON(w, http.StatusOK, user)
+}
+
+// Задать лимит открытых ревью пользователя
+// (POST /users/setMaxOpenReviews)
+func (s *Server) PostUsersSetMaxOpenReviews(w http.ResponseWriter, r *http.Request) {
+	var body models.PostUsersSetMaxOpenReviewsJSONRequestBody
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	user, err := s.ser.SetMaxOpenReviews(r.Context(), body)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, user)
+}
+
+// Задать родительскую команду
+// (POST /team/setParent)
+func (s *Server) PostTeamSetParent(w http.ResponseWriter, r *http.Request) {
+	var body models.PostTeamSetParentJSONRequestBody
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	team, err := s.ser.SetTeamParent(r.Context(), body)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, team)
+}
+
+// Задать политику эскалации выбора ревьюверов
+// (POST /team/setEscalationPolicy)
+func (s *Server) PostTeamSetEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	var body models.PostTeamSetEscalationPolicyJSONRequestBody
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	team, err := s.ser.SetEscalationPolicy(r.Context(), body)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, team)
+}
+
+// Задать лимит открытых ревью по умолчанию и политику при его достижении
+// (POST /team/setReviewCapacity)
+func (s *Server) PostTeamSetReviewCapacity(w http.ResponseWriter, r *http.Request) {
+	var body models.PostTeamSetReviewCapacityJSONRequestBody
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	team, err := s.ser.SetReviewCapacity(r.Context(), body)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, team)
+}
+
+// Получить иерархию команд
+// (GET /team/tree)
+func (s *Server) GetTeamTree(w http.ResponseWriter, r *http.Request, params models.GetTeamTreeParams) {
+	tree, err := s.ser.GetTeamTree(r.Context(), params.TeamName)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, tree)
+}
+
+// Список PR с фильтрами по команде и статусу
+// (GET /pullRequest/list)
+func (s *Server) GetPullRequestList(w http.ResponseWriter, r *http.Request, params models.GetPullRequestListParams) {
+	list, err := s.ser.ListPullRequests(r.Context(), params)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, list)
+}
+
+// Получить пользователя
+// (GET /users/get)
+func (s *Server) GetUsersGet(w http.ResponseWriter, r *http.Request, params models.GetUsersGetParams) {
+	user, err := s.ser.GetUser(r.Context(), params.UserId)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, user)
+}
+
+// Список пользователей с фильтрами и постраничной выдачей
+// (GET /users/list)
+func (s *Server) GetUsersList(w http.ResponseWriter, r *http.Request, params models.GetUsersListParams) {
+	list, err := s.ser.ListUsers(r.Context(), params)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, list)
+}
+
+// Изменить имя пользователя
+// (PATCH /users/update)
+func (s *Server) PatchUsersUpdate(w http.ResponseWriter, r *http.Request) {
+	var body models.PatchUsersUpdateJSONRequestBody
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	user, err := s.ser.UpdateUsername(r.Context(), body)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, user)
+}
+
+// Удалить или обезличить пользователя
+// (POST /users/delete)
+func (s *Server) PostUsersDelete(w http.ResponseWriter, r *http.Request) {
+	var body models.PostUsersDeleteJSONRequestBody
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	res, err := s.ser.DeleteUser(r.Context(), body)
+	if err != nil {
+		if errors.Is(err, service.ErrPrecondition) {
+			sendError(w, http.StatusConflict, models.USERHASHISTORY, "User has pull requests or reviews; use mode=anonymize")
+			return
+		}
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, res)
+}
+
+// Массовый импорт команд и пользователей из CSV или YAML
+// (POST /team/import)
+func (s *Server) PostTeamImport(w http.ResponseWriter, r *http.Request, params models.PostTeamImportParams) {
+	teams, ok := decodeOrgFile(w, r, orgfile.Parse)
+	if !ok {
+		return
+	}
+
+	dryRun := params.DryRun != nil && *params.DryRun
+	plan, err := s.ser.ImportTeams(r.Context(), teams, dryRun)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, plan)
+}
+
+// Привести оргструктуру к описанному в файле состоянию
+// (POST /org/sync)
+func (s *Server) PostOrgSync(w http.ResponseWriter, r *http.Request, params models.PostOrgSyncParams) {
+	teams, ok := decodeOrgFile(w, r, orgfile.ParseDesired)
+	if !ok {
+		return
+	}
+
+	absentUsers := models.PostOrgSyncParamsAbsentUsersDeactivate
+	if params.AbsentUsers != nil {
+		absentUsers = *params.AbsentUsers
+	}
+	dryRun := params.DryRun != nil && *params.DryRun
+	plan, err := s.ser.SyncOrg(r.Context(), teams, absentUsers, dryRun)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, plan)
+}
+
+func handleServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		sendError(w, http.StatusNotFound, models.NOTFOUND, "Not found")
+	case errors.Is(err, service.ErrConflict):
+		sendError(w, http.StatusConflict, models.PREXISTS, "Already exists")
+	case errors.Is(err, service.ErrInvalidInput):
+		sendValidationError(w, err.Error(), nil)
+	case errors.Is(err, service.ErrNoCandidate):
+		sendError(w, http.StatusConflict, models.NOCANDIDATE, "No candidate available")
+	default:
+		slog.Error("Request failed", "error", err)
+		sendError(w, http.StatusInternalServerError, models.INTERNALERROR, "Internal Server Error")
+	}
+}
+
+// handleParamError отвечает на ошибки разбора параметров в ServerInterfaceWrapper.
+func handleParamError(w http.ResponseWriter, r *http.Request, err error) {
+	var (
+		required *RequiredParamError
+		invalid  *InvalidParamFormatError
+	)
+	switch {
+	case errors.As(err, &required):
+		sendValidationError(w, "Request validation failed", []models.FieldError{
+			{Field: required.ParamName, Message: "parameter is required"},
+		})
+	case errors.As(err, &invalid):
+		sendValidationError(w, "Request validation failed", []models.FieldError{
+			{Field: invalid.ParamName, Message: invalid.Err.Error()},
+		})
+	default:
+		sendValidationError(w, err.Error(), nil)
+	}
+}
+
+// NotFound и MethodNotAllowed отвечают в формате ErrorResponse вместо текстовых ответов chi.
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	sendError(w, http.StatusNotFound, models.NOTFOUND, "Route not found")
+}
+
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	sendError(w, http.StatusMethodNotAllowed, models.METHODNOTALLOWED, "Method not allowed")
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler            ServerInterface
+	HandlerMiddlewares []MiddlewareFunc
+	ErrorHandlerFunc   func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+type MiddlewareFunc func(http.Handler) http.Handler
+
+// PostPullRequestCreate operation middleware
+func (siw *ServerInterfaceWrapper) PostPullRequestCreate(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostPullRequestCreate(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostPullRequestMerge operation middleware
+func (siw *ServerInterfaceWrapper) PostPullRequestMerge(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostPullRequestMerge(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostPullRequestReassign operation middleware
+func (siw *ServerInterfaceWrapper) PostPullRequestReassign(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostPullRequestReassign(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostPullRequestPreviewAssignment operation middleware
+func (siw *ServerInterfaceWrapper) PostPullRequestPreviewAssignment(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostPullRequestPreviewAssignment(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostTeamAdd operation middleware
+func (siw *ServerInterfaceWrapper) PostTeamAdd(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostTeamAdd(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetTeamGet operation middleware
+func (siw *ServerInterfaceWrapper) GetTeamGet(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.GetTeamGetParams
+
+	// ------------- Required query parameter "team_name" -------------
+
+	if paramValue := r.URL.Query().Get("team_name"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "team_name"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "team_name", r.URL.Query(), &params.TeamName)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "team_name", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetTeamGet(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostTeamRename operation middleware
+func (siw *ServerInterfaceWrapper) PostTeamRename(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostTeamRename(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostTeamDelete operation middleware
+func (siw *ServerInterfaceWrapper) PostTeamDelete(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostTeamDelete(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostUsersMoveTeam operation middleware
+func (siw *ServerInterfaceWrapper) PostUsersMoveTeam(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostUsersMoveTeam(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostTeamRemoveMember operation middleware
+func (siw *ServerInterfaceWrapper) PostTeamRemoveMember(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostTeamRemoveMember(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetAssignmentStats operation middleware
+func (siw *ServerInterfaceWrapper) GetAssignmentStats(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.GetAssignmentStatsParams
+
+	// ------------- Optional query parameter "team_name" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "team_name", r.URL.Query(), &params.TeamName)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "team_name", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "include_subteams" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "include_subteams", r.URL.Query(), &params.IncludeSubteams)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "include_subteams", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetAssignmentStats(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostTeamSetParent operation middleware
+func (siw *ServerInterfaceWrapper) PostTeamSetParent(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostTeamSetParent(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostTeamSetEscalationPolicy operation middleware
+func (siw *ServerInterfaceWrapper) PostTeamSetEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostTeamSetEscalationPolicy(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// PostTeamSetReviewCapacity operation middleware
+func (siw *ServerInterfaceWrapper) PostTeamSetReviewCapacity(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostTeamSetReviewCapacity(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetTeamTree operation middleware
+func (siw *ServerInterfaceWrapper) GetTeamTree(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.GetTeamTreeParams
+
+	// ------------- Optional query parameter "team_name" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "team_name", r.URL.Query(), &params.TeamName)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "team_name", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetTeamTree(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetPullRequestList operation middleware
+func (siw *ServerInterfaceWrapper) GetPullRequestList(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.GetPullRequestListParams
+
+	// ------------- Optional query parameter "team_name" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "team_name", r.URL.Query(), &params.TeamName)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "team_name", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "include_subteams" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "include_subteams", r.URL.Query(), &params.IncludeSubteams)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "include_subteams", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", r.URL.Query(), &params.Status)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "status", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", r.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetPullRequestList(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetUsersGet operation middleware
+func (siw *ServerInterfaceWrapper) GetUsersGet(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.GetUsersGetParams
+
+	// ------------- Required query parameter "user_id" -------------
+
+	if paramValue := r.URL.Query().Get("user_id"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "user_id"})
 		return
 	}
 
-	err := s.ser.AddTeam(r.Context(), body)
+	err = runtime.BindQueryParameter("form", true, true, "user_id", r.URL.Query(), &params.UserId)
 	if err != nil {
-		handleServiceError(w, err)
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "user_id", Err: err})
 		return
 	}
 
-	sendJSON(w, http.StatusOK, body)
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetUsersGet(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
 }
 
-// Получить команду с участниками
-// (GET /team/get)
-func (s *Server) GetTeamGet(w http.ResponseWriter, r *http.Request, params models.GetTeamGetParams) {
-	team, err := s.ser.GetTeam(r.Context(), params.TeamName)
+// GetUsersList operation middleware
+func (siw *ServerInterfaceWrapper) GetUsersList(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.GetUsersListParams
+
+	// ------------- Optional query parameter "team_name" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "team_name", r.URL.Query(), &params.TeamName)
 	if err != nil {
-		handleServiceError(w, err)
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "team_name", Err: err})
 		return
 	}
-	sendJSON(w, http.StatusOK, team)
-}
 
-// Получить статистику назначенных ревью
-// (GET /users/get)
-func (s *Server) GetAssignmentStats(w http.ResponseWriter, r *http.Request) {
-	stats, err := s.ser.GetAssignmentStats(r.Context())
+	// ------------- Optional query parameter "is_active" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "is_active", r.URL.Query(), &params.IsActive)
 	if err != nil {
-		handleServiceError(w, err)
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "is_active", Err: err})
 		return
 	}
-	sendJSON(w, http.StatusOK, stats)
-}
 
-// Получить PR'ы, где пользователь назначен ревьювером
-// (GET /users/getReview)
-func (s *Server) GetUsersGetReview(w http.ResponseWriter, r *http.Request, params models.GetUsersGetReviewParams) {
-	prs, err := s.ser.GetUsersReviews(r.Context(), params.UserId)
+	// ------------- Optional query parameter "name_prefix" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "name_prefix", r.URL.Query(), &params.NamePrefix)
 	if err != nil {
-		handleServiceError(w, err)
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "name_prefix", Err: err})
 		return
 	}
-	sendJSON(w, http.StatusOK, prs)
-}
 
-// Установить флаг активности пользователя
-// (POST /users/setIsActive)
-func (s *Server) PostUsersSetIsActive(w http.ResponseWriter, r *http.Request) {
-	var body models.PostUsersSetIsActiveJSONRequestBody
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		sendError(w, http.StatusBadRequest, models.NOTFOUND, "Invalid request body")
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
 		return
 	}
 
-	user, err := s.ser.SetUserActive(r.Context(), body)
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", r.URL.Query(), &params.Cursor)
 	if err != nil {
-		handleServiceError(w, err)
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
 		return
 	}
 
-	sendJSON(w, http.StatusOK, user)
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetUsersList(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
 }
 
-func handleServiceError(w http.ResponseWriter, err error) {
-	switch {
-	case errors.Is(err, service.ErrNotFound):
-		sendError(w, http.StatusNotFound, models.NOTFOUND, "Not found")
-	case errors.Is(err, service.ErrConflict):
-		sendError(w, http.StatusConflict, models.PREXISTS, "Already exists")
-	default:
-		sendError(w, http.StatusInternalServerError, models.NOTFOUND, "Internal Server Error")
+// PatchUsersUpdate operation middleware
+func (siw *ServerInterfaceWrapper) PatchUsersUpdate(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PatchUsersUpdate(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
 	}
+
+	handler.ServeHTTP(w, r)
 }
 
-// ServerInterfaceWrapper converts contexts to parameters.
-type ServerInterfaceWrapper struct {
-	Handler            ServerInterface
-	HandlerMiddlewares []MiddlewareFunc
-	ErrorHandlerFunc   func(w http.ResponseWriter, r *http.Request, err error)
+// PostUsersDelete operation middleware
+func (siw *ServerInterfaceWrapper) PostUsersDelete(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostUsersDelete(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
 }
 
-type MiddlewareFunc func(http.Handler) http.Handler
+// PostTeamImport operation middleware
+func (siw *ServerInterfaceWrapper) PostTeamImport(w http.ResponseWriter, r *http.Request) {
 
-// PostPullRequestCreate operation middleware
-func (siw *ServerInterfaceWrapper) PostPullRequestCreate(w http.ResponseWriter, r *http.Request) {
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.PostTeamImportParams
+
+	// ------------- Optional query parameter "dry_run" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "dry_run", r.URL.Query(), &params.DryRun)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "dry_run", Err: err})
+		return
+	}
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.PostPullRequestCreate(w, r)
+		siw.Handler.PostTeamImport(w, r, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -220,11 +1065,32 @@ func (siw *ServerInterfaceWrapper) PostPullRequestCreate(w http.ResponseWriter,
 	handler.ServeHTTP(w, r)
 }
 
-// PostPullRequestMerge operation middleware
-func (siw *ServerInterfaceWrapper) PostPullRequestMerge(w http.ResponseWriter, r *http.Request) {
+// PostOrgSync operation middleware
+func (siw *ServerInterfaceWrapper) PostOrgSync(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.PostOrgSyncParams
+
+	// ------------- Optional query parameter "dry_run" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "dry_run", r.URL.Query(), &params.DryRun)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "dry_run", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "absent_users" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "absent_users", r.URL.Query(), &params.AbsentUsers)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "absent_users", Err: err})
+		return
+	}
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.PostPullRequestMerge(w, r)
+		siw.Handler.PostOrgSync(w, r, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -234,11 +1100,61 @@ func (siw *ServerInterfaceWrapper) PostPullRequestMerge(w http.ResponseWriter, r
 	handler.ServeHTTP(w, r)
 }
 
-// PostPullRequestReassign operation middleware
-func (siw *ServerInterfaceWrapper) PostPullRequestReassign(w http.ResponseWriter, r *http.Request) {
+// GetEventsStream operation middleware
+func (siw *ServerInterfaceWrapper) GetEventsStream(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.GetEventsStreamParams
+
+	// ------------- Optional query parameter "team_name" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "team_name", r.URL.Query(), &params.TeamName)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "team_name", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "user_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "user_id", r.URL.Query(), &params.UserId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "user_id", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "last_event_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "last_event_id", r.URL.Query(), &params.LastEventId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "last_event_id", Err: err})
+		return
+	}
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "Last-Event-ID" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Last-Event-ID")]; found {
+		var LastEventID int64
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Last-Event-ID", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Last-Event-ID", valueList[0], &LastEventID, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Last-Event-ID", Err: err})
+			return
+		}
+
+		params.LastEventID = &LastEventID
+
+	}
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.PostPullRequestReassign(w, r)
+		siw.Handler.GetEventsStream(w, r, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -248,11 +1164,11 @@ func (siw *ServerInterfaceWrapper) PostPullRequestReassign(w http.ResponseWriter
 	handler.ServeHTTP(w, r)
 }
 
-// PostTeamAdd operation middleware
-func (siw *ServerInterfaceWrapper) PostTeamAdd(w http.ResponseWriter, r *http.Request) {
+// PostBatch operation middleware
+func (siw *ServerInterfaceWrapper) PostBatch(w http.ResponseWriter, r *http.Request) {
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.PostTeamAdd(w, r)
+		siw.Handler.PostBatch(w, r)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -262,31 +1178,31 @@ func (siw *ServerInterfaceWrapper) PostTeamAdd(w http.ResponseWriter, r *http.Re
 	handler.ServeHTTP(w, r)
 }
 
-// GetTeamGet operation middleware
-func (siw *ServerInterfaceWrapper) GetTeamGet(w http.ResponseWriter, r *http.Request) {
+// GetPullRequestGet operation middleware
+func (siw *ServerInterfaceWrapper) GetPullRequestGet(w http.ResponseWriter, r *http.Request) {
 
 	var err error
 
 	// Parameter object where we will unmarshal all parameters from the context
-	var params models.GetTeamGetParams
+	var params models.GetPullRequestGetParams
 
-	// ------------- Required query parameter "team_name" -------------
+	// ------------- Required query parameter "pull_request_id" -------------
 
-	if paramValue := r.URL.Query().Get("team_name"); paramValue != "" {
+	if paramValue := r.URL.Query().Get("pull_request_id"); paramValue != "" {
 
 	} else {
-		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "team_name"})
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "pull_request_id"})
 		return
 	}
 
-	err = runtime.BindQueryParameter("form", true, true, "team_name", r.URL.Query(), &params.TeamName)
+	err = runtime.BindQueryParameter("form", true, true, "pull_request_id", r.URL.Query(), &params.PullRequestId)
 	if err != nil {
-		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "team_name", Err: err})
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "pull_request_id", Err: err})
 		return
 	}
 
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetTeamGet(w, r, params)
+		siw.Handler.GetPullRequestGet(w, r, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -296,10 +1212,46 @@ func (siw *ServerInterfaceWrapper) GetTeamGet(w http.ResponseWriter, r *http.Req
 	handler.ServeHTTP(w, r)
 }
 
-// GetAssignmentStats operation middleware
-func (siw *ServerInterfaceWrapper) GetAssignmentStats(w http.ResponseWriter, r *http.Request) {
+// GetPullRequestGetAsOf operation middleware
+func (siw *ServerInterfaceWrapper) GetPullRequestGetAsOf(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params models.GetPullRequestGetAsOfParams
+
+	// ------------- Required query parameter "pull_request_id" -------------
+
+	if paramValue := r.URL.Query().Get("pull_request_id"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "pull_request_id"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "pull_request_id", r.URL.Query(), &params.PullRequestId)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "pull_request_id", Err: err})
+		return
+	}
+
+	// ------------- Required query parameter "at" -------------
+
+	if paramValue := r.URL.Query().Get("at"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "at"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "at", r.URL.Query(), &params.At)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "at", Err: err})
+		return
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		siw.Handler.GetAssignmentStats(w, r)
+		siw.Handler.GetPullRequestGetAsOf(w, r, params)
 	}))
 
 	for _, middleware := range siw.HandlerMiddlewares {
@@ -357,6 +1309,20 @@ func (siw *ServerInterfaceWrapper) PostUsersSetIsActive(w http.ResponseWriter, r
 	handler.ServeHTTP(w, r)
 }
 
+// PostUsersSetMaxOpenReviews operation middleware
+func (siw *ServerInterfaceWrapper) PostUsersSetMaxOpenReviews(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.PostUsersSetMaxOpenReviews(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 type UnescapedCookieParamError struct {
 	ParamName string
 	Err       error
@@ -460,9 +1426,7 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 		r = chi.NewRouter()
 	}
 	if options.ErrorHandlerFunc == nil {
-		options.ErrorHandlerFunc = func(w http.ResponseWriter, r *http.Request, err error) {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-		}
+		options.ErrorHandlerFunc = handleParamError
 	}
 	wrapper := ServerInterfaceWrapper{
 		Handler:            si,
@@ -473,27 +1437,90 @@ func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handl
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/pullRequest/create", wrapper.PostPullRequestCreate)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/pullRequest/list", wrapper.GetPullRequestList)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/pullRequest/get", wrapper.GetPullRequestGet)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/pullRequest/getAsOf", wrapper.GetPullRequestGetAsOf)
+	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/pullRequest/merge", wrapper.PostPullRequestMerge)
 	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/pullRequest/reassign", wrapper.PostPullRequestReassign)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/pullRequest/previewAssignment", wrapper.PostPullRequestPreviewAssignment)
+	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/team/add", wrapper.PostTeamAdd)
 	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/team/get", wrapper.GetTeamGet)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/team/import", wrapper.PostTeamImport)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/org/sync", wrapper.PostOrgSync)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/events/stream", wrapper.GetEventsStream)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/batch", wrapper.PostBatch)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/team/rename", wrapper.PostTeamRename)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/team/delete", wrapper.PostTeamDelete)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/team/removeMember", wrapper.PostTeamRemoveMember)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/team/setEscalationPolicy", wrapper.PostTeamSetEscalationPolicy)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/team/setReviewCapacity", wrapper.PostTeamSetReviewCapacity)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/team/setParent", wrapper.PostTeamSetParent)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/team/tree", wrapper.GetTeamTree)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/users/delete", wrapper.PostUsersDelete)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/users/get", wrapper.GetUsersGet)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/users/getAssignmentStats", wrapper.GetAssignmentStats)
 	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/users/list", wrapper.GetUsersList)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/users/moveTeam", wrapper.PostUsersMoveTeam)
+	})
 	r.Group(func(r chi.Router) {
 		r.Get(options.BaseURL+"/users/getReview", wrapper.GetUsersGetReview)
 	})
 	r.Group(func(r chi.Router) {
 		r.Post(options.BaseURL+"/users/setIsActive", wrapper.PostUsersSetIsActive)
 	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/users/setMaxOpenReviews", wrapper.PostUsersSetMaxOpenReviews)
+	})
+	r.Group(func(r chi.Router) {
+		r.Patch(options.BaseURL+"/users/update", wrapper.PatchUsersUpdate)
+	})
 
 	return r
 }
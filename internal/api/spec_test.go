@@ -0,0 +1,77 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	openapi "pull-request-api.com"
+	"pull-request-api.com/internal/api"
+)
+
+// Маршруты chi и пути спецификации не должны расходиться: новый эндпоинт
+// без описания в openapi.yml (или наоборот) ломает этот тест.
+func TestSpec_MatchesRegisteredRoutes(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData(openapi.Spec)
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate(context.Background()))
+
+	var specRoutes []string
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			specRoutes = append(specRoutes, method+" "+path)
+		}
+	}
+
+	r := chi.NewRouter()
+	api.HandlerFromMux(api.NewServer(nil), r)
+
+	var chiRoutes []string
+	err = chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		chiRoutes = append(chiRoutes, method+" "+route)
+		return nil
+	})
+	require.NoError(t, err)
+
+	sort.Strings(specRoutes)
+	sort.Strings(chiRoutes)
+	assert.Equal(t, specRoutes, chiRoutes)
+}
+
+func TestSpec_Served(t *testing.T) {
+	r := chi.NewRouter()
+	require.NoError(t, api.RegisterDocs(r, openapi.Spec))
+
+	resp := do(r, http.MethodGet, "/openapi.yml", "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, openapi.Spec, resp.Body.Bytes())
+
+	resp = do(r, http.MethodGet, "/openapi.json", "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	var spec map[string]any
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &spec))
+	assert.Contains(t, spec["paths"], "/users/getAssignmentStats")
+
+	resp = do(r, http.MethodGet, "/docs", "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.True(t, strings.Contains(resp.Body.String(), "openapi.json"))
+	assert.NotContains(t, resp.Body.String(), "https://", "страница не должна тянуть статику с CDN")
+
+	for _, asset := range []string{"/docs/swagger-ui-bundle.js", "/docs/swagger-ui.css"} {
+		resp = do(r, http.MethodGet, asset, "")
+		require.Equal(t, http.StatusOK, resp.Code, asset)
+		assert.NotEmpty(t, resp.Body.Bytes(), asset)
+	}
+
+	resp = do(r, http.MethodGet, "/docs/", "")
+	require.Equal(t, http.StatusMovedPermanently, resp.Code)
+	assert.Equal(t, "/docs", resp.Header().Get("Location"))
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"pull-request-api.com/internal/models"
+	"pull-request-api.com/internal/notify"
+	"pull-request-api.com/internal/service"
+)
+
+// Без Hub поток перечитывает журнал с этим интервалом. С Hub — только по
+// сигналу: событие, закоммиченное позже следующих, тоже его отправляет.
+const streamPollInterval = time.Second
+
+// streamHeartbeat — интервал комментариев-пингов, чтобы прокси не закрывали
+// простаивающее соединение.
+const streamHeartbeat = 15 * time.Second
+
+// WithEventHub включает мгновенную доставку в GET /events/stream: поток
+// перечитывает журнал по сигналу hub, а не по таймеру.
+func (s *Server) WithEventHub(hub *notify.Hub) *Server {
+	s.events = hub
+	return s
+}
+
+// Поток изменений PR и активности пользователей (Server-Sent Events)
+// (GET /events/stream)
+func (s *Server) GetEventsStream(w http.ResponseWriter, r *http.Request, params models.GetEventsStreamParams) {
+	ctx := r.Context()
+
+	var filter service.EventFilter
+	if params.TeamName != nil {
+		filter.TeamName = *params.TeamName
+	}
+	if params.UserId != nil {
+		filter.UserID = *params.UserId
+	}
+	after := params.LastEventID
+	if after == nil {
+		after = params.LastEventId
+	}
+
+	// Подписка до первого чтения: событие между чтением и подпиской не потеряется.
+	var wake <-chan struct{}
+	if s.events != nil {
+		ch, cancel := s.events.Subscribe()
+		defer cancel()
+		wake = ch
+	}
+
+	cursor, err := s.ser.NewEventCursor(ctx, filter, after)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		slog.Error("Event stream is not supported by the response writer", "error", err)
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	var retry <-chan time.Time
+	for {
+		events, err := cursor.Next(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("Event stream failed", "error", err)
+			}
+			return
+		}
+		for _, ev := range events {
+			data, err := json.Marshal(ev)
+			if err != nil {
+				slog.Error("Event stream failed", "error", err)
+				return
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.Id, ev.Type, data); err != nil {
+				return
+			}
+		}
+		if len(events) > 0 {
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+
+		retry = nil
+		if wake == nil {
+			retry = time.After(streamPollInterval)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+		case <-retry:
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+
+	"pull-request-api.com/internal/models"
+)
+
+// RequestValidator проверяет запросы по OpenAPI-спецификации: обязательные поля,
+// длины строк, неизвестные поля, параметры запроса.
+type RequestValidator struct {
+	router routers.Router
+}
+
+func NewRequestValidator(spec []byte) (*RequestValidator, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("load openapi spec: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid openapi spec: %w", err)
+	}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &RequestValidator{router: router}, nil
+}
+
+// ValidateRequests отклоняет запросы, не соответствующие спецификации, с кодом
+// VALIDATION_ERROR и списком ошибок по полям. Маршруты, которых нет в
+// спецификации, пропускаются без проверки.
+func ValidateRequests(v *RequestValidator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, err := v.router.FindRoute(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			err = openapi3filter.ValidateRequest(r.Context(), &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: pathParams,
+				Route:      route,
+				Options: &openapi3filter.Options{
+					MultiError: true,
+					// Аутентификацию проверяет Authenticate, здесь только схема.
+					AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+				},
+			})
+			if err != nil {
+				sendValidationError(w, "Request validation failed", fieldErrors(err))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func fieldErrors(err error) []models.FieldError {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		var details []models.FieldError
+		for _, inner := range e {
+			details = append(details, fieldErrors(inner)...)
+		}
+		return details
+	case *openapi3filter.RequestError:
+		prefix := ""
+		if e.Parameter != nil {
+			prefix = e.Parameter.Name
+		}
+
+		var details []models.FieldError
+		for _, inner := range flatten(e.Err) {
+			var schemaErr *openapi3.SchemaError
+			if errors.As(inner, &schemaErr) {
+				details = append(details, schemaFieldError(prefix, schemaErr))
+			}
+		}
+		if len(details) > 0 {
+			return details
+		}
+		if prefix == "" {
+			prefix = "body"
+		}
+		return []models.FieldError{{Field: prefix, Message: e.Error()}}
+	default:
+		return []models.FieldError{{Field: "", Message: err.Error()}}
+	}
+}
+
+func flatten(err error) []error {
+	if multi, ok := err.(openapi3.MultiError); ok {
+		var errs []error
+		for _, e := range multi {
+			errs = append(errs, flatten(e)...)
+		}
+		return errs
+	}
+	return []error{err}
+}
+
+func schemaFieldError(prefix string, err *openapi3.SchemaError) models.FieldError {
+	path := err.JSONPointer()
+	// Для неизвестного поля указатель ведёт на объект, имя поля есть только в тексте.
+	if name, ok := strings.CutPrefix(err.Reason, "property \""); ok {
+		if name, _, ok := strings.Cut(name, "\""); ok && (len(path) == 0 || path[len(path)-1] != name) {
+			path = append(path, name)
+		}
+	}
+
+	field := strings.Join(append(strings.Fields(prefix), path...), ".")
+	return models.FieldError{Field: field, Message: err.Reason}
+}
@@ -0,0 +1,143 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	openapi "pull-request-api.com"
+	"pull-request-api.com/internal/api"
+	"pull-request-api.com/internal/models"
+)
+
+// Сервис не нужен: невалидные запросы не доходят до обработчиков.
+func newValidatedRouter(t *testing.T) *chi.Mux {
+	v, err := api.NewRequestValidator(openapi.Spec)
+	require.NoError(t, err)
+
+	r := chi.NewRouter()
+	r.NotFound(api.NotFound)
+	r.MethodNotAllowed(api.MethodNotAllowed)
+	r.Use(api.ValidateRequests(v))
+	api.HandlerFromMux(api.NewServer(nil), r)
+	return r
+}
+
+func TestValidation_RequestBody(t *testing.T) {
+	r := newValidatedRouter(t)
+
+	cases := []struct {
+		name   string
+		path   string
+		body   string
+		fields []string
+	}{
+		{"empty id", "/pullRequest/create", `{"pull_request_id":"","pull_request_name":"x","author_id":"u1"}`, []string{"pull_request_id"}},
+		{"missing field", "/pullRequest/create", `{"pull_request_id":"pr","pull_request_name":"x"}`, []string{"author_id"}},
+		{"unknown field", "/pullRequest/merge", `{"pull_request_id":"pr","force":true}`, []string{"force"}},
+		{"too long", "/pullRequest/merge", `{"pull_request_id":"` + strings.Repeat("x", 129) + `"}`, []string{"pull_request_id"}},
+		{"nested member", "/team/add", `{"team_name":"T","members":[{"user_id":"","username":"A","is_active":true}]}`, []string{"members.0.user_id"}},
+		{"several errors", "/users/setIsActive", `{"user_id":""}`, []string{"user_id", "is_active"}},
+		{"not json", "/team/add", `{`, []string{"body"}},
+		{"enum", "/users/delete", `{"user_id":"u1","review_policy":"keep"}`, []string{"review_policy"}},
+		{"batch op", "/batch", `{"operations":[{"op":"merge","pull_request_id":"pr"},{"op":"close"}]}`, []string{"operations.1.op"}},
+		{"empty batch", "/batch", `{"operations":[]}`, []string{"operations"}},
+		{"negative limit", "/users/setMaxOpenReviews", `{"user_id":"u1","max_open_reviews":-1}`, []string{"max_open_reviews"}},
+		{"capacity policy", "/team/setReviewCapacity", `{"team_name":"T","capacity_policy":"wait"}`, []string{"capacity_policy"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := do(r, http.MethodPost, tc.path, tc.body)
+			require.Equal(t, http.StatusBadRequest, resp.Code, resp.Body.String())
+
+			errResp := decodeError(t, resp)
+			assert.Equal(t, models.VALIDATIONERROR, errResp.Error.Code)
+			require.NotNil(t, errResp.Error.Details)
+
+			var fields []string
+			for _, d := range *errResp.Error.Details {
+				fields = append(fields, d.Field)
+			}
+			assert.ElementsMatch(t, tc.fields, fields)
+		})
+	}
+}
+
+func TestValidation_QueryParams(t *testing.T) {
+	r := newValidatedRouter(t)
+
+	resp := do(r, http.MethodGet, "/team/get", "")
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+	errResp := decodeError(t, resp)
+	assert.Equal(t, models.VALIDATIONERROR, errResp.Error.Code)
+	require.NotNil(t, errResp.Error.Details)
+	assert.Equal(t, "team_name", (*errResp.Error.Details)[0].Field)
+
+	resp = do(r, http.MethodGet, "/users/list?limit=500", "")
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+	errResp = decodeError(t, resp)
+	require.NotNil(t, errResp.Error.Details)
+	assert.Equal(t, "limit", (*errResp.Error.Details)[0].Field)
+
+	// Без валидатора обязательный параметр проверяет обёртка chi — тоже JSON.
+	bare := chi.NewRouter()
+	api.HandlerFromMux(api.NewServer(nil), bare)
+	resp = do(bare, http.MethodGet, "/users/getReview", "")
+	require.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, models.VALIDATIONERROR, decodeError(t, resp).Error.Code)
+}
+
+func TestValidation_UnknownRoute(t *testing.T) {
+	resp := do(newValidatedRouter(t), http.MethodGet, "/nope", "")
+	require.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, models.NOTFOUND, decodeError(t, resp).Error.Code)
+}
+
+func TestValidation_WrongMethod(t *testing.T) {
+	resp := do(newValidatedRouter(t), http.MethodGet, "/pullRequest/create", "")
+	require.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+	assert.Equal(t, models.METHODNOTALLOWED, decodeError(t, resp).Error.Code)
+}
+
+func do(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeError(t *testing.T, resp *httptest.ResponseRecorder) models.ErrorResponse {
+	assert.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+	var errResp models.ErrorResponse
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &errResp))
+	return errResp
+}
+
+func TestValidation_OrgFile(t *testing.T) {
+	r := newValidatedRouter(t)
+
+	for contentType, body := range map[string]string{
+		"text/csv":         "team_name,user_id,username,email\nT,u1,A,a@x\n",
+		"application/yaml": "teams:\n  - team_name: T\n    members:\n      - {user_id: u1, username: A, email: a@x}\n",
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/team/import?dry_run=true", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		require.Equal(t, http.StatusBadRequest, resp.Code, resp.Body.String())
+		errResp := decodeError(t, resp)
+		require.NotNil(t, errResp.Error.Details, contentType)
+		assert.Contains(t, (*errResp.Error.Details)[0].Field, "email", contentType)
+	}
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// Principal описывает вызывающего, извлечённого из bearer-токена.
+type Principal struct {
+	UserID string
+	Roles  []string
+}
+
+func (p *Principal) HasRole(role string) bool {
+	return p != nil && slices.Contains(p.Roles, role)
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext возвращает вызывающего или nil, если запрос не аутентифицирован
+// (например, когда аутентификация выключена).
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
@@ -0,0 +1,11 @@
+package auth
+
+import "time"
+
+// ExpireThrottle снимает ограничение minRefresh, как если бы с последней
+// попытки перечитывания прошло достаточно времени.
+func (ks *KeySet) ExpireThrottle() {
+	ks.mu.Lock()
+	ks.lastAttempt = time.Time{}
+	ks.mu.Unlock()
+}
@@ -0,0 +1,247 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// errUnsupportedKey — ключ корректен, но его тип или кривая не поддерживаются;
+// такие ключи пропускаются, а не ломают весь набор.
+var errUnsupportedKey = errors.New("unsupported key")
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// KeySet хранит ключи из JWKS (файл или URL) и периодически перечитывает их,
+// чтобы подхватывать ротацию ключей IdP.
+type KeySet struct {
+	source string
+	client *http.Client
+
+	// minRefresh ограничивает внеплановые перечитывания при неизвестном kid.
+	minRefresh time.Duration
+
+	mu   sync.RWMutex
+	keys map[string]any
+	// lastAttempt — время последней попытки перечитывания, в том числе
+	// неудачной: пока IdP недоступен, неизвестный kid не должен вызывать
+	// запрос на каждый входящий токен.
+	lastAttempt time.Time
+}
+
+// NewKeySet создаёт набор ключей. source — путь к файлу или http(s) URL.
+// Ключи загружаются сразу, чтобы ошибка конфигурации была видна при старте.
+func NewKeySet(ctx context.Context, source string) (*KeySet, error) {
+	ks := &KeySet{
+		source:     source,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		minRefresh: 30 * time.Second,
+		keys:       map[string]any{},
+	}
+	if err := ks.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// Refresh перечитывает JWKS из источника.
+func (ks *KeySet) Refresh(ctx context.Context) error {
+	ks.mu.Lock()
+	ks.lastAttempt = time.Now()
+	ks.mu.Unlock()
+
+	data, err := ks.fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("load jwks from %s: %w", ks.source, err)
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("parse jwks from %s: %w", ks.source, err)
+	}
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.mu.Unlock()
+	return nil
+}
+
+// Run перечитывает ключи с заданным интервалом, пока не отменён ctx.
+func (ks *KeySet) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := ks.Refresh(ctx); err != nil {
+				slog.Error("JWKS refresh failed", "error", err)
+			}
+		}
+	}
+}
+
+// Key возвращает публичный ключ по kid. Если ключ неизвестен, набор один раз
+// перечитывается (не чаще minRefresh, считая и неудачные попытки) — так новый
+// ключ IdP подхватывается без ожидания тика. Перечитывание не зависит от отмены
+// запроса: его ограничивает таймаут HTTP-клиента.
+func (ks *KeySet) Key(ctx context.Context, kid string) (any, error) {
+	ks.mu.Lock()
+	key, ok := ks.lookup(kid)
+	stale := time.Since(ks.lastAttempt) >= ks.minRefresh
+	if !ok && stale {
+		// Занимаем попытку сразу, чтобы параллельные запросы не ушли в IdP все разом.
+		ks.lastAttempt = time.Now()
+	}
+	ks.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	if !stale {
+		return nil, ErrUnknownKey
+	}
+
+	if err := ks.Refresh(context.WithoutCancel(ctx)); err != nil {
+		return nil, err
+	}
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	if key, ok := ks.lookup(kid); ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// lookup без kid допустим только если в наборе ровно один ключ.
+func (ks *KeySet) lookup(kid string) (any, bool) {
+	if kid == "" && len(ks.keys) == 1 {
+		for _, k := range ks.keys {
+			return k, true
+		}
+	}
+	key, ok := ks.keys[kid]
+	return key, ok
+}
+
+func (ks *KeySet) fetch(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(ks.source, "http://") && !strings.HasPrefix(ks.source, "https://") {
+		return os.ReadFile(ks.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+func parseJWKS(data []byte) (map[string]any, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if errors.Is(err, errUnsupportedKey) {
+			slog.Warn("Skipping JWKS key", "kid", k.Kid, "error", err)
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = pub
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no signing keys")
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("%w: curve %q", errUnsupportedKey, k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("%w: key type %q", errUnsupportedKey, k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
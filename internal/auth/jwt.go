@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+type Config struct {
+	Issuer   string
+	Audience string
+	// UserClaim — claim с user_id, по умолчанию "sub".
+	UserClaim string
+	// RolesClaim — claim с ролями; допускается путь через точку ("realm_access.roles").
+	// Значение может быть массивом строк или строкой с ролями через пробел.
+	RolesClaim string
+	// Leeway — допустимое расхождение часов при проверке exp/nbf.
+	Leeway time.Duration
+}
+
+// Verifier проверяет подпись и claims bearer-токенов.
+type Verifier struct {
+	keys *KeySet
+	cfg  Config
+}
+
+func NewVerifier(keys *KeySet, cfg Config) *Verifier {
+	if cfg.UserClaim == "" {
+		cfg.UserClaim = "sub"
+	}
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = "roles"
+	}
+	return &Verifier{keys: keys, cfg: cfg}
+}
+
+func (v *Verifier) Verify(ctx context.Context, raw string) (*Principal, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(v.cfg.Leeway),
+	}
+	if v.cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(v.cfg.Issuer))
+	}
+	if v.cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(v.cfg.Audience))
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	userID, _ := lookupClaim(claims, v.cfg.UserClaim).(string)
+	if userID == "" {
+		return nil, fmt.Errorf("%w: claim %q is missing", ErrInvalidToken, v.cfg.UserClaim)
+	}
+
+	return &Principal{
+		UserID: userID,
+		Roles:  rolesFromClaim(lookupClaim(claims, v.cfg.RolesClaim)),
+	}, nil
+}
+
+func lookupClaim(claims map[string]any, path string) any {
+	var cur any = claims
+	for _, part := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+		cur = m[part]
+	}
+	return cur
+}
+
+func rolesFromClaim(v any) []string {
+	switch val := v.(type) {
+	case string:
+		return strings.Fields(val)
+	case []any:
+		roles := make([]string, 0, len(val))
+		for _, r := range val {
+			if s, ok := r.(string); ok {
+				roles = append(roles, s)
+			}
+		}
+		return roles
+	default:
+		return nil
+	}
+}
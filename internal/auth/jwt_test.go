@@ -0,0 +1,216 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pull-request-api.com/internal/auth"
+)
+
+func TestVerifier_ValidRSAToken(t *testing.T) {
+	key := newRSAKey(t)
+	keys := keySetFromFile(t, rsaJWK("k1", key))
+	v := auth.NewVerifier(keys, auth.Config{Issuer: "https://sso", Audience: "pr-api"})
+
+	token := sign(t, jwt.SigningMethodRS256, "k1", key, jwt.MapClaims{
+		"sub":   "alice",
+		"iss":   "https://sso",
+		"aud":   "pr-api",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"admin", "reviewer"},
+	})
+
+	p, err := v.Verify(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", p.UserID)
+	assert.True(t, p.HasRole("admin"))
+	assert.False(t, p.HasRole("owner"))
+}
+
+func TestVerifier_CustomClaimsAndEC(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	keys := keySetFromFile(t, ecJWK("ec1", key))
+	v := auth.NewVerifier(keys, auth.Config{UserClaim: "preferred_username", RolesClaim: "realm_access.roles"})
+
+	token := sign(t, jwt.SigningMethodES256, "ec1", key, jwt.MapClaims{
+		"preferred_username": "bob",
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"realm_access":       map[string]any{"roles": []string{"lead"}},
+	})
+
+	p, err := v.Verify(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", p.UserID)
+	assert.Equal(t, []string{"lead"}, p.Roles)
+}
+
+func TestVerifier_Rejects(t *testing.T) {
+	key := newRSAKey(t)
+	other := newRSAKey(t)
+	keys := keySetFromFile(t, rsaJWK("k1", key))
+	v := auth.NewVerifier(keys, auth.Config{Issuer: "https://sso"})
+
+	valid := jwt.MapClaims{"sub": "alice", "iss": "https://sso", "exp": time.Now().Add(time.Hour).Unix()}
+
+	cases := map[string]string{
+		"expired": sign(t, jwt.SigningMethodRS256, "k1", key, jwt.MapClaims{
+			"sub": "alice", "iss": "https://sso", "exp": time.Now().Add(-time.Hour).Unix(),
+		}),
+		"no exp":       sign(t, jwt.SigningMethodRS256, "k1", key, jwt.MapClaims{"sub": "alice", "iss": "https://sso"}),
+		"wrong issuer": sign(t, jwt.SigningMethodRS256, "k1", key, jwt.MapClaims{"sub": "alice", "iss": "evil", "exp": time.Now().Add(time.Hour).Unix()}),
+		"wrong key":    sign(t, jwt.SigningMethodRS256, "k1", other, valid),
+		"no subject":   sign(t, jwt.SigningMethodRS256, "k1", key, jwt.MapClaims{"iss": "https://sso", "exp": time.Now().Add(time.Hour).Unix()}),
+		"hmac":         sign(t, jwt.SigningMethodHS256, "k1", []byte("secret"), valid),
+		"garbage":      "not-a-token",
+	}
+
+	for name, token := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := v.Verify(context.Background(), token)
+			assert.ErrorIs(t, err, auth.ErrInvalidToken)
+		})
+	}
+}
+
+func TestKeySet_RotationFromURL(t *testing.T) {
+	oldKey := newRSAKey(t)
+	newKey := newRSAKey(t)
+
+	current := jwksJSON(t, rsaJWK("old", oldKey))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(current)
+	}))
+	defer srv.Close()
+
+	keys, err := auth.NewKeySet(context.Background(), srv.URL)
+	require.NoError(t, err)
+	v := auth.NewVerifier(keys, auth.Config{})
+
+	claims := jwt.MapClaims{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()}
+	_, err = v.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "old", oldKey, claims))
+	require.NoError(t, err)
+
+	// IdP ротирует ключ: старый убран, новый опубликован.
+	current = jwksJSON(t, rsaJWK("new", newKey))
+	require.NoError(t, keys.Refresh(context.Background()))
+
+	_, err = v.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "new", newKey, claims))
+	assert.NoError(t, err)
+	_, err = v.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "old", oldKey, claims))
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+}
+
+func TestKeySet_SkipsUnsupportedKeys(t *testing.T) {
+	key := newRSAKey(t)
+	keys := keySetFromFile(t,
+		map[string]string{"kid": "ed", "kty": "OKP", "crv": "Ed25519", "x": "AAAA"},
+		map[string]string{"kid": "hmac", "kty": "oct"},
+		map[string]string{"kid": "k256", "kty": "EC", "crv": "secp256k1", "x": "AAAA", "y": "AAAA"},
+		rsaJWK("k1", key),
+	)
+	v := auth.NewVerifier(keys, auth.Config{})
+
+	claims := jwt.MapClaims{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()}
+	_, err := v.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "k1", key, claims))
+	assert.NoError(t, err)
+}
+
+func TestKeySet_ThrottlesFailedRefresh(t *testing.T) {
+	key := newRSAKey(t)
+	var hits atomic.Int32
+	down := atomic.Bool{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(jwksJSON(t, rsaJWK("k1", key)))
+	}))
+	defer srv.Close()
+
+	keys, err := auth.NewKeySet(context.Background(), srv.URL)
+	require.NoError(t, err)
+	down.Store(true)
+	keys.ExpireThrottle()
+
+	// IdP недоступен: неудачная попытка тоже должна сдвигать окно minRefresh,
+	// иначе каждый токен с неизвестным kid уходит в IdP.
+	for range 5 {
+		_, err := keys.Key(context.Background(), "unknown")
+		assert.Error(t, err)
+	}
+	assert.EqualValues(t, 2, hits.Load())
+}
+
+// --- Хэлперы ---
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return key
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	s, err := token.SignedString(key)
+	require.NoError(t, err)
+	return s
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kid": kid,
+		"kty": "RSA",
+		"use": "sig",
+		"n":   b64(key.N.Bytes()),
+		"e":   b64(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kid": kid,
+		"kty": "EC",
+		"crv": "P-256",
+		"x":   b64(key.X.FillBytes(make([]byte, 32))),
+		"y":   b64(key.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+func jwksJSON(t *testing.T, keys ...map[string]string) []byte {
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	require.NoError(t, err)
+	return data
+}
+
+func keySetFromFile(t *testing.T, keys ...map[string]string) *auth.KeySet {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwksJSON(t, keys...), 0o600))
+	ks, err := auth.NewKeySet(context.Background(), path)
+	require.NoError(t, err)
+	return ks
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
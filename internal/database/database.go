@@ -2,13 +2,10 @@ package database
 
 import (
 	"database/sql"
-	"errors"
 	"fmt"
 	"log"
 	"time"
 
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
@@ -32,23 +29,13 @@ func Connect(dsn string) (*sql.DB, error) {
 	return nil, fmt.Errorf("could not connect to database after retries: %w", err)
 }
 
+// Migrate применяет все неприменённые миграции.
 func Migrate(db *sql.DB, dbName string, sourceURL string) error {
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	m, err := NewMigrator(db, dbName, sourceURL)
 	if err != nil {
 		return err
 	}
+	defer m.Close()
 
-	m, err := migrate.NewWithDatabaseInstance(
-		sourceURL,
-		dbName,
-		driver,
-	)
-	if err != nil {
-		return err
-	}
-
-	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		return err
-	}
-	return nil
+	return m.Up()
 }
@@ -0,0 +1,173 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+// Ошибки проверки схемы перед запуском сервера.
+var (
+	ErrSchemaDirty    = errors.New("schema is dirty")
+	ErrSchemaTooNew   = errors.New("schema is newer than this binary")
+	ErrSchemaOutdated = errors.New("schema is outdated")
+)
+
+// Migrator управляет версией схемы: применяет и откатывает миграции из
+// sourceURL (свой набор для каждой СУБД). Close не закрывает db.
+type Migrator struct {
+	m   *migrate.Migrate
+	src source.Driver
+}
+
+// SchemaStatus — текущая версия схемы и список известных бинарнику миграций.
+type SchemaStatus struct {
+	// Version — 0, если ни одна миграция не применялась.
+	Version    uint
+	Dirty      bool
+	Latest     uint
+	Migrations []Migration
+}
+
+// Migration — миграция из источника.
+type Migration struct {
+	Version uint
+	Name    string
+	Applied bool
+}
+
+func NewMigrator(db *sql.DB, dbName string, sourceURL string) (*Migrator, error) {
+	src, err := source.Open(sourceURL)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := migrationDriver(db)
+	if err != nil {
+		src.Close()
+		return nil, err
+	}
+
+	m, err := migrate.NewWithDatabaseInstance(sourceURL, dbName, driver)
+	if err != nil {
+		driver.Close()
+		src.Close()
+		return nil, err
+	}
+	return &Migrator{m: m, src: src}, nil
+}
+
+func migrationDriver(db *sql.DB) (migratedb.Driver, error) {
+	if DialectOf(db) == SQLite {
+		driver, err := sqlite3.WithInstance(db, &sqlite3.Config{})
+		if err != nil {
+			return nil, err
+		}
+		return sharedDB{driver}, nil
+	}
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	driver, err := postgres.WithConnection(context.Background(), conn, &postgres.Config{})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return driver, nil
+}
+
+// sharedDB не даёт Migrator.Close закрыть *sql.DB приложения: драйвер SQLite
+// работает с ним напрямую, а не с отдельным соединением.
+type sharedDB struct {
+	migratedb.Driver
+}
+
+func (sharedDB) Close() error { return nil }
+
+func (mg *Migrator) Close() error {
+	srcErr, dbErr := mg.m.Close()
+	return errors.Join(srcErr, dbErr, mg.src.Close())
+}
+
+// Up применяет все неприменённые миграции.
+func (mg *Migrator) Up() error {
+	return ignoreNoChange(mg.m.Up())
+}
+
+// Down откатывает n последних миграций.
+func (mg *Migrator) Down(n int) error {
+	if n < 1 {
+		return fmt.Errorf("number of migrations to roll back must be positive, got %d", n)
+	}
+	return ignoreNoChange(mg.m.Steps(-n))
+}
+
+// Goto применяет или откатывает миграции до указанной версии.
+func (mg *Migrator) Goto(version uint) error {
+	return ignoreNoChange(mg.m.Migrate(version))
+}
+
+// Force записывает версию и снимает признак dirty, не выполняя миграций.
+// Нужна после ручного исправления упавшей миграции; -1 — схема без миграций.
+func (mg *Migrator) Force(version int) error {
+	return mg.m.Force(version)
+}
+
+func (mg *Migrator) Status() (*SchemaStatus, error) {
+	version, dirty, err := mg.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return nil, err
+	}
+	status := &SchemaStatus{Version: version, Dirty: dirty}
+
+	v, err := mg.src.First()
+	for err == nil {
+		name := ""
+		if r, identifier, err := mg.src.ReadUp(v); err == nil {
+			r.Close()
+			name = identifier
+		}
+		status.Migrations = append(status.Migrations, Migration{Version: v, Name: name, Applied: v <= version})
+		status.Latest = v
+		v, err = mg.src.Next(v)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return status, nil
+}
+
+// Check проверяет, что сервер может работать со схемой: она не dirty
+// и её версия совпадает с последней известной миграцией.
+func (mg *Migrator) Check() error {
+	status, err := mg.Status()
+	if err != nil {
+		return err
+	}
+	switch {
+	case status.Dirty:
+		return fmt.Errorf("%w: migration %d failed; fix the database and run `migrate force VERSION`", ErrSchemaDirty, status.Version)
+	case status.Version > status.Latest:
+		return fmt.Errorf("%w: database is at version %d, latest known migration is %d", ErrSchemaTooNew, status.Version, status.Latest)
+	case status.Version < status.Latest:
+		return fmt.Errorf("%w: database is at version %d, latest migration is %d; run `migrate up`", ErrSchemaOutdated, status.Version, status.Latest)
+	}
+	return nil
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,156 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Dialect — СУБД, с которой работает сервис.
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+// sqliteDriverName — драйвер database/sql для SQLite, принимающий запросы
+// в синтаксисе PostgreSQL (см. rewriteQuery). Требует сборки с CGO.
+const sqliteDriverName = "sqlite3-pg"
+
+func init() {
+	sql.Register(sqliteDriverName, &sqliteDriver{
+		SQLiteDriver: sqlite3.SQLiteDriver{ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			// Встроенная lower() меняет регистр только у ASCII, а поиск по
+			// префиксу имени (ListUsers) должен работать и для кириллицы.
+			return conn.RegisterFunc("lower", strings.ToLower, true)
+		}},
+	})
+}
+
+// DialectOf определяет СУБД по драйверу подключения.
+func DialectOf(db *sql.DB) Dialect {
+	if _, ok := db.Driver().(*sqliteDriver); ok {
+		return SQLite
+	}
+	return Postgres
+}
+
+// ConnectSQLite открывает базу SQLite в файле path (создаётся, если её нет).
+// Транзакции начинаются с BEGIN IMMEDIATE: пишущая транзакция сразу получает
+// блокировку всей базы, поэтому блокировки строк (FOR UPDATE) не нужны.
+func ConnectSQLite(path string) (*sql.DB, error) {
+	dsn := "file:" + path + "?_txlock=immediate&_busy_timeout=10000&_foreign_keys=on&_journal_mode=WAL"
+	db, err := sql.Open(sqliteDriverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open sqlite database %s: %w", path, err)
+	}
+	return db, nil
+}
+
+type sqliteDriver struct {
+	sqlite3.SQLiteDriver
+}
+
+func (d *sqliteDriver) Open(dsn string) (driver.Conn, error) {
+	conn, err := d.SQLiteDriver.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteConn{conn.(*sqlite3.SQLiteConn)}, nil
+}
+
+type sqliteConn struct {
+	*sqlite3.SQLiteConn
+}
+
+func (c *sqliteConn) Prepare(query string) (driver.Stmt, error) {
+	return c.SQLiteConn.Prepare(rewriteQuery(query))
+}
+
+func (c *sqliteConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.SQLiteConn.PrepareContext(ctx, rewriteQuery(query))
+}
+
+func (c *sqliteConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.SQLiteConn.ExecContext(ctx, rewriteQuery(query), args)
+}
+
+func (c *sqliteConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.SQLiteConn.QueryContext(ctx, rewriteQuery(query), args)
+}
+
+// BeginTx начинает читающую транзакцию с BEGIN DEFERRED: она не берёт
+// блокировку записи и не ждёт пишущих транзакций (в режиме WAL). Остальные
+// транзакции начинаются с BEGIN IMMEDIATE из DSN.
+func (c *sqliteConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if !opts.ReadOnly {
+		return c.SQLiteConn.BeginTx(ctx, opts)
+	}
+	if _, err := c.SQLiteConn.ExecContext(ctx, "BEGIN DEFERRED", nil); err != nil {
+		return nil, err
+	}
+	return &sqliteReadTx{c.SQLiteConn}, nil
+}
+
+// sqliteReadTx — транзакция, начатая BeginTx с ReadOnly.
+type sqliteReadTx struct {
+	conn *sqlite3.SQLiteConn
+}
+
+func (tx *sqliteReadTx) Commit() error {
+	_, err := tx.conn.ExecContext(context.Background(), "COMMIT", nil)
+	return err
+}
+
+func (tx *sqliteReadTx) Rollback() error {
+	_, err := tx.conn.ExecContext(context.Background(), "ROLLBACK", nil)
+	return err
+}
+
+var (
+	placeholderRe = regexp.MustCompile(`\$(\d+)`)
+	forUpdateRe   = regexp.MustCompile(`(?i)\s+FOR\s+UPDATE\b`)
+)
+
+// rewriteQuery переводит синтаксис PostgreSQL, общий для всех запросов сервиса,
+// в синтаксис SQLite (строковые литералы не затрагиваются):
+//   - $N -> ?N: в SQLite $N — именованный параметр, и номер ему назначается
+//     по порядку появления в запросе, а не по N;
+//   - FOR UPDATE убирается: транзакция и так держит блокировку всей базы.
+//
+// Остальные различия СУБД (LOCK TABLE, интервалы) обрабатываются в местах
+// использования через DialectOf.
+func rewriteQuery(query string) string {
+	var b strings.Builder
+	for query != "" {
+		i := strings.IndexAny(query, `'"`)
+		if i < 0 {
+			i = len(query)
+		}
+		code := placeholderRe.ReplaceAllString(query[:i], "?$1")
+		b.WriteString(forUpdateRe.ReplaceAllString(code, ""))
+		query = query[i:]
+		if query == "" {
+			break
+		}
+
+		end := strings.IndexByte(query[1:], query[0])
+		if end < 0 {
+			b.WriteString(query)
+			break
+		}
+		b.WriteString(query[:end+2])
+		query = query[end+2:]
+	}
+	return b.String()
+}
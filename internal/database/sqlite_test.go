@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewriteQuery(t *testing.T) {
+	cases := []struct {
+		name, query, want string
+	}{
+		{"placeholders", `SELECT 1 FROM users WHERE team_name = $2 AND user_id = $1`, `SELECT 1 FROM users WHERE team_name = ?2 AND user_id = ?1`},
+		{"for update", "SELECT user_id FROM users WHERE team_name = $1\n\t\tORDER BY user_id FOR UPDATE", "SELECT user_id FROM users WHERE team_name = ?1\n\t\tORDER BY user_id"},
+		{"literals untouched", `SELECT '$1 for update' FROM t WHERE a = $1 FOR UPDATE`, `SELECT '$1 for update' FROM t WHERE a = ?1`},
+		{"escape literal", `WHERE lower(username) LIKE $3 ESCAPE '\'`, `WHERE lower(username) LIKE ?3 ESCAPE '\'`},
+		{"unterminated literal", `SELECT 'abc`, `SELECT 'abc`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, rewriteQuery(tc.query))
+		})
+	}
+}
+
+func TestConnectSQLite(t *testing.T) {
+	db, err := ConnectSQLite(t.TempDir() + "/test.sqlite")
+	require.NoError(t, err)
+	defer db.Close()
+	assert.Equal(t, SQLite, DialectOf(db))
+
+	var lower string
+	require.NoError(t, db.QueryRow(`SELECT lower($1)`, "Алиса").Scan(&lower))
+	assert.Equal(t, "алиса", lower)
+}
+
+func TestSQLiteReadOnlyTx(t *testing.T) {
+	db, err := ConnectSQLite(t.TempDir() + "/test.sqlite")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE t (v INTEGER)`)
+	require.NoError(t, err)
+
+	// Пишущая транзакция держит блокировку записи до конца теста.
+	write, err := db.Begin()
+	require.NoError(t, err)
+	defer write.Rollback()
+	_, err = write.Exec(`INSERT INTO t (v) VALUES (1)`)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	read, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	require.NoError(t, err)
+	var n int
+	require.NoError(t, read.QueryRowContext(ctx, `SELECT COUNT(*) FROM t`).Scan(&n))
+	assert.Equal(t, 0, n)
+	require.NoError(t, read.Rollback())
+}
@@ -0,0 +1,71 @@
+// Package graphqlapi реализует GraphQL API для дашбордов поверх методов
+// сервиса. Схема — schema.graphql; связанные объекты загружаются пакетно
+// (см. loader).
+package graphqlapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"pull-request-api.com/internal/service"
+)
+
+const (
+	// maxDepth ограничивает вложенность запроса: пользователь → ревью → автор → ...
+	maxDepth         = 10
+	maxRequestBodyMB = 1
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// Handler обслуживает POST-запросы GraphQL.
+type Handler struct {
+	ser    *service.Service
+	schema *graphql.Schema
+}
+
+// NewHandler разбирает схему и проверяет, что резолверы ей соответствуют.
+func NewHandler(ser *service.Service) (*Handler, error) {
+	schema, err := graphql.ParseSchema(schemaSDL, &resolver{ser: ser},
+		graphql.MaxDepth(maxDepth),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{ser: ser, schema: schema}, nil
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req request
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodyMB<<20)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Query == "" {
+		http.Error(w, "Request body must be JSON with a non-empty query", http.StatusBadRequest)
+		return
+	}
+
+	// Загрузчики живут в пределах одного запроса, чтобы кэш не отдавал
+	// устаревшие данные и не смешивал данные разных клиентов.
+	ctx := withLoaders(r.Context(), newLoaders(h.ser))
+	resp := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,51 @@
+package graphqlapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	h, err := NewHandler(nil)
+	require.NoError(t, err, "resolvers must match schema.graphql")
+
+	req := httptest.NewRequest(http.MethodGet, "/graphql", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":""}`))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// Валидация first не доходит до базы.
+	req = httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"{ users(first: 0) { nodes { id } } }"}`))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp struct {
+		Errors []struct {
+			Message    string         `json:"message"`
+			Extensions map[string]any `json:"extensions"`
+		} `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "VALIDATION_ERROR", resp.Errors[0].Extensions["code"])
+
+	// Слишком глубокий запрос отклоняется до выполнения.
+	deep := `{"query":"{ user(id: \"u1\") { reviews { author { reviews { author { reviews { author { reviews { author { reviews { id } } } } } } } } } } }"}`
+	req = httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(deep))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.NotEmpty(t, resp.Errors)
+	assert.Contains(t, resp.Errors[0].Message, "depth")
+}
@@ -0,0 +1,119 @@
+package graphqlapi
+
+import (
+	"context"
+	"sync"
+
+	"pull-request-api.com/internal/models"
+	"pull-request-api.com/internal/service"
+)
+
+// loader пакетно загружает объекты по ключам в пределах одного запроса.
+// Резолвер списка регистрирует ключи элементов через prime, и первый load
+// любого из них загружает все зарегистрированные ключи одним вызовом fetch;
+// остальные элементы берут результат из кэша.
+type loader[V any] struct {
+	fetch func(ctx context.Context, keys []string) (map[string]V, error)
+
+	mu      sync.Mutex
+	pending []string
+	queued  map[string]bool
+	loaded  map[string]V
+	found   map[string]bool
+}
+
+func newLoader[V any](fetch func(ctx context.Context, keys []string) (map[string]V, error)) *loader[V] {
+	return &loader[V]{fetch: fetch, queued: map[string]bool{}, loaded: map[string]V{}, found: map[string]bool{}}
+}
+
+func (l *loader[V]) prime(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, k := range keys {
+		if !l.queued[k] {
+			l.queued[k] = true
+			l.pending = append(l.pending, k)
+		}
+	}
+}
+
+// load возвращает объект по ключу; ok=false, если его нет.
+func (l *loader[V]) load(ctx context.Context, key string) (V, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	if len(l.pending) > 0 && !l.found[key] {
+		keys := l.pending
+		values, err := l.fetch(ctx, keys)
+		if err != nil {
+			var zero V
+			return zero, false, err
+		}
+		l.pending = nil
+		for _, k := range keys {
+			if v, ok := values[k]; ok {
+				l.loaded[k], l.found[k] = v, true
+			}
+		}
+	}
+	v, ok := l.loaded[key]
+	return v, ok, nil
+}
+
+// loaders — загрузчики одного запроса GraphQL.
+type loaders struct {
+	users   *loader[*models.User]
+	prs     *loader[*models.PullRequest]
+	reviews *loader[[]models.PullRequestShort]
+}
+
+// newLoaders создаёт загрузчики; загруженные PR и ревью регистрируют
+// связанные ключи в следующих загрузчиках, чтобы следующий уровень
+// вложенности тоже загрузился одним запросом. Загрузчик держит блокировку,
+// пока выполняет fetch, поэтому зависимости идут в одну сторону:
+// reviews → prs → users.
+func newLoaders(ser *service.Service) *loaders {
+	l := &loaders{users: newLoader(ser.GetUsersByIDs)}
+	l.prs = newLoader(func(ctx context.Context, keys []string) (map[string]*models.PullRequest, error) {
+		prs, err := ser.GetPullRequestsByIDs(ctx, keys)
+		if err != nil {
+			return nil, err
+		}
+		for _, pr := range prs {
+			l.users.prime(pr.AuthorId)
+			l.users.prime(pr.AssignedReviewers...)
+		}
+		return prs, nil
+	})
+	l.reviews = newLoader(func(ctx context.Context, keys []string) (map[string][]models.PullRequestShort, error) {
+		reviews, err := ser.GetReviewsByUsers(ctx, keys)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			// У пользователя без ревью пустой список, а не «не найдено».
+			if _, ok := reviews[k]; !ok {
+				reviews[k] = nil
+			}
+			for _, pr := range reviews[k] {
+				l.prs.prime(pr.PullRequestId)
+			}
+		}
+		return reviews, nil
+	})
+	return l
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
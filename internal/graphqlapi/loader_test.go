@@ -0,0 +1,76 @@
+package graphqlapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader_Batches(t *testing.T) {
+	var calls [][]string
+	l := newLoader(func(_ context.Context, keys []string) (map[string]int, error) {
+		calls = append(calls, keys)
+		out := map[string]int{}
+		for _, k := range keys {
+			if k != "missing" {
+				out[k] = len(k)
+			}
+		}
+		return out, nil
+	})
+	ctx := context.Background()
+
+	l.prime("a", "bb", "a", "missing")
+	var wg sync.WaitGroup
+	for _, k := range []string{"a", "bb", "missing"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := l.load(ctx, k)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	require.Len(t, calls, 1)
+	assert.Equal(t, []string{"a", "bb", "missing"}, calls[0])
+
+	v, ok, err := l.load(ctx, "bb")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+	_, ok, err = l.load(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Len(t, calls, 1)
+
+	// Незарегистрированный ключ загружается вместе с новыми зарегистрированными.
+	l.prime("ccc")
+	v, ok, err = l.load(ctx, "dddd")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 4, v)
+	require.Len(t, calls, 2)
+	assert.Equal(t, []string{"ccc", "dddd"}, calls[1])
+}
+
+func TestLoader_RetriesAfterError(t *testing.T) {
+	fail := true
+	l := newLoader(func(_ context.Context, keys []string) (map[string]int, error) {
+		if fail {
+			return nil, errors.New("db down")
+		}
+		return map[string]int{"a": 1}, nil
+	})
+
+	_, _, err := l.load(context.Background(), "a")
+	require.Error(t, err)
+	fail = false
+	v, ok, err := l.load(context.Background(), "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+}
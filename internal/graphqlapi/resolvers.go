@@ -0,0 +1,617 @@
+package graphqlapi
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/graph-gophers/graphql-go"
+
+	"pull-request-api.com/internal/models"
+	"pull-request-api.com/internal/service"
+)
+
+// maxPageSize — наибольшее значение first в списках.
+const maxPageSize = 200
+
+// resolver — корневой резолвер запросов и мутаций.
+type resolver struct {
+	ser *service.Service
+}
+
+// --- Query ---
+
+func (r *resolver) Team(ctx context.Context, args struct{ Name string }) (*teamResolver, error) {
+	team, err := r.ser.GetTeam(ctx, args.Name)
+	if errors.Is(err, service.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, queryError(err)
+	}
+	return r.newTeam(ctx, *team), nil
+}
+
+func (r *resolver) Teams(ctx context.Context, args struct {
+	First int32
+	After *string
+}) (*teamConnection, error) {
+	after, err := pageArgs(args.First, args.After)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := r.ser.ListTeams(ctx)
+	if err != nil {
+		return nil, queryError(err)
+	}
+	// ListTeams упорядочен по имени.
+	start := sort.Search(len(teams), func(i int) bool { return teams[i].TeamName > after })
+	teams = teams[start:]
+
+	conn := &teamConnection{}
+	if len(teams) > int(args.First) {
+		teams = teams[:args.First]
+		conn.page = nextPage(teams[len(teams)-1].TeamName)
+	}
+	for _, t := range teams {
+		conn.nodes = append(conn.nodes, r.newTeam(ctx, t))
+	}
+	return conn, nil
+}
+
+func (r *resolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	user, ok, err := loadersFrom(ctx).users.load(ctx, string(args.ID))
+	if err != nil {
+		return nil, queryError(err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	return loadedUser(ctx, user), nil
+}
+
+func (r *resolver) Users(ctx context.Context, args struct {
+	TeamName   *string
+	IsActive   *bool
+	NamePrefix *string
+	First      int32
+	After      *string
+}) (*userConnection, error) {
+	after, err := pageArgs(args.First, args.After)
+	if err != nil {
+		return nil, err
+	}
+	limit := int(args.First)
+	params := models.GetUsersListParams{TeamName: args.TeamName, IsActive: args.IsActive, NamePrefix: args.NamePrefix, Limit: &limit}
+	if after != "" {
+		params.Cursor = &after
+	}
+	list, err := r.ser.ListUsers(ctx, params)
+	if err != nil {
+		return nil, queryError(err)
+	}
+
+	conn := &userConnection{}
+	for i := range list.Users {
+		conn.nodes = append(conn.nodes, loadedUser(ctx, &list.Users[i]))
+	}
+	if list.NextCursor != nil {
+		conn.page = nextPage(*list.NextCursor)
+	}
+	return conn, nil
+}
+
+func (r *resolver) PullRequest(ctx context.Context, args struct{ ID graphql.ID }) (*pullRequestResolver, error) {
+	pr, ok, err := loadersFrom(ctx).prs.load(ctx, string(args.ID))
+	if err != nil {
+		return nil, queryError(err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	return newPullRequest(ctx, pr), nil
+}
+
+func (r *resolver) PullRequests(ctx context.Context, args struct {
+	TeamName        *string
+	IncludeSubteams bool
+	Status          *string
+	First           int32
+	After           *string
+}) (*pullRequestConnection, error) {
+	after, err := pageArgs(args.First, args.After)
+	if err != nil {
+		return nil, err
+	}
+	// Берём на одну запись больше, чтобы понять, есть ли следующая страница.
+	fetch := int(args.First) + 1
+	params := models.GetPullRequestListParams{TeamName: args.TeamName, IncludeSubteams: &args.IncludeSubteams, Limit: &fetch}
+	if args.Status != nil {
+		status := models.PullRequestStatus(*args.Status)
+		params.Status = &status
+	}
+	if after != "" {
+		params.Cursor = &after
+	}
+	ids, err := r.ser.ListPullRequestIDs(ctx, params)
+	if err != nil {
+		return nil, queryError(err)
+	}
+
+	conn := &pullRequestConnection{}
+	if len(ids) > int(args.First) {
+		ids = ids[:args.First]
+		conn.page = nextPage(ids[len(ids)-1])
+	}
+	conn.nodes = newPullRequests(ctx, ids)
+	return conn, nil
+}
+
+func (r *resolver) AssignmentStats(ctx context.Context, args struct {
+	TeamName        *string
+	IncludeSubteams bool
+}) ([]*statsResolver, error) {
+	stats, err := r.ser.GetAssignmentStats(ctx, args.TeamName, args.IncludeSubteams)
+	if err != nil {
+		return nil, queryError(err)
+	}
+	return newStats(ctx, stats), nil
+}
+
+// --- Mutation ---
+
+type teamInput struct {
+	Name    string
+	Members []struct {
+		UserID   graphql.ID
+		Username string
+		IsActive bool
+	}
+}
+
+func (r *resolver) AddTeam(ctx context.Context, args struct{ Input teamInput }) (*teamResolver, error) {
+	team := models.Team{TeamName: args.Input.Name, Members: []models.TeamMember{}}
+	for _, m := range args.Input.Members {
+		team.Members = append(team.Members, models.TeamMember{UserId: string(m.UserID), Username: m.Username, IsActive: m.IsActive})
+	}
+	if err := r.ser.AddTeam(ctx, team); err != nil {
+		return nil, queryError(err)
+	}
+	added, err := r.ser.GetTeam(ctx, team.TeamName)
+	if err != nil {
+		return nil, queryError(err)
+	}
+	return r.newTeam(ctx, *added), nil
+}
+
+func (r *resolver) RenameTeam(ctx context.Context, args struct{ Name, NewName string }) (*teamResolver, error) {
+	team, err := r.ser.RenameTeam(ctx, models.PostTeamRenameJSONRequestBody{TeamName: args.Name, NewTeamName: args.NewName})
+	if errors.Is(err, service.ErrConflict) {
+		return nil, &apiError{code: models.TEAMEXISTS, message: "Team with newName already exists"}
+	} else if err != nil {
+		return nil, queryError(err)
+	}
+	return r.newTeam(ctx, *team), nil
+}
+
+func (r *resolver) SetUserActive(ctx context.Context, args struct {
+	UserID   graphql.ID
+	IsActive bool
+}) (*userResolver, error) {
+	user, err := r.ser.SetUserActive(ctx, models.PostUsersSetIsActiveJSONRequestBody{UserId: string(args.UserID), IsActive: args.IsActive})
+	if err != nil {
+		return nil, queryError(err)
+	}
+	return loadedUser(ctx, user), nil
+}
+
+func (r *resolver) CreatePullRequest(ctx context.Context, args struct {
+	Input struct {
+		ID       graphql.ID
+		Name     string
+		AuthorID graphql.ID
+		TeamName *string
+	}
+}) (*pullRequestResolver, error) {
+	pr, err := r.ser.CreatePullRequest(ctx, models.PostPullRequestCreateJSONRequestBody{
+		PullRequestId:   string(args.Input.ID),
+		PullRequestName: args.Input.Name,
+		AuthorId:        string(args.Input.AuthorID),
+		TeamName:        args.Input.TeamName,
+	})
+	if errors.Is(err, service.ErrConflict) {
+		return nil, &apiError{code: models.PREXISTS, message: "PR id already exists"}
+	} else if err != nil {
+		return nil, queryError(err)
+	}
+	return newPullRequest(ctx, pr), nil
+}
+
+func (r *resolver) MergePullRequest(ctx context.Context, args struct{ ID graphql.ID }) (*pullRequestResolver, error) {
+	pr, err := r.ser.MergePullRequest(ctx, string(args.ID))
+	if err != nil {
+		return nil, queryError(err)
+	}
+	return newPullRequest(ctx, pr), nil
+}
+
+func (r *resolver) ReassignReviewer(ctx context.Context, args struct{ PullRequestID, OldUserID graphql.ID }) (*pullRequestResolver, error) {
+	pr, err := r.ser.ReassignReviewer(ctx, models.PostPullRequestReassignJSONRequestBody{
+		PullRequestId: string(args.PullRequestID),
+		OldUserId:     string(args.OldUserID),
+	})
+	switch {
+	case errors.Is(err, service.ErrPrecondition):
+		return nil, &apiError{code: models.PRMERGED, message: "PR is merged"}
+	case errors.Is(err, service.ErrInvalidInput):
+		return nil, &apiError{code: models.NOTASSIGNED, message: "User not assigned"}
+	case errors.Is(err, service.ErrConflict):
+		return nil, &apiError{code: models.NOCANDIDATE, message: "No candidate available"}
+	case err != nil:
+		return nil, queryError(err)
+	}
+	return newPullRequest(ctx, pr), nil
+}
+
+// --- Team ---
+
+type teamResolver struct {
+	ser  *service.Service
+	team models.Team
+}
+
+func (r *resolver) newTeam(ctx context.Context, team models.Team) *teamResolver {
+	ids := make([]string, len(team.Members))
+	for i, m := range team.Members {
+		ids[i] = m.UserId
+	}
+	loadersFrom(ctx).users.prime(ids...)
+	loadersFrom(ctx).reviews.prime(ids...)
+	return &teamResolver{ser: r.ser, team: team}
+}
+
+func (t *teamResolver) Name() string {
+	return t.team.TeamName
+}
+
+func (t *teamResolver) ParentTeam() *string {
+	return t.team.ParentTeam
+}
+
+func (t *teamResolver) Members(ctx context.Context, args struct{ IsActive *bool }) []*userResolver {
+	var ids []string
+	for _, m := range t.team.Members {
+		if args.IsActive == nil || m.IsActive == *args.IsActive {
+			ids = append(ids, m.UserId)
+		}
+	}
+	return newUsers(ctx, ids)
+}
+
+func (t *teamResolver) Stats(ctx context.Context, args struct{ IncludeSubteams bool }) ([]*statsResolver, error) {
+	stats, err := t.ser.GetAssignmentStats(ctx, &t.team.TeamName, args.IncludeSubteams)
+	if err != nil {
+		return nil, queryError(err)
+	}
+	return newStats(ctx, stats), nil
+}
+
+// --- User ---
+
+type userResolver struct {
+	id   string
+	user *models.User
+}
+
+// newUsers создаёт резолверы пользователей и регистрирует их в загрузчиках,
+// чтобы поля и ревью всех пользователей списка загрузились одним запросом.
+func newUsers(ctx context.Context, ids []string) []*userResolver {
+	l := loadersFrom(ctx)
+	l.users.prime(ids...)
+	l.reviews.prime(ids...)
+	users := make([]*userResolver, len(ids))
+	for i, id := range ids {
+		users[i] = &userResolver{id: id}
+	}
+	return users
+}
+
+// loadedUser создаёт резолвер уже загруженного пользователя; в загрузчике
+// регистрируются только его ревью.
+func loadedUser(ctx context.Context, user *models.User) *userResolver {
+	loadersFrom(ctx).reviews.prime(user.UserId)
+	return &userResolver{id: user.UserId, user: user}
+}
+
+func (u *userResolver) load(ctx context.Context) (*models.User, error) {
+	if u.user != nil {
+		return u.user, nil
+	}
+	user, ok, err := loadersFrom(ctx).users.load(ctx, u.id)
+	if err != nil {
+		return nil, queryError(err)
+	}
+	if !ok {
+		return nil, queryError(fmt.Errorf("user %q: %w", u.id, service.ErrNotFound))
+	}
+	u.user = user
+	return user, nil
+}
+
+func (u *userResolver) ID() graphql.ID {
+	return graphql.ID(u.id)
+}
+
+func (u *userResolver) Username(ctx context.Context) (string, error) {
+	user, err := u.load(ctx)
+	if err != nil {
+		return "", err
+	}
+	return user.Username, nil
+}
+
+func (u *userResolver) IsActive(ctx context.Context) (bool, error) {
+	user, err := u.load(ctx)
+	if err != nil {
+		return false, err
+	}
+	return user.IsActive, nil
+}
+
+func (u *userResolver) TeamName(ctx context.Context) (*string, error) {
+	user, err := u.load(ctx)
+	if err != nil || user.TeamName == "" {
+		return nil, err
+	}
+	return &user.TeamName, nil
+}
+
+func (u *userResolver) Teams(ctx context.Context) ([]string, error) {
+	user, err := u.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return user.Teams, nil
+}
+
+func (u *userResolver) Reviews(ctx context.Context, args struct{ Status *string }) ([]*pullRequestResolver, error) {
+	reviews, _, err := loadersFrom(ctx).reviews.load(ctx, u.id)
+	if err != nil {
+		return nil, queryError(err)
+	}
+	var ids []string
+	for _, pr := range reviews {
+		if args.Status == nil || string(pr.Status) == *args.Status {
+			ids = append(ids, pr.PullRequestId)
+		}
+	}
+	return newPullRequests(ctx, ids), nil
+}
+
+// --- PullRequest ---
+
+type pullRequestResolver struct {
+	id string
+	pr *models.PullRequest
+}
+
+// newPullRequests — как newUsers для PR.
+func newPullRequests(ctx context.Context, ids []string) []*pullRequestResolver {
+	loadersFrom(ctx).prs.prime(ids...)
+	prs := make([]*pullRequestResolver, len(ids))
+	for i, id := range ids {
+		prs[i] = &pullRequestResolver{id: id}
+	}
+	return prs
+}
+
+func newPullRequest(ctx context.Context, pr *models.PullRequest) *pullRequestResolver {
+	l := loadersFrom(ctx)
+	ids := append([]string{pr.AuthorId}, pr.AssignedReviewers...)
+	l.users.prime(ids...)
+	l.reviews.prime(ids...)
+	return &pullRequestResolver{id: pr.PullRequestId, pr: pr}
+}
+
+func (p *pullRequestResolver) load(ctx context.Context) (*models.PullRequest, error) {
+	if p.pr != nil {
+		return p.pr, nil
+	}
+	pr, ok, err := loadersFrom(ctx).prs.load(ctx, p.id)
+	if err != nil {
+		return nil, queryError(err)
+	}
+	if !ok {
+		return nil, queryError(fmt.Errorf("pull request %q: %w", p.id, service.ErrNotFound))
+	}
+	p.pr = pr
+	return pr, nil
+}
+
+func (p *pullRequestResolver) ID() graphql.ID {
+	return graphql.ID(p.id)
+}
+
+func (p *pullRequestResolver) Name(ctx context.Context) (string, error) {
+	pr, err := p.load(ctx)
+	if err != nil {
+		return "", err
+	}
+	return pr.PullRequestName, nil
+}
+
+func (p *pullRequestResolver) Status(ctx context.Context) (string, error) {
+	pr, err := p.load(ctx)
+	if err != nil {
+		return "", err
+	}
+	return string(pr.Status), nil
+}
+
+func (p *pullRequestResolver) Author(ctx context.Context) (*userResolver, error) {
+	pr, err := p.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newUsers(ctx, []string{pr.AuthorId})[0], nil
+}
+
+func (p *pullRequestResolver) Reviewers(ctx context.Context) ([]*userResolver, error) {
+	pr, err := p.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newUsers(ctx, pr.AssignedReviewers), nil
+}
+
+func (p *pullRequestResolver) TeamName(ctx context.Context) (*string, error) {
+	pr, err := p.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pr.TeamName, nil
+}
+
+func (p *pullRequestResolver) CreatedAt(ctx context.Context) (*graphql.Time, error) {
+	pr, err := p.load(ctx)
+	if err != nil || pr.CreatedAt == nil {
+		return nil, err
+	}
+	return &graphql.Time{Time: *pr.CreatedAt}, nil
+}
+
+func (p *pullRequestResolver) MergedAt(ctx context.Context) (*graphql.Time, error) {
+	pr, err := p.load(ctx)
+	if err != nil || pr.MergedAt == nil {
+		return nil, err
+	}
+	return &graphql.Time{Time: *pr.MergedAt}, nil
+}
+
+// --- AssignmentStats ---
+
+type statsResolver struct {
+	user  *userResolver
+	count int
+}
+
+func newStats(ctx context.Context, stats []models.AssignmentStats) []*statsResolver {
+	ids := make([]string, len(stats))
+	for i, st := range stats {
+		ids[i] = st.UserId
+	}
+	users := newUsers(ctx, ids)
+	out := make([]*statsResolver, len(stats))
+	for i, st := range stats {
+		out[i] = &statsResolver{user: users[i], count: st.Count}
+	}
+	return out
+}
+
+func (s *statsResolver) User() *userResolver {
+	return s.user
+}
+
+func (s *statsResolver) Count() int32 {
+	return int32(s.count)
+}
+
+// --- Pagination ---
+
+type pageInfo struct {
+	endCursor *string
+}
+
+func nextPage(last string) pageInfo {
+	cursor := base64.RawURLEncoding.EncodeToString([]byte(last))
+	return pageInfo{endCursor: &cursor}
+}
+
+func (p pageInfo) EndCursor() *string {
+	return p.endCursor
+}
+
+func (p pageInfo) HasNextPage() bool {
+	return p.endCursor != nil
+}
+
+type teamConnection struct {
+	nodes []*teamResolver
+	page  pageInfo
+}
+
+func (c *teamConnection) Nodes() []*teamResolver { return c.nodes }
+func (c *teamConnection) PageInfo() pageInfo     { return c.page }
+
+type userConnection struct {
+	nodes []*userResolver
+	page  pageInfo
+}
+
+func (c *userConnection) Nodes() []*userResolver { return c.nodes }
+func (c *userConnection) PageInfo() pageInfo     { return c.page }
+
+type pullRequestConnection struct {
+	nodes []*pullRequestResolver
+	page  pageInfo
+}
+
+func (c *pullRequestConnection) Nodes() []*pullRequestResolver { return c.nodes }
+func (c *pullRequestConnection) PageInfo() pageInfo            { return c.page }
+
+// pageArgs проверяет first и раскодирует after: курсор — последний ключ
+// предыдущей страницы (имя команды, user_id или id PR).
+func pageArgs(first int32, after *string) (string, error) {
+	if first < 1 || first > maxPageSize {
+		return "", &apiError{code: models.VALIDATIONERROR, message: fmt.Sprintf("first must be between 1 and %d", maxPageSize)}
+	}
+	if after == nil {
+		return "", nil
+	}
+	key, err := base64.RawURLEncoding.DecodeString(*after)
+	if err != nil {
+		return "", &apiError{code: models.VALIDATIONERROR, message: "invalid after cursor"}
+	}
+	return string(key), nil
+}
+
+// --- Errors ---
+
+// apiError — ошибка резолвера с кодом из ErrorResponse REST API в extensions.code.
+type apiError struct {
+	code    models.ErrorResponseErrorCode
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func (e *apiError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": string(e.code)}
+}
+
+// queryError переводит ошибки сервиса в коды так же, как handleServiceError
+// в REST API; внутренние ошибки не раскрываются.
+func queryError(err error) error {
+	var ae *apiError
+	switch {
+	case errors.As(err, &ae):
+		return ae
+	case errors.Is(err, service.ErrNotFound):
+		return &apiError{code: models.NOTFOUND, message: "Not found"}
+	case errors.Is(err, service.ErrConflict):
+		return &apiError{code: models.PREXISTS, message: "Already exists"}
+	case errors.Is(err, service.ErrInvalidInput):
+		return &apiError{code: models.VALIDATIONERROR, message: err.Error()}
+	case errors.Is(err, service.ErrPrecondition):
+		return &apiError{code: models.PRMERGED, message: err.Error()}
+	case errors.Is(err, service.ErrNoCandidate):
+		return &apiError{code: models.NOCANDIDATE, message: "No candidate available"}
+	default:
+		slog.Error("GraphQL request failed", "error", err)
+		return &apiError{code: models.INTERNALERROR, message: "Internal Server Error"}
+	}
+}
@@ -0,0 +1,79 @@
+package grpcapi
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"pull-request-api.com/internal/models"
+	reviewerv1 "pull-request-api.com/proto/reviewer/v1"
+)
+
+func teamFromProto(t *reviewerv1.Team) models.Team {
+	team := models.Team{TeamName: t.TeamName, Members: []models.TeamMember{}}
+	if t.ParentTeam != "" {
+		team.ParentTeam = &t.ParentTeam
+	}
+	for _, m := range t.Members {
+		team.Members = append(team.Members, models.TeamMember{UserId: m.UserId, Username: m.Username, IsActive: m.IsActive})
+	}
+	return team
+}
+
+func teamToProto(t *models.Team) *reviewerv1.Team {
+	team := &reviewerv1.Team{TeamName: t.TeamName}
+	if t.ParentTeam != nil {
+		team.ParentTeam = *t.ParentTeam
+	}
+	for _, m := range t.Members {
+		team.Members = append(team.Members, &reviewerv1.TeamMember{UserId: m.UserId, Username: m.Username, IsActive: m.IsActive})
+	}
+	return team
+}
+
+func userToProto(u *models.User) *reviewerv1.User {
+	return &reviewerv1.User{
+		UserId:   u.UserId,
+		Username: u.Username,
+		TeamName: u.TeamName,
+		IsActive: u.IsActive,
+		Teams:    u.Teams,
+	}
+}
+
+func statusToProto(s string) reviewerv1.PullRequestStatus {
+	switch s {
+	case string(models.PullRequestStatusOPEN):
+		return reviewerv1.PullRequestStatus_PULL_REQUEST_STATUS_OPEN
+	case string(models.PullRequestStatusMERGED):
+		return reviewerv1.PullRequestStatus_PULL_REQUEST_STATUS_MERGED
+	}
+	return reviewerv1.PullRequestStatus_PULL_REQUEST_STATUS_UNSPECIFIED
+}
+
+func pullRequestToProto(pr *models.PullRequest) *reviewerv1.PullRequest {
+	out := &reviewerv1.PullRequest{
+		PullRequestId:     pr.PullRequestId,
+		PullRequestName:   pr.PullRequestName,
+		AuthorId:          pr.AuthorId,
+		Status:            statusToProto(string(pr.Status)),
+		AssignedReviewers: pr.AssignedReviewers,
+	}
+	if pr.TeamName != nil {
+		out.TeamName = *pr.TeamName
+	}
+	if pr.CreatedAt != nil {
+		out.CreatedAt = timestamppb.New(*pr.CreatedAt)
+	}
+	if pr.MergedAt != nil {
+		out.MergedAt = timestamppb.New(*pr.MergedAt)
+	}
+	return out
+}
+
+func pullRequestShortToProto(pr *models.PullRequestShort) *reviewerv1.PullRequestShort {
+	return &reviewerv1.PullRequestShort{
+		PullRequestId:   pr.PullRequestId,
+		PullRequestName: pr.PullRequestName,
+		AuthorId:        pr.AuthorId,
+		Status:          statusToProto(string(pr.Status)),
+	}
+}
@@ -0,0 +1,227 @@
+// Package grpcapi — gRPC API (proto/reviewer/v1) поверх того же service.Service,
+// что и REST API.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"pull-request-api.com/internal/models"
+	"pull-request-api.com/internal/notify"
+	"pull-request-api.com/internal/service"
+	reviewerv1 "pull-request-api.com/proto/reviewer/v1"
+)
+
+// Server реализует reviewerv1.ReviewerServiceServer.
+type Server struct {
+	reviewerv1.UnimplementedReviewerServiceServer
+
+	ser    *service.Service
+	events *notify.Hub
+}
+
+func NewServer(ser *service.Service) *Server {
+	return &Server{ser: ser}
+}
+
+// WithEventHub включает мгновенную доставку изменений в StreamUserReviews
+// с watch: очередь перечитывается по сигналу hub, а не по таймеру.
+func (s *Server) WithEventHub(hub *notify.Hub) *Server {
+	s.events = hub
+	return s
+}
+
+func (s *Server) AddTeam(ctx context.Context, req *reviewerv1.AddTeamRequest) (*reviewerv1.Team, error) {
+	if req.GetTeam().GetTeamName() == "" {
+		return nil, invalidArgument("team.team_name")
+	}
+	team := teamFromProto(req.Team)
+	for _, m := range team.Members {
+		if m.UserId == "" {
+			return nil, invalidArgument("team.members.user_id")
+		}
+	}
+	if err := s.ser.AddTeam(ctx, team); err != nil {
+		return nil, serviceError(err)
+	}
+	return teamToProto(&team), nil
+}
+
+func (s *Server) GetTeam(ctx context.Context, req *reviewerv1.GetTeamRequest) (*reviewerv1.Team, error) {
+	if req.TeamName == "" {
+		return nil, invalidArgument("team_name")
+	}
+	team, err := s.ser.GetTeam(ctx, req.TeamName)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return teamToProto(team), nil
+}
+
+func (s *Server) RenameTeam(ctx context.Context, req *reviewerv1.RenameTeamRequest) (*reviewerv1.Team, error) {
+	if req.TeamName == "" {
+		return nil, invalidArgument("team_name")
+	}
+	if req.NewTeamName == "" {
+		return nil, invalidArgument("new_team_name")
+	}
+	team, err := s.ser.RenameTeam(ctx, models.PostTeamRenameJSONRequestBody{TeamName: req.TeamName, NewTeamName: req.NewTeamName})
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return teamToProto(team), nil
+}
+
+func (s *Server) GetUser(ctx context.Context, req *reviewerv1.GetUserRequest) (*reviewerv1.User, error) {
+	if req.UserId == "" {
+		return nil, invalidArgument("user_id")
+	}
+	user, err := s.ser.GetUser(ctx, req.UserId)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return userToProto(user), nil
+}
+
+func (s *Server) SetUserActive(ctx context.Context, req *reviewerv1.SetUserActiveRequest) (*reviewerv1.User, error) {
+	if req.UserId == "" {
+		return nil, invalidArgument("user_id")
+	}
+	user, err := s.ser.SetUserActive(ctx, models.PostUsersSetIsActiveJSONRequestBody{UserId: req.UserId, IsActive: req.IsActive})
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return userToProto(user), nil
+}
+
+func (s *Server) CreatePullRequest(ctx context.Context, req *reviewerv1.CreatePullRequestRequest) (*reviewerv1.PullRequest, error) {
+	switch {
+	case req.PullRequestId == "":
+		return nil, invalidArgument("pull_request_id")
+	case req.PullRequestName == "":
+		return nil, invalidArgument("pull_request_name")
+	case req.AuthorId == "":
+		return nil, invalidArgument("author_id")
+	}
+	body := models.PostPullRequestCreateJSONRequestBody{
+		PullRequestId:   req.PullRequestId,
+		PullRequestName: req.PullRequestName,
+		AuthorId:        req.AuthorId,
+	}
+	if req.TeamName != "" {
+		body.TeamName = &req.TeamName
+	}
+	pr, err := s.ser.CreatePullRequest(ctx, body)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return pullRequestToProto(pr), nil
+}
+
+func (s *Server) GetPullRequest(ctx context.Context, req *reviewerv1.GetPullRequestRequest) (*reviewerv1.PullRequest, error) {
+	if req.PullRequestId == "" {
+		return nil, invalidArgument("pull_request_id")
+	}
+	pr, err := s.ser.GetPullRequest(ctx, req.PullRequestId)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return pullRequestToProto(pr), nil
+}
+
+func (s *Server) MergePullRequest(ctx context.Context, req *reviewerv1.MergePullRequestRequest) (*reviewerv1.PullRequest, error) {
+	if req.PullRequestId == "" {
+		return nil, invalidArgument("pull_request_id")
+	}
+	pr, err := s.ser.MergePullRequest(ctx, req.PullRequestId)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	return pullRequestToProto(pr), nil
+}
+
+func (s *Server) ReassignReviewer(ctx context.Context, req *reviewerv1.ReassignReviewerRequest) (*reviewerv1.PullRequest, error) {
+	if req.PullRequestId == "" {
+		return nil, invalidArgument("pull_request_id")
+	}
+	if req.OldUserId == "" {
+		return nil, invalidArgument("old_user_id")
+	}
+	pr, err := s.ser.ReassignReviewer(ctx, models.PostPullRequestReassignJSONRequestBody{PullRequestId: req.PullRequestId, OldUserId: req.OldUserId})
+	// Ошибки переназначения — состояние PR, а не некорректный запрос (как и в REST,
+	// где им соответствуют коды PR_MERGED, NOT_ASSIGNED и NO_CANDIDATE).
+	switch {
+	case errors.Is(err, service.ErrPrecondition):
+		return nil, status.Error(codes.FailedPrecondition, "PR is merged")
+	case errors.Is(err, service.ErrInvalidInput):
+		return nil, status.Error(codes.FailedPrecondition, "User not assigned")
+	case errors.Is(err, service.ErrConflict):
+		return nil, status.Error(codes.FailedPrecondition, "No candidate available")
+	case err != nil:
+		return nil, serviceError(err)
+	}
+	return pullRequestToProto(pr), nil
+}
+
+func (s *Server) GetUserReviews(ctx context.Context, req *reviewerv1.GetUserReviewsRequest) (*reviewerv1.GetUserReviewsResponse, error) {
+	if req.UserId == "" {
+		return nil, invalidArgument("user_id")
+	}
+	prs, err := s.ser.GetUsersReviews(ctx, req.UserId)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	resp := &reviewerv1.GetUserReviewsResponse{UserId: req.UserId}
+	for i := range prs {
+		resp.PullRequests = append(resp.PullRequests, pullRequestShortToProto(&prs[i]))
+	}
+	return resp, nil
+}
+
+func (s *Server) GetAssignmentStats(ctx context.Context, req *reviewerv1.GetAssignmentStatsRequest) (*reviewerv1.GetAssignmentStatsResponse, error) {
+	var teamName *string
+	if req.TeamName != "" {
+		teamName = &req.TeamName
+	}
+	stats, err := s.ser.GetAssignmentStats(ctx, teamName, req.IncludeSubteams)
+	if err != nil {
+		return nil, serviceError(err)
+	}
+	resp := &reviewerv1.GetAssignmentStatsResponse{}
+	for _, st := range stats {
+		resp.Stats = append(resp.Stats, &reviewerv1.AssignmentStats{UserId: st.UserId, Count: int64(st.Count)})
+	}
+	return resp, nil
+}
+
+func invalidArgument(field string) error {
+	return status.Errorf(codes.InvalidArgument, "%s is required", field)
+}
+
+// serviceError переводит ошибки сервиса в статусы gRPC так же, как
+// handleServiceError в REST API переводит их в HTTP-статусы.
+func serviceError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return status.Error(codes.NotFound, "Not found")
+	case errors.Is(err, service.ErrConflict):
+		return status.Error(codes.AlreadyExists, "Already exists")
+	case errors.Is(err, service.ErrInvalidInput):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrPrecondition):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrNoCandidate):
+		return status.Error(codes.FailedPrecondition, "No candidate available")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		slog.Error("Request failed", "error", err)
+		return status.Error(codes.Internal, "Internal Server Error")
+	}
+}
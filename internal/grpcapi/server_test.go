@@ -0,0 +1,50 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"pull-request-api.com/internal/service"
+	reviewerv1 "pull-request-api.com/proto/reviewer/v1"
+)
+
+func TestServiceError(t *testing.T) {
+	tests := []struct {
+		err  error
+		code codes.Code
+	}{
+		{service.ErrNotFound, codes.NotFound},
+		{service.ErrConflict, codes.AlreadyExists},
+		{fmt.Errorf("%w: author has no team", service.ErrInvalidInput), codes.InvalidArgument},
+		{service.ErrPrecondition, codes.FailedPrecondition},
+		{context.Canceled, codes.Canceled},
+		{fmt.Errorf("connection refused"), codes.Internal},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.code, status.Code(serviceError(tt.err)), tt.err.Error())
+	}
+	assert.Equal(t, "Internal Server Error", status.Convert(serviceError(fmt.Errorf("secret"))).Message())
+}
+
+// Сервис не нужен: запросы без обязательных полей до него не доходят.
+func TestServer_RequiredFields(t *testing.T) {
+	s := NewServer(nil)
+	ctx := context.Background()
+
+	_, err := s.CreatePullRequest(ctx, &reviewerv1.CreatePullRequestRequest{PullRequestId: "pr-1", PullRequestName: "x"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, err.Error(), "author_id")
+
+	_, err = s.AddTeam(ctx, &reviewerv1.AddTeamRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = s.ReassignReviewer(ctx, &reviewerv1.ReassignReviewerRequest{PullRequestId: "pr-1"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	err = s.StreamUserReviews(&reviewerv1.StreamUserReviewsRequest{}, nil)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,122 @@
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	"pull-request-api.com/internal/models"
+	"pull-request-api.com/internal/service"
+	reviewerv1 "pull-request-api.com/proto/reviewer/v1"
+)
+
+// Без Hub очередь перечитывается с этим интервалом, с Hub — по сигналу.
+const watchPollInterval = time.Second
+
+// StreamUserReviews отправляет текущую очередь ревью пользователя, а с watch
+// продолжает отправлять её изменения по журналу событий: назначение
+// ревьювером, снятие и мёрж PR из очереди.
+func (s *Server) StreamUserReviews(req *reviewerv1.StreamUserReviewsRequest, stream reviewerv1.ReviewerService_StreamUserReviewsServer) error {
+	if req.UserId == "" {
+		return invalidArgument("user_id")
+	}
+	ctx := stream.Context()
+
+	// Курсор открывается до чтения очереди, чтобы не пропустить изменения
+	// между ними; изменение, уже попавшее в очередь, придёт повторно.
+	var (
+		cursor *service.EventCursor
+		wake   <-chan struct{}
+	)
+	if req.Watch {
+		if s.events != nil {
+			ch, cancel := s.events.Subscribe()
+			defer cancel()
+			wake = ch
+		}
+		var err error
+		if cursor, err = s.ser.NewEventCursor(ctx, service.EventFilter{UserID: req.UserId}, nil); err != nil {
+			return serviceError(err)
+		}
+	}
+
+	prs, err := s.ser.GetUsersReviews(ctx, req.UserId)
+	if err != nil {
+		return serviceError(err)
+	}
+	queue := map[string]bool{}
+	for i := range prs {
+		queue[prs[i].PullRequestId] = true
+		if err := stream.Send(&reviewerv1.ReviewQueueEntry{PullRequest: pullRequestShortToProto(&prs[i])}); err != nil {
+			return err
+		}
+	}
+	if !req.Watch {
+		return nil
+	}
+
+	for {
+		events, err := cursor.Next(ctx)
+		if err != nil {
+			return serviceError(err)
+		}
+		for _, ev := range events {
+			entry, err := s.queueChange(ctx, req.UserId, queue, ev)
+			if err != nil {
+				return serviceError(err)
+			}
+			if entry == nil {
+				continue
+			}
+			if err := stream.Send(entry); err != nil {
+				return err
+			}
+		}
+
+		var retry <-chan time.Time
+		if wake == nil {
+			retry = time.After(watchPollInterval)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-wake:
+		case <-retry:
+		}
+	}
+}
+
+// queueChange переводит событие в изменение очереди ревью userID и обновляет
+// queue; nil, если очередь не изменилась.
+func (s *Server) queueChange(ctx context.Context, userID string, queue map[string]bool, ev models.Event) (*reviewerv1.ReviewQueueEntry, error) {
+	if ev.PullRequestId == nil {
+		return nil, nil
+	}
+	prID := *ev.PullRequestId
+	reviewer := ev.UserId != nil && *ev.UserId == userID
+
+	var removed bool
+	switch {
+	case ev.Type == models.EventTypeReviewerAssigned && reviewer:
+		queue[prID] = true
+	case ev.Type == models.EventTypeReviewerRemoved && reviewer:
+		delete(queue, prID)
+		removed = true
+	case ev.Type == models.EventTypePRMerged && queue[prID]:
+	default:
+		return nil, nil
+	}
+
+	pr, err := s.ser.GetPullRequest(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+	return &reviewerv1.ReviewQueueEntry{
+		PullRequest: &reviewerv1.PullRequestShort{
+			PullRequestId:   pr.PullRequestId,
+			PullRequestName: pr.PullRequestName,
+			AuthorId:        pr.AuthorId,
+			Status:          statusToProto(string(pr.Status)),
+		},
+		Removed: removed,
+	}, nil
+}
@@ -0,0 +1,125 @@
+// Package idempotency хранит ответы на запросы с заголовком Idempotency-Key,
+// чтобы повтор запроса возвращал исходный ответ, а не выполнялся заново.
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrKeyReused — ключ уже использован с другим телом запроса.
+	ErrKeyReused = errors.New("idempotency key reused with different request")
+	// ErrInProgress — запрос с этим ключом ещё выполняется.
+	ErrInProgress = errors.New("request with this idempotency key is in progress")
+)
+
+type Response struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+type Store struct {
+	db *sql.DB
+}
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Begin резервирует ключ на время lease. Если ключ новый или прежний запрос не
+// завершился и его срок истёк (обработчик упал, процесс перезапущен), возвращает
+// (nil, nil), и вызывающий должен выполнить запрос и вызвать Complete или
+// Release. Если по ключу уже есть сохранённый ответ с тем же хэшем запроса,
+// возвращает его.
+func (s *Store) Begin(ctx context.Context, scope, key, requestHash string, ttl, lease time.Duration) (*Response, error) {
+	// Время считается на стороне приложения: арифметика дат в PostgreSQL и
+	// SQLite несовместима. Истёкшую запись удаляем сразу, чтобы ключ можно
+	// было использовать заново.
+	now := time.Now().UTC()
+	_, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys
+		WHERE scope = $1 AND idempotency_key = $2 AND expires_at < $3`, scope, key, now)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO idempotency_keys (scope, idempotency_key, request_hash, expires_at, locked_until)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (scope, idempotency_key) DO NOTHING
+	`, scope, key, requestHash, now.Add(ttl), now.Add(lease))
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 1 {
+		return nil, nil
+	}
+
+	// Перехват брошенного ключа: условие в UPDATE гарантирует, что из
+	// нескольких повторов ключ получит только один.
+	res, err = s.db.ExecContext(ctx, `
+		UPDATE idempotency_keys SET locked_until = $1
+		WHERE scope = $2 AND idempotency_key = $3 AND request_hash = $4
+			AND status_code IS NULL AND (locked_until IS NULL OR locked_until < $5)
+	`, now.Add(lease), scope, key, requestHash, now)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 1 {
+		return nil, nil
+	}
+
+	var (
+		storedHash  string
+		statusCode  sql.NullInt64
+		contentType sql.NullString
+		body        []byte
+	)
+	err = s.db.QueryRowContext(ctx, `
+		SELECT request_hash, status_code, content_type, response_body
+		FROM idempotency_keys WHERE scope = $1 AND idempotency_key = $2
+	`, scope, key).Scan(&storedHash, &statusCode, &contentType, &body)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Запись успели освободить между INSERT и SELECT — пусть клиент повторит.
+		return nil, ErrInProgress
+	} else if err != nil {
+		return nil, err
+	}
+
+	if storedHash != requestHash {
+		return nil, ErrKeyReused
+	}
+	if !statusCode.Valid {
+		return nil, ErrInProgress
+	}
+	return &Response{
+		StatusCode:  int(statusCode.Int64),
+		ContentType: contentType.String,
+		Body:        body,
+	}, nil
+}
+
+// Complete сохраняет ответ для последующих повторов.
+func (s *Store) Complete(ctx context.Context, scope, key string, resp Response) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE idempotency_keys SET status_code = $1, content_type = $2, response_body = $3, locked_until = NULL
+		WHERE scope = $4 AND idempotency_key = $5
+	`, resp.StatusCode, resp.ContentType, resp.Body, scope, key)
+	return err
+}
+
+// Release освобождает ключ без сохранения ответа (после 5xx или паники),
+// чтобы повтор выполнил запрос заново.
+func (s *Store) Release(ctx context.Context, scope, key string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE scope = $1 AND idempotency_key = $2`, scope, key)
+	return err
+}
+
+// Cleanup удаляет истёкшие записи.
+func (s *Store) Cleanup(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at < $1`, time.Now().UTC())
+	return err
+}
@@ -9,12 +9,101 @@ import (
 
 // Defines values for ErrorResponseErrorCode.
 const (
-	NOCANDIDATE ErrorResponseErrorCode = "NO_CANDIDATE"
-	NOTASSIGNED ErrorResponseErrorCode = "NOT_ASSIGNED"
-	NOTFOUND    ErrorResponseErrorCode = "NOT_FOUND"
-	PREXISTS    ErrorResponseErrorCode = "PR_EXISTS"
-	PRMERGED    ErrorResponseErrorCode = "PR_MERGED"
-	TEAMEXISTS  ErrorResponseErrorCode = "TEAM_EXISTS"
+	IDEMPOTENCYINPROGRESS ErrorResponseErrorCode = "IDEMPOTENCY_IN_PROGRESS"
+	IDEMPOTENCYKEYREUSED  ErrorResponseErrorCode = "IDEMPOTENCY_KEY_REUSED"
+	INTERNALERROR         ErrorResponseErrorCode = "INTERNAL_ERROR"
+	METHODNOTALLOWED      ErrorResponseErrorCode = "METHOD_NOT_ALLOWED"
+	NOCANDIDATE           ErrorResponseErrorCode = "NO_CANDIDATE"
+	NOTASSIGNED           ErrorResponseErrorCode = "NOT_ASSIGNED"
+	NOTFOUND              ErrorResponseErrorCode = "NOT_FOUND"
+	PAYLOADTOOLARGE       ErrorResponseErrorCode = "PAYLOAD_TOO_LARGE"
+	PREXISTS              ErrorResponseErrorCode = "PR_EXISTS"
+	PRMERGED              ErrorResponseErrorCode = "PR_MERGED"
+	RATELIMITED           ErrorResponseErrorCode = "RATE_LIMITED"
+	TEAMEXISTS            ErrorResponseErrorCode = "TEAM_EXISTS"
+	TEAMNOTEMPTY          ErrorResponseErrorCode = "TEAM_NOT_EMPTY"
+	UNAUTHORIZED          ErrorResponseErrorCode = "UNAUTHORIZED"
+	USERHASHISTORY        ErrorResponseErrorCode = "USER_HAS_HISTORY"
+	VALIDATIONERROR       ErrorResponseErrorCode = "VALIDATION_ERROR"
+)
+
+// Defines values for CapacityPolicy.
+const (
+	CapacityPolicyFail        CapacityPolicy = "fail"
+	CapacityPolicyLeastLoaded CapacityPolicy = "least_loaded"
+	CapacityPolicyQueue       CapacityPolicy = "queue"
+)
+
+// Defines values for EscalationPolicy.
+const (
+	EscalationPolicyNone               EscalationPolicy = "none"
+	EscalationPolicyParent             EscalationPolicy = "parent"
+	EscalationPolicySiblings           EscalationPolicy = "siblings"
+	EscalationPolicySiblingsThenParent EscalationPolicy = "siblings_then_parent"
+)
+
+// Defines values for EventType.
+const (
+	EventTypePRCreated        EventType = "PRCreated"
+	EventTypePRMerged         EventType = "PRMerged"
+	EventTypeReviewerAssigned EventType = "ReviewerAssigned"
+	EventTypeReviewerRemoved  EventType = "ReviewerRemoved"
+	EventTypeReviewersPending EventType = "ReviewersPending"
+	EventTypeUserActivated    EventType = "UserActivated"
+	EventTypeUserDeactivated  EventType = "UserDeactivated"
+	EventTypeUserDeleted      EventType = "UserDeleted"
+	EventTypeUserMoved        EventType = "UserMoved"
+)
+
+// Defines values for ReviewPolicy.
+const (
+	ReviewPolicyKeep     ReviewPolicy = "keep"
+	ReviewPolicyReassign ReviewPolicy = "reassign"
+	ReviewPolicyUnassign ReviewPolicy = "unassign"
+)
+
+// Defines values for TeamMembership.
+const (
+	TeamMembershipAdditional TeamMembership = "additional"
+	TeamMembershipPrimary    TeamMembership = "primary"
+)
+
+// Defines values for TeamMemberPolicy.
+const (
+	TeamMemberPolicyMove   TeamMemberPolicy = "move"
+	TeamMemberPolicyReject TeamMemberPolicy = "reject"
+)
+
+// Defines values for UserDeleteMode.
+const (
+	UserDeleteModeAnonymize UserDeleteMode = "anonymize"
+	UserDeleteModeBlock     UserDeleteMode = "block"
+)
+
+// Defines values for UserDeleteResultResult.
+const (
+	UserDeleteResultResultAnonymized UserDeleteResultResult = "anonymized"
+	UserDeleteResultResultDeleted    UserDeleteResultResult = "deleted"
+)
+
+// Defines values for OrgChangeAction.
+const (
+	OrgChangeActionAddMember      OrgChangeAction = "add_member"
+	OrgChangeActionCreateTeam     OrgChangeAction = "create_team"
+	OrgChangeActionCreateUser     OrgChangeAction = "create_user"
+	OrgChangeActionDeactivateUser OrgChangeAction = "deactivate_user"
+	OrgChangeActionDeleteTeam     OrgChangeAction = "delete_team"
+	OrgChangeActionDeleteUser     OrgChangeAction = "delete_user"
+	OrgChangeActionMoveUser       OrgChangeAction = "move_user"
+	OrgChangeActionRemoveMember   OrgChangeAction = "remove_member"
+	OrgChangeActionUpdateTeam     OrgChangeAction = "update_team"
+	OrgChangeActionUpdateUser     OrgChangeAction = "update_user"
+)
+
+// Defines values for PostOrgSyncParamsAbsentUsers.
+const (
+	PostOrgSyncParamsAbsentUsersDeactivate PostOrgSyncParamsAbsentUsers = "deactivate"
+	PostOrgSyncParamsAbsentUsersDelete     PostOrgSyncParamsAbsentUsers = "delete"
 )
 
 // Defines values for PullRequestStatus.
@@ -29,32 +118,136 @@ const (
 	PullRequestShortStatusOPEN   PullRequestShortStatus = "OPEN"
 )
 
+// Defines values for BatchItemResultStatus.
+const (
+	BatchItemResultStatusFailed     BatchItemResultStatus = "failed"
+	BatchItemResultStatusOk         BatchItemResultStatus = "ok"
+	BatchItemResultStatusRolledBack BatchItemResultStatus = "rolled_back"
+	BatchItemResultStatusSkipped    BatchItemResultStatus = "skipped"
+)
+
+// Defines values for BatchOperationOp.
+const (
+	BatchOperationOpCreate      BatchOperationOp = "create"
+	BatchOperationOpMerge       BatchOperationOp = "merge"
+	BatchOperationOpReassign    BatchOperationOp = "reassign"
+	BatchOperationOpSetIsActive BatchOperationOp = "set_is_active"
+)
+
+// Defines values for AssignmentCandidateReason.
+const (
+	AssignmentCandidateReasonAlreadyAssigned AssignmentCandidateReason = "already_assigned"
+	AssignmentCandidateReasonAtCapacity      AssignmentCandidateReason = "at_capacity"
+	AssignmentCandidateReasonAuthor          AssignmentCandidateReason = "author"
+	AssignmentCandidateReasonEligible        AssignmentCandidateReason = "eligible"
+	AssignmentCandidateReasonInactive        AssignmentCandidateReason = "inactive"
+	AssignmentCandidateReasonSelected        AssignmentCandidateReason = "selected"
+)
+
+// CapacityPolicy Что делать, если слот ревьювера можно заполнить только сверх лимита открытых ревью
+type CapacityPolicy string
+
+// EscalationPolicy Откуда добирать ревьюверов, если в команде не хватает кандидатов
+type EscalationPolicy string
+
 // ErrorResponse defines model for ErrorResponse.
 type ErrorResponse struct {
 	Error struct {
-		Code    ErrorResponseErrorCode `json:"code"`
-		Message string                 `json:"message"`
+		Code ErrorResponseErrorCode `json:"code"`
+
+		// Details Ошибки по полям (для VALIDATION_ERROR)
+		Details *[]FieldError `json:"details,omitempty"`
+		Message string        `json:"message"`
 	} `json:"error"`
 }
 
 // ErrorResponseErrorCode defines model for ErrorResponse.Error.Code.
 type ErrorResponseErrorCode string
 
+// Event defines model for Event.
+type Event struct {
+	// Id Номер события в журнале, совпадает с `id:` в потоке SSE
+	Id         int64     `json:"id"`
+	OccurredAt time.Time `json:"occurred_at"`
+
+	// PullRequestId PR события (для событий PR)
+	PullRequestId *string `json:"pull_request_id,omitempty"`
+
+	// Selection Решение о выборе ревьювера (для ReviewerAssigned)
+	Selection *ReviewerSelection `json:"selection,omitempty"`
+
+	// TeamName Команда PR
+	TeamName *string   `json:"team_name"`
+	Type     EventType `json:"type"`
+
+	// UserId Назначенный или снятый ревьювер, либо пользователь события активности
+	UserId *string `json:"user_id,omitempty"`
+}
+
+// EventType defines model for Event.Type.
+type EventType string
+
+// FieldError defines model for FieldError.
+type FieldError struct {
+	// Field Путь к полю тела запроса (через точку) или имя параметра
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// OrgChange defines model for OrgChange.
+type OrgChange struct {
+	Action OrgChangeAction `json:"action"`
+
+	// Field Изменяемое поле (для update_team/update_user/move_user)
+	Field *string `json:"field,omitempty"`
+	New   *string `json:"new,omitempty"`
+	Old   *string `json:"old,omitempty"`
+
+	TeamName string  `json:"team_name"`
+	UserId   *string `json:"user_id,omitempty"`
+}
+
+// OrgChangeAction defines model for OrgChange.Action.
+type OrgChangeAction string
+
+// OrgPlan defines model for OrgPlan.
+type OrgPlan struct {
+	// Applied Изменения применены (false для dry_run)
+	Applied bool        `json:"applied"`
+	Changes []OrgChange `json:"changes"`
+
+	// Reviews Переназначенные ревью удалённых из команд пользователей (только для синхронизации; в dry_run замены — пример)
+	Reviews *[]ReviewReassignment `json:"reviews,omitempty"`
+}
+
 // PullRequest defines model for PullRequest.
 type PullRequest struct {
 	// AssignedReviewers user_id назначенных ревьюверов (0..2)
-	AssignedReviewers []string          `json:"assigned_reviewers"`
-	AuthorId          string            `json:"author_id"`
-	CreatedAt         *time.Time        `json:"createdAt"`
-	MergedAt          *time.Time        `json:"mergedAt"`
-	PullRequestId     string            `json:"pull_request_id"`
-	PullRequestName   string            `json:"pull_request_name"`
-	Status            PullRequestStatus `json:"status"`
+	AssignedReviewers []string   `json:"assigned_reviewers"`
+	AuthorId          string     `json:"author_id"`
+	CreatedAt         *time.Time `json:"createdAt"`
+	MergedAt          *time.Time `json:"mergedAt"`
+
+	// PendingReviewers Слоты ревьюверов, которые ещё не удалось заполнить
+	PendingReviewers int               `json:"pending_reviewers"`
+	PullRequestId    string            `json:"pull_request_id"`
+	PullRequestName  string            `json:"pull_request_name"`
+	Status           PullRequestStatus `json:"status"`
+
+	// TeamName Команда, из которой назначаются ревьюверы
+	TeamName *string `json:"team_name"`
 }
 
 // PullRequestStatus defines model for PullRequest.Status.
 type PullRequestStatus string
 
+// PullRequestList defines model for PullRequestList.
+type PullRequestList struct {
+	// NextCursor Значение cursor для следующей страницы; null на последней странице
+	NextCursor   *string       `json:"next_cursor"`
+	PullRequests []PullRequest `json:"pull_requests"`
+}
+
 // PullRequestShort defines model for PullRequestShort.
 type PullRequestShort struct {
 	AuthorId        string                 `json:"author_id"`
@@ -66,12 +259,81 @@ type PullRequestShort struct {
 // PullRequestShortStatus defines model for PullRequestShort.Status.
 type PullRequestShortStatus string
 
+// ReviewPolicy Что делать с открытыми ревью пользователя, покидающего команду
+type ReviewPolicy string
+
+// ReviewerSelection Данные, по которым выбор ревьюверов повторяется без обращения к базе
+type ReviewerSelection struct {
+	// Candidates Кандидаты по ступеням эскалации (своя команда, затем эскалация) в порядке user_id
+	Candidates [][]string `json:"candidates"`
+
+	// Count Сколько ревьюверов требовалось выбрать
+	Count int `json:"count"`
+
+	// Overflow Кандидаты сверх лимита открытых ревью (политика least_loaded) по возрастанию загрузки
+	Overflow []string `json:"overflow,omitempty"`
+
+	// Seed Зерно генератора для этого решения
+	Seed int64 `json:"seed"`
+
+	// Strategy Алгоритм выбора
+	Strategy string `json:"strategy"`
+}
+
+// ReviewReassignment defines model for ReviewReassignment.
+type ReviewReassignment struct {
+	OldUserId     string `json:"old_user_id"`
+	PullRequestId string `json:"pull_request_id"`
+
+	// NewUserId Новый ревьювер; null, если ревьювер снят без замены
+	NewUserId *string `json:"new_user_id"`
+}
+
 // Team defines model for Team.
 type Team struct {
-	Members  []TeamMember `json:"members"`
-	TeamName string       `json:"team_name"`
+	// CapacityPolicy Что делать, если слот ревьювера можно заполнить только сверх лимита открытых ревью
+	CapacityPolicy *CapacityPolicy `json:"capacity_policy,omitempty"`
+
+	// DefaultMaxOpenReviews Лимит открытых ревью для участников, у которых это основная команда и нет своего лимита
+	DefaultMaxOpenReviews *int `json:"default_max_open_reviews,omitempty"`
+
+	// EscalationPolicy Откуда добирать ревьюверов, если в команде не хватает кандидатов
+	EscalationPolicy *EscalationPolicy `json:"escalation_policy,omitempty"`
+	Members          []TeamMember      `json:"members"`
+
+	// Membership Какой становится команда для уже существующих пользователей (только в запросе)
+	Membership *TeamMembership `json:"membership,omitempty"`
+
+	// ParentTeam Родительская команда (департамент)
+	ParentTeam *string `json:"parent_team,omitempty"`
+	TeamName   string  `json:"team_name"`
+}
+
+// TeamDeleteResult defines model for TeamDeleteResult.
+type TeamDeleteResult struct {
+	// MovedMembers user_id участников, перемещённых в target_team
+	MovedMembers []string             `json:"moved_members"`
+	Reviews      []ReviewReassignment `json:"reviews"`
+	TeamName     string               `json:"team_name"`
 }
 
+// TeamNode defines model for TeamNode.
+type TeamNode struct {
+	Children         []TeamNode       `json:"children"`
+	EscalationPolicy EscalationPolicy `json:"escalation_policy"`
+
+	// MembersCount Число участников команды (без подкоманд)
+	MembersCount int     `json:"members_count"`
+	ParentTeam   *string `json:"parent_team"`
+	TeamName     string  `json:"team_name"`
+}
+
+// TeamMembership Какой становится команда для уже существующих пользователей (только в запросе)
+type TeamMembership string
+
+// TeamMemberPolicy Что делать с участниками удаляемой команды
+type TeamMemberPolicy string
+
 // TeamMember defines model for TeamMember.
 type TeamMember struct {
 	IsActive bool   `json:"is_active"`
@@ -81,10 +343,47 @@ type TeamMember struct {
 
 // User defines model for User.
 type User struct {
-	IsActive bool   `json:"is_active"`
+	// DeletedAt Когда пользователь был удалён (обезличен)
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	IsActive  bool       `json:"is_active"`
+
+	// MaxOpenReviews Лимит открытых ревью пользователя; без него действует лимит основной команды
+	MaxOpenReviews *int `json:"max_open_reviews,omitempty"`
+
+	// TeamName Основная команда пользователя
 	TeamName string `json:"team_name"`
-	UserId   string `json:"user_id"`
-	Username string `json:"username"`
+
+	// Teams Все команды пользователя, включая основную
+	Teams    []string `json:"teams"`
+	UserId   string   `json:"user_id"`
+	Username string   `json:"username"`
+}
+
+// UserDeleteMode Что делать с пользователем, у которого есть PR или ревью
+type UserDeleteMode string
+
+// UserDeleteResult defines model for UserDeleteResult.
+type UserDeleteResult struct {
+	// Result deleted — пользователь удалён полностью, anonymized — обезличен
+	Result  UserDeleteResultResult `json:"result"`
+	Reviews []ReviewReassignment   `json:"reviews"`
+	UserId  string                 `json:"user_id"`
+}
+
+// UserDeleteResultResult defines model for UserDeleteResult.Result.
+type UserDeleteResultResult string
+
+// UserList defines model for UserList.
+type UserList struct {
+	// NextCursor Значение cursor для следующей страницы; null на последней странице
+	NextCursor *string `json:"next_cursor"`
+	Users      []User  `json:"users"`
+}
+
+// UserMoveResult defines model for UserMoveResult.
+type UserMoveResult struct {
+	Reviews []ReviewReassignment `json:"reviews"`
+	User    User                 `json:"user"`
 }
 
 type AssignmentStats struct {
@@ -92,6 +391,174 @@ type AssignmentStats struct {
 	Count  int    `json:"count"`
 }
 
+// AssignmentCandidate Участник команды, рассмотренный при выборе ревьюверов
+type AssignmentCandidate struct {
+	// Included Попал ли пользователь в число кандидатов ступени
+	Included bool `json:"included"`
+
+	// Reason selected — выбран; eligible — кандидат, не выбранный в этом розыгрыше;
+	// author — автор PR; inactive — неактивен; already_assigned — уже выбран на
+	// предыдущей ступени; at_capacity — достиг лимита открытых ревью
+	Reason   AssignmentCandidateReason `json:"reason"`
+	TeamName string                    `json:"team_name"`
+
+	// Tier Ступень эскалации: 0 — команда PR, далее — по политике эскалации
+	Tier   int    `json:"tier"`
+	UserId string `json:"user_id"`
+}
+
+// AssignmentCandidateReason selected — выбран; eligible — кандидат, не выбранный в этом розыгрыше;
+// author — автор PR; inactive — неактивен; already_assigned — уже выбран на
+// предыдущей ступени; at_capacity — достиг лимита открытых ревью.
+// Отдельного состояния «в отпуске» нет: отсутствующего пользователя
+// деактивируют (POST /users/setIsActive), и он получает inactive
+type AssignmentCandidateReason string
+
+// AssignmentPreview Результат пробного выбора ревьюверов
+type AssignmentPreview struct {
+	// Candidates Участники просмотренных ступеней с причиной включения или исключения
+	Candidates []AssignmentCandidate `json:"candidates"`
+
+	// Reviewers Ревьюверы, выбранные в этом розыгрыше
+	Reviewers []string          `json:"reviewers"`
+	Selection ReviewerSelection `json:"selection"`
+
+	// TeamName Команда PR
+	TeamName string `json:"team_name"`
+}
+
+// BatchItemResult defines model for BatchItemResult.
+type BatchItemResult struct {
+	// Error Ошибка операции (для status=failed)
+	Error *struct {
+		Code    ErrorResponseErrorCode `json:"code"`
+		Message string                 `json:"message"`
+	} `json:"error,omitempty"`
+
+	// Index Номер операции в запросе
+	Index int `json:"index"`
+
+	// Pr PR после операции (create, merge, reassign)
+	Pr *PullRequest `json:"pr,omitempty"`
+
+	// Status ok — выполнена; failed — ошибка; rolled_back — выполнена, но отменена
+	// вместе с пакетом (atomic); skipped — не выполнялась
+	Status BatchItemResultStatus `json:"status"`
+
+	// User Пользователь после операции (set_is_active)
+	User *User `json:"user,omitempty"`
+}
+
+// BatchItemResultStatus ok — выполнена; failed — ошибка; rolled_back — выполнена, но отменена
+// вместе с пакетом (atomic); skipped — не выполнялась
+type BatchItemResultStatus string
+
+// BatchOperation Операция пакета; поля — как в теле соответствующего эндпоинта
+type BatchOperation struct {
+	AuthorId        *string          `json:"author_id,omitempty"`
+	IsActive        *bool            `json:"is_active,omitempty"`
+	OldUserId       *string          `json:"old_user_id,omitempty"`
+	Op              BatchOperationOp `json:"op"`
+	PullRequestId   *string          `json:"pull_request_id,omitempty"`
+	PullRequestName *string          `json:"pull_request_name,omitempty"`
+	TeamName        *string          `json:"team_name,omitempty"`
+	UserId          *string          `json:"user_id,omitempty"`
+}
+
+// BatchOperationOp defines model for BatchOperation.Op.
+type BatchOperationOp string
+
+// BatchResult defines model for BatchResult.
+type BatchResult struct {
+	// Committed Изменения сохранены (false, если atomic-пакет отменён)
+	Committed bool              `json:"committed"`
+	Results   []BatchItemResult `json:"results"`
+}
+
+// GetAssignmentStatsParams defines parameters for GetAssignmentStats.
+type GetAssignmentStatsParams struct {
+	// TeamName Считать только PR этой команды и только её участников
+	TeamName *TeamNameQuery `form:"team_name,omitempty" json:"team_name,omitempty"`
+
+	// IncludeSubteams Учитывать также все подкоманды team_name
+	IncludeSubteams *IncludeSubteamsQuery `form:"include_subteams,omitempty" json:"include_subteams,omitempty"`
+}
+
+// GetEventsStreamParams defines parameters for GetEventsStream.
+type GetEventsStreamParams struct {
+	// TeamName Только PR этой команды и активность её участников
+	TeamName *string `form:"team_name,omitempty" json:"team_name,omitempty"`
+
+	// UserId Только PR, где пользователь автор или ревьювер, и его активность
+	UserId *string `form:"user_id,omitempty" json:"user_id,omitempty"`
+
+	// LastEventId То же, что Last-Event-ID, для клиентов без доступа к заголовкам
+	LastEventId *int64 `form:"last_event_id,omitempty" json:"last_event_id,omitempty"`
+
+	// LastEventID Продолжить после события с этим номером
+	LastEventID *int64 `json:"Last-Event-ID,omitempty"`
+}
+
+// GetPullRequestListParams defines parameters for GetPullRequestList.
+type GetPullRequestListParams struct {
+	// TeamName Только PR этой команды
+	TeamName *TeamNameQuery `form:"team_name,omitempty" json:"team_name,omitempty"`
+
+	// IncludeSubteams Учитывать также все подкоманды team_name
+	IncludeSubteams *IncludeSubteamsQuery `form:"include_subteams,omitempty" json:"include_subteams,omitempty"`
+	Status          *PullRequestStatus    `form:"status,omitempty" json:"status,omitempty"`
+	Limit           *int                  `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor next_cursor из предыдущей страницы
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// GetTeamTreeParams defines parameters for GetTeamTree.
+type GetTeamTreeParams struct {
+	// TeamName Корень поддерева; без параметра возвращаются все деревья
+	TeamName *TeamNameQuery `form:"team_name,omitempty" json:"team_name,omitempty"`
+}
+
+// PostTeamImportParams defines parameters for PostTeamImport.
+type PostTeamImportParams struct {
+	// DryRun Только показать изменения, не применяя их
+	DryRun *bool `form:"dry_run,omitempty" json:"dry_run,omitempty"`
+}
+
+// PostOrgSyncParams defines parameters for PostOrgSync.
+type PostOrgSyncParams struct {
+	// DryRun Только показать изменения, не применяя их
+	DryRun *bool `form:"dry_run,omitempty" json:"dry_run,omitempty"`
+
+	// AbsentUsers Что делать с пользователями, которых нет в файле
+	AbsentUsers *PostOrgSyncParamsAbsentUsers `form:"absent_users,omitempty" json:"absent_users,omitempty"`
+}
+
+// PostOrgSyncParamsAbsentUsers defines parameters for PostOrgSync.
+type PostOrgSyncParamsAbsentUsers string
+
+// GetUsersGetParams defines parameters for GetUsersGet.
+type GetUsersGetParams struct {
+	UserId UserIdQuery `form:"user_id" json:"user_id"`
+}
+
+// GetUsersListParams defines parameters for GetUsersList.
+type GetUsersListParams struct {
+	// TeamName Только участники этой команды (основной или дополнительной)
+	TeamName *TeamNameQuery `form:"team_name,omitempty" json:"team_name,omitempty"`
+	IsActive *bool          `form:"is_active,omitempty" json:"is_active,omitempty"`
+
+	// NamePrefix Префикс username без учёта регистра
+	NamePrefix *string `form:"name_prefix,omitempty" json:"name_prefix,omitempty"`
+	Limit      *int    `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor next_cursor из предыдущей страницы
+	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// IncludeSubteamsQuery defines model for IncludeSubteamsQuery.
+type IncludeSubteamsQuery = bool
+
 // TeamNameQuery defines model for TeamNameQuery.
 type TeamNameQuery = string
 
@@ -103,6 +570,9 @@ type PostPullRequestCreateJSONBody struct {
 	AuthorId        string `json:"author_id"`
 	PullRequestId   string `json:"pull_request_id"`
 	PullRequestName string `json:"pull_request_name"`
+
+	// TeamName Команда PR; по умолчанию основная команда автора
+	TeamName *string `json:"team_name,omitempty"`
 }
 
 // PostPullRequestMergeJSONBody defines parameters for PostPullRequestMerge.
@@ -122,12 +592,43 @@ type GetTeamGetParams struct {
 	TeamName TeamNameQuery `form:"team_name" json:"team_name"`
 }
 
+// GetPullRequestGetParams defines parameters for GetPullRequestGet.
+type GetPullRequestGetParams struct {
+	PullRequestId string `form:"pull_request_id" json:"pull_request_id"`
+}
+
+// GetPullRequestGetAsOfParams defines parameters for GetPullRequestGetAsOf.
+type GetPullRequestGetAsOfParams struct {
+	PullRequestId string `form:"pull_request_id" json:"pull_request_id"`
+
+	// At Момент времени (RFC 3339)
+	At time.Time `form:"at" json:"at"`
+}
+
 // GetUsersGetReviewParams defines parameters for GetUsersGetReview.
 type GetUsersGetReviewParams struct {
 	// UserId Идентификатор пользователя
 	UserId UserIdQuery `form:"user_id" json:"user_id"`
 }
 
+// PostBatchJSONBody defines parameters for PostBatch.
+type PostBatchJSONBody struct {
+	// Atomic Выполнить все операции в одной транзакции
+	Atomic     *bool            `json:"atomic,omitempty"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+// PostPullRequestPreviewAssignmentJSONBody defines parameters for PostPullRequestPreviewAssignment.
+type PostPullRequestPreviewAssignmentJSONBody struct {
+	AuthorId string `json:"author_id"`
+
+	// PullRequestId Будущий идентификатор PR; если задан, проверяется, что он свободен
+	PullRequestId *string `json:"pull_request_id,omitempty"`
+
+	// TeamName Команда PR; по умолчанию основная команда автора
+	TeamName *string `json:"team_name,omitempty"`
+}
+
 // PostUsersSetIsActiveJSONBody defines parameters for PostUsersSetIsActive.
 type PostUsersSetIsActiveJSONBody struct {
 	IsActive bool   `json:"is_active"`
@@ -143,8 +644,115 @@ type PostPullRequestMergeJSONRequestBody PostPullRequestMergeJSONBody
 // PostPullRequestReassignJSONRequestBody defines body for PostPullRequestReassign for application/json ContentType.
 type PostPullRequestReassignJSONRequestBody PostPullRequestReassignJSONBody
 
+// PostPullRequestPreviewAssignmentJSONRequestBody defines body for PostPullRequestPreviewAssignment for application/json ContentType.
+type PostPullRequestPreviewAssignmentJSONRequestBody PostPullRequestPreviewAssignmentJSONBody
+
+// PostTeamRenameJSONBody defines parameters for PostTeamRename.
+type PostTeamRenameJSONBody struct {
+	NewTeamName string `json:"new_team_name"`
+	TeamName    string `json:"team_name"`
+}
+
+// PostTeamDeleteJSONBody defines parameters for PostTeamDelete.
+type PostTeamDeleteJSONBody struct {
+	MemberPolicy *TeamMemberPolicy `json:"member_policy,omitempty"`
+	ReviewPolicy *ReviewPolicy     `json:"review_policy,omitempty"`
+	TargetTeam   *string           `json:"target_team,omitempty"`
+	TeamName     string            `json:"team_name"`
+}
+
+// PostUsersMoveTeamJSONBody defines parameters for PostUsersMoveTeam.
+type PostUsersMoveTeamJSONBody struct {
+	ReviewPolicy *ReviewPolicy `json:"review_policy,omitempty"`
+	TeamName     string        `json:"team_name"`
+	UserId       string        `json:"user_id"`
+}
+
+// PostTeamRemoveMemberJSONBody defines parameters for PostTeamRemoveMember.
+type PostTeamRemoveMemberJSONBody struct {
+	ReviewPolicy *ReviewPolicy `json:"review_policy,omitempty"`
+	TeamName     string        `json:"team_name"`
+	UserId       string        `json:"user_id"`
+}
+
+// PostTeamSetParentJSONBody defines parameters for PostTeamSetParent.
+type PostTeamSetParentJSONBody struct {
+	// ParentTeam Новая родительская команда; null делает команду корневой
+	ParentTeam *string `json:"parent_team"`
+	TeamName   string  `json:"team_name"`
+}
+
+// PostTeamSetEscalationPolicyJSONBody defines parameters for PostTeamSetEscalationPolicy.
+type PostTeamSetEscalationPolicyJSONBody struct {
+	EscalationPolicy EscalationPolicy `json:"escalation_policy"`
+	TeamName         string           `json:"team_name"`
+}
+
+// PostTeamSetReviewCapacityJSONBody defines parameters for PostTeamSetReviewCapacity.
+type PostTeamSetReviewCapacityJSONBody struct {
+	// CapacityPolicy Что делать, если слот ревьювера можно заполнить только сверх лимита открытых ревью
+	CapacityPolicy CapacityPolicy `json:"capacity_policy"`
+
+	// DefaultMaxOpenReviews Лимит по умолчанию; null — без лимита
+	DefaultMaxOpenReviews *int   `json:"default_max_open_reviews"`
+	TeamName              string `json:"team_name"`
+}
+
+// PostUsersSetMaxOpenReviewsJSONBody defines parameters for PostUsersSetMaxOpenReviews.
+type PostUsersSetMaxOpenReviewsJSONBody struct {
+	// MaxOpenReviews Лимит пользователя; null — действует лимит основной команды
+	MaxOpenReviews *int   `json:"max_open_reviews"`
+	UserId         string `json:"user_id"`
+}
+
+// PatchUsersUpdateJSONBody defines parameters for PatchUsersUpdate.
+type PatchUsersUpdateJSONBody struct {
+	UserId   string `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// PostUsersDeleteJSONBody defines parameters for PostUsersDelete.
+type PostUsersDeleteJSONBody struct {
+	Mode         *UserDeleteMode `json:"mode,omitempty"`
+	ReviewPolicy *ReviewPolicy   `json:"review_policy,omitempty"`
+	UserId       string          `json:"user_id"`
+}
+
+// PatchUsersUpdateJSONRequestBody defines body for PatchUsersUpdate for application/json ContentType.
+type PatchUsersUpdateJSONRequestBody PatchUsersUpdateJSONBody
+
+// PostUsersDeleteJSONRequestBody defines body for PostUsersDelete for application/json ContentType.
+type PostUsersDeleteJSONRequestBody PostUsersDeleteJSONBody
+
+// PostTeamSetParentJSONRequestBody defines body for PostTeamSetParent for application/json ContentType.
+type PostTeamSetParentJSONRequestBody PostTeamSetParentJSONBody
+
+// PostTeamSetEscalationPolicyJSONRequestBody defines body for PostTeamSetEscalationPolicy for application/json ContentType.
+type PostTeamSetEscalationPolicyJSONRequestBody PostTeamSetEscalationPolicyJSONBody
+
+// PostTeamSetReviewCapacityJSONRequestBody defines body for PostTeamSetReviewCapacity for application/json ContentType.
+type PostTeamSetReviewCapacityJSONRequestBody PostTeamSetReviewCapacityJSONBody
+
+// PostTeamRemoveMemberJSONRequestBody defines body for PostTeamRemoveMember for application/json ContentType.
+type PostTeamRemoveMemberJSONRequestBody PostTeamRemoveMemberJSONBody
+
+// PostTeamRenameJSONRequestBody defines body for PostTeamRename for application/json ContentType.
+type PostTeamRenameJSONRequestBody PostTeamRenameJSONBody
+
+// PostTeamDeleteJSONRequestBody defines body for PostTeamDelete for application/json ContentType.
+type PostTeamDeleteJSONRequestBody PostTeamDeleteJSONBody
+
+// PostUsersMoveTeamJSONRequestBody defines body for PostUsersMoveTeam for application/json ContentType.
+type PostUsersMoveTeamJSONRequestBody PostUsersMoveTeamJSONBody
+
 // PostTeamAddJSONRequestBody defines body for PostTeamAdd for application/json ContentType.
 type PostTeamAddJSONRequestBody = Team
 
 // PostUsersSetIsActiveJSONRequestBody defines body for PostUsersSetIsActive for application/json ContentType.
 type PostUsersSetIsActiveJSONRequestBody PostUsersSetIsActiveJSONBody
+
+// PostUsersSetMaxOpenReviewsJSONRequestBody defines body for PostUsersSetMaxOpenReviews for application/json ContentType.
+type PostUsersSetMaxOpenReviewsJSONRequestBody PostUsersSetMaxOpenReviewsJSONBody
+
+// PostBatchJSONRequestBody defines body for PostBatch for application/json ContentType.
+type PostBatchJSONRequestBody PostBatchJSONBody
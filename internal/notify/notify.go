@@ -0,0 +1,99 @@
+// Package notify будит читателей журнала событий, когда в нём появляются
+// новые записи: по LISTEN/NOTIFY PostgreSQL (сигнал получают все реплики)
+// или по таймеру.
+package notify
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Channel — канал NOTIFY, в который триггер на events сообщает о новых событиях.
+const Channel = "events"
+
+// Hub рассылает сигнал «есть новые события» подписчикам. Сигнал не несёт
+// данных: подписчик сам дочитывает журнал от своего курсора.
+type Hub struct {
+	mu   sync.Mutex
+	subs map[chan struct{}]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: map[chan struct{}]struct{}{}}
+}
+
+// Subscribe возвращает канал сигналов и функцию отписки. Сигналы не копятся:
+// несколько Notify до чтения канала дают один сигнал.
+func (h *Hub) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		delete(h.subs, ch)
+		h.mu.Unlock()
+	}
+}
+
+func (h *Hub) Notify() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Poll вызывает Notify каждые interval до отмены ctx. Нужен, когда
+// уведомлений от базы нет (SQLite).
+func (h *Hub) Poll(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.Notify()
+		}
+	}
+}
+
+// ListenPostgres подписывается на Channel отдельным соединением по dsn и
+// вызывает Notify на каждое уведомление. Уведомления за время обрыва
+// соединения теряются, поэтому после переподключения Notify вызывается тоже.
+func (h *Hub) ListenPostgres(ctx context.Context, dsn string) error {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			slog.Warn("Event listener connection problem", "error", err)
+		}
+	})
+	if err := listener.Listen(Channel); err != nil {
+		listener.Close()
+		return err
+	}
+
+	go func() {
+		defer listener.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-listener.Notify:
+				// nil приходит после переподключения.
+				h.Notify()
+			case <-time.After(90 * time.Second):
+				go listener.Ping()
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHub_NotifyCoalescesSignals(t *testing.T) {
+	h := NewHub()
+	ch, cancel := h.Subscribe()
+	defer cancel()
+
+	h.Notify()
+	h.Notify()
+
+	assert.Len(t, ch, 1)
+	<-ch
+	assert.Len(t, ch, 0)
+}
+
+func TestHub_Unsubscribe(t *testing.T) {
+	h := NewHub()
+	ch1, cancel1 := h.Subscribe()
+	ch2, cancel2 := h.Subscribe()
+	defer cancel2()
+
+	cancel1()
+	h.Notify()
+
+	assert.Len(t, ch1, 0)
+	assert.Len(t, ch2, 1)
+}
@@ -0,0 +1,126 @@
+package orgfile
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"pull-request-api.com/internal/models"
+)
+
+// Колонки CSV. Одна строка — один участник команды; строка без user_id
+// описывает команду без участников. Настройки команды (parent_team,
+// escalation_policy) можно указать в любой её строке, но не противоречиво.
+var csvColumns = []string{"team_name", "user_id", "username", "is_active", "parent_team", "escalation_policy"}
+
+func parseCSV(data []byte) ([]models.Team, []teamPaths, []models.FieldError) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	var errs []models.FieldError
+	add := func(field, format string, args ...any) {
+		errs = append(errs, models.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, []models.FieldError{{Field: "1", Message: "header row is required"}}
+	} else if err != nil {
+		return nil, nil, []models.FieldError{{Field: "1", Message: err.Error()}}
+	}
+
+	col := map[string]int{}
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		switch {
+		case !slices.Contains(csvColumns, name):
+			add("1."+name, "unknown column")
+		case col[name] != 0:
+			add("1."+name, "duplicate column")
+		default:
+			col[name] = i + 1
+		}
+	}
+	if col["team_name"] == 0 {
+		add("1.team_name", "column is required")
+	}
+	if col["user_id"] != 0 && col["username"] == 0 {
+		add("1.username", "column is required together with user_id")
+	}
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+
+	var (
+		teams []models.Team
+		paths []teamPaths
+		index = map[string]int{}
+	)
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := r.FieldPos(0)
+		prefix := strconv.Itoa(line) + "."
+		if err != nil {
+			add(strconv.Itoa(line), "%v", err)
+			continue
+		}
+		get := func(name string) string {
+			if i := col[name]; i != 0 && i <= len(record) {
+				return strings.TrimSpace(record[i-1])
+			}
+			return ""
+		}
+
+		name := get("team_name")
+		i, ok := index[name]
+		if !ok || name == "" {
+			i = len(teams)
+			index[name] = i
+			teams = append(teams, models.Team{TeamName: name, Members: []models.TeamMember{}})
+			paths = append(paths, teamPaths{team: prefix})
+		}
+		team := &teams[i]
+
+		if v := get("parent_team"); v != "" {
+			if team.ParentTeam != nil && *team.ParentTeam != v {
+				add(prefix+"parent_team", "conflicts with %q set earlier for team %q", *team.ParentTeam, name)
+			}
+			team.ParentTeam = &v
+		}
+		if v := get("escalation_policy"); v != "" {
+			policy := models.EscalationPolicy(v)
+			if team.EscalationPolicy != nil && *team.EscalationPolicy != policy {
+				add(prefix+"escalation_policy", "conflicts with %q set earlier for team %q", *team.EscalationPolicy, name)
+			}
+			team.EscalationPolicy = &policy
+		}
+
+		userID := get("user_id")
+		if userID == "" {
+			if get("username") != "" || get("is_active") != "" {
+				add(prefix+"user_id", "must not be empty when username or is_active is set")
+			}
+			continue
+		}
+
+		active := true
+		if v := get("is_active"); v != "" {
+			active, err = strconv.ParseBool(v)
+			if err != nil {
+				add(prefix+"is_active", "must be true or false")
+			}
+		}
+		team.Members = append(team.Members, models.TeamMember{UserId: userID, Username: get("username"), IsActive: active})
+		paths[i].members = append(paths[i].members, prefix)
+	}
+	return teams, paths, errs
+}
@@ -0,0 +1,196 @@
+// Package orgfile разбирает описание оргструктуры (команды, участники,
+// настройки команд) из CSV или YAML и проверяет файл целиком до применения.
+package orgfile
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"pull-request-api.com/internal/models"
+)
+
+type Format string
+
+const (
+	CSV  Format = "csv"
+	YAML Format = "yaml"
+)
+
+// FormatFromContentType определяет формат по заголовку Content-Type.
+func FormatFromContentType(contentType string) (Format, bool) {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.TrimSpace(strings.ToLower(mediaType)) {
+	case "text/csv":
+		return CSV, true
+	case "application/yaml", "application/x-yaml", "text/yaml":
+		return YAML, true
+	}
+	return "", false
+}
+
+// FormatFromPath определяет формат по расширению файла.
+func FormatFromPath(path string) (Format, bool) {
+	switch {
+	case strings.HasSuffix(path, ".csv"):
+		return CSV, true
+	case strings.HasSuffix(path, ".yaml"), strings.HasSuffix(path, ".yml"):
+		return YAML, true
+	}
+	return "", false
+}
+
+// ValidationError содержит все найденные в файле ошибки. Field — путь к полю:
+// для YAML через точку (teams.0.members.1.user_id), для CSV — номер строки и
+// колонка (3.user_id).
+type ValidationError struct {
+	Details []models.FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Details))
+	for _, d := range e.Details {
+		msgs = append(msgs, d.Field+": "+d.Message)
+	}
+	return "invalid org file: " + strings.Join(msgs, "; ")
+}
+
+// Parse разбирает файл для импорта и проверяет его. Каждый пользователь может
+// быть указан только в одной команде. При ошибках в данных возвращает
+// *ValidationError со списком всех ошибок.
+func Parse(data []byte, format Format) ([]models.Team, error) {
+	return parse(data, format, false)
+}
+
+// ParseDesired разбирает полное желаемое описание оргструктуры. Пользователь
+// может входить в несколько команд: первая команда, где он указан, становится
+// основной, а username и is_active во всех командах должны совпадать.
+func ParseDesired(data []byte, format Format) ([]models.Team, error) {
+	return parse(data, format, true)
+}
+
+func parse(data []byte, format Format, shared bool) ([]models.Team, error) {
+	var (
+		teams []models.Team
+		paths []teamPaths
+		errs  []models.FieldError
+	)
+	switch format {
+	case CSV:
+		teams, paths, errs = parseCSV(data)
+	case YAML:
+		teams, paths, errs = parseYAML(data)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+
+	if len(errs) == 0 {
+		errs = validate(teams, paths, shared)
+	}
+	if len(errs) > 0 {
+		return nil, &ValidationError{Details: errs}
+	}
+	return teams, nil
+}
+
+// teamPaths — пути к полям команды и её участников в исходном файле.
+type teamPaths struct {
+	team    string
+	members []string
+}
+
+const (
+	maxIDLength   = 128
+	maxNameLength = 255
+)
+
+func validate(teams []models.Team, paths []teamPaths, shared bool) []models.FieldError {
+	var errs []models.FieldError
+	add := func(field, format string, args ...any) {
+		errs = append(errs, models.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	seenTeams := map[string]bool{}
+	userTeam := map[string]string{}
+	firstSeen := map[string]models.TeamMember{}
+	for i, t := range teams {
+		p := paths[i]
+		switch {
+		case t.TeamName == "":
+			add(p.team+"team_name", "must not be empty")
+		case len(t.TeamName) > maxNameLength:
+			add(p.team+"team_name", "must be at most %d characters", maxNameLength)
+		case seenTeams[t.TeamName]:
+			add(p.team+"team_name", "team %q is listed more than once", t.TeamName)
+		}
+		seenTeams[t.TeamName] = true
+
+		if t.ParentTeam != nil && *t.ParentTeam == t.TeamName {
+			add(p.team+"parent_team", "team cannot be its own parent")
+		}
+		if t.EscalationPolicy != nil && !validPolicy(*t.EscalationPolicy) {
+			add(p.team+"escalation_policy", "unknown escalation policy %q", *t.EscalationPolicy)
+		}
+
+		for j, m := range t.Members {
+			mp := p.members[j]
+			switch {
+			case m.UserId == "":
+				add(mp+"user_id", "must not be empty")
+			case len(m.UserId) > maxIDLength:
+				add(mp+"user_id", "must be at most %d characters", maxIDLength)
+			default:
+				other, ok := userTeam[m.UserId]
+				switch {
+				case !ok:
+					userTeam[m.UserId] = t.TeamName
+					firstSeen[m.UserId] = m
+				case other == t.TeamName:
+					add(mp+"user_id", "user %q is listed in team %q more than once", m.UserId, other)
+				case !shared:
+					add(mp+"user_id", "user %q is already listed in team %q", m.UserId, other)
+				case firstSeen[m.UserId] != m:
+					add(mp+"user_id", "user %q differs from its entry in team %q (username, is_active)", m.UserId, other)
+				}
+			}
+			if m.Username == "" {
+				add(mp+"username", "must not be empty")
+			} else if len(m.Username) > maxNameLength {
+				add(mp+"username", "must be at most %d characters", maxNameLength)
+			}
+		}
+	}
+
+	// Циклы среди команд файла (ссылки на команды вне файла проверяет сервис).
+	parents := map[string]string{}
+	for _, t := range teams {
+		if t.ParentTeam != nil {
+			parents[t.TeamName] = *t.ParentTeam
+		}
+	}
+	for i, t := range teams {
+		visited := map[string]bool{t.TeamName: true}
+		for cur, ok := parents[t.TeamName]; ok; cur, ok = parents[cur] {
+			if cur == t.TeamName {
+				if len(visited) > 1 {
+					add(paths[i].team+"parent_team", "parent_team forms a cycle")
+				}
+				break
+			}
+			if visited[cur] {
+				break
+			}
+			visited[cur] = true
+		}
+	}
+	return errs
+}
+
+func validPolicy(p models.EscalationPolicy) bool {
+	return slices.Contains([]models.EscalationPolicy{
+		models.EscalationPolicyNone,
+		models.EscalationPolicyParent,
+		models.EscalationPolicySiblings,
+		models.EscalationPolicySiblingsThenParent,
+	}, p)
+}
@@ -0,0 +1,114 @@
+package orgfile_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pull-request-api.com/internal/models"
+	"pull-request-api.com/internal/orgfile"
+)
+
+func TestParse_CSV(t *testing.T) {
+	data := "team_name,user_id,username,is_active,parent_team,escalation_policy\n" +
+		"payments,,,,,\n" +
+		"core,u1,Alice,,payments,siblings\n" +
+		"core,u2,Bob,false,,\n"
+
+	teams, err := orgfile.Parse([]byte(data), orgfile.CSV)
+	require.NoError(t, err)
+	require.Len(t, teams, 2)
+
+	assert.Equal(t, "payments", teams[0].TeamName)
+	assert.Empty(t, teams[0].Members)
+
+	core := teams[1]
+	require.NotNil(t, core.ParentTeam)
+	assert.Equal(t, "payments", *core.ParentTeam)
+	assert.Equal(t, models.EscalationPolicySiblings, *core.EscalationPolicy)
+	assert.Equal(t, []models.TeamMember{
+		{UserId: "u1", Username: "Alice", IsActive: true},
+		{UserId: "u2", Username: "Bob", IsActive: false},
+	}, core.Members)
+}
+
+func TestParse_YAML(t *testing.T) {
+	data := `
+teams:
+  - team_name: core
+    escalation_policy: parent
+    members:
+      - user_id: 42
+        username: Alice
+      - user_id: u2
+        username: Bob
+        is_active: false
+`
+	teams, err := orgfile.Parse([]byte(data), orgfile.YAML)
+	require.NoError(t, err)
+	require.Len(t, teams, 1)
+	assert.Nil(t, teams[0].ParentTeam)
+	assert.Equal(t, []models.TeamMember{
+		{UserId: "42", Username: "Alice", IsActive: true},
+		{UserId: "u2", Username: "Bob", IsActive: false},
+	}, teams[0].Members)
+}
+
+func TestParse_ReportsAllErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		format orgfile.Format
+		data   string
+		fields []string
+	}{
+		{"csv unknown column", orgfile.CSV, "team_name,user_id,username,email\n", []string{"1.email"}},
+		{"csv duplicate user", orgfile.CSV, "team_name,user_id,username\na,u1,A\nb,u1,A\nb,u2,\n", []string{"3.user_id", "4.username"}},
+		{"csv conflicting settings", orgfile.CSV, "team_name,parent_team\na,x\na,y\n", []string{"3.parent_team"}},
+		{"csv bad flag", orgfile.CSV, "team_name,user_id,username,is_active\na,u1,A,maybe\n", []string{"2.is_active"}},
+		{"yaml unknown fields", orgfile.YAML, "teams:\n  - team_name: a\n    lead: u1\n    members:\n      - {user_id: u1, username: A, email: x}\n", []string{"teams.0.lead", "teams.0.members.0.email"}},
+		{"yaml duplicate team", orgfile.YAML, "teams:\n  - team_name: a\n  - team_name: a\n", []string{"teams.1.team_name"}},
+		{"yaml policy", orgfile.YAML, "teams:\n  - team_name: a\n    escalation_policy: everyone\n", []string{"teams.0.escalation_policy"}},
+		{"yaml cycle", orgfile.YAML, "teams:\n  - {team_name: a, parent_team: b}\n  - {team_name: b, parent_team: a}\n", []string{"teams.0.parent_team", "teams.1.parent_team"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := orgfile.Parse([]byte(tc.data), tc.format)
+			var verr *orgfile.ValidationError
+			require.True(t, errors.As(err, &verr), "error: %v", err)
+
+			var fields []string
+			for _, d := range verr.Details {
+				fields = append(fields, d.Field)
+			}
+			assert.ElementsMatch(t, tc.fields, fields)
+		})
+	}
+}
+
+func TestParseDesired_SharedMembers(t *testing.T) {
+	data := "team_name,user_id,username\na,u1,A\nb,u1,A\nb,u2,B\n"
+	teams, err := orgfile.ParseDesired([]byte(data), orgfile.CSV)
+	require.NoError(t, err)
+	require.Len(t, teams, 2)
+	assert.Equal(t, "u1", teams[1].Members[0].UserId)
+
+	_, err = orgfile.Parse([]byte(data), orgfile.CSV)
+	assert.Error(t, err)
+
+	_, err = orgfile.ParseDesired([]byte("team_name,user_id,username\na,u1,A\nb,u1,Other\n"), orgfile.CSV)
+	var verr *orgfile.ValidationError
+	require.True(t, errors.As(err, &verr))
+	assert.Equal(t, "3.user_id", verr.Details[0].Field)
+}
+
+func TestFormatFromContentType(t *testing.T) {
+	f, ok := orgfile.FormatFromContentType("text/csv; charset=utf-8")
+	assert.True(t, ok)
+	assert.Equal(t, orgfile.CSV, f)
+
+	_, ok = orgfile.FormatFromContentType("application/json")
+	assert.False(t, ok)
+}
@@ -0,0 +1,66 @@
+package orgfile
+
+import (
+	"fmt"
+	"io"
+
+	"pull-request-api.com/internal/models"
+)
+
+// WritePlan печатает список изменений в человекочитаемом виде, по строке на
+// изменение: «+» — создание, «~» — изменение поля (старое -> новое значение),
+// «-» — удаление. Переназначенные ревью печатаются после изменений.
+func WritePlan(w io.Writer, plan *models.OrgPlan) error {
+	for _, c := range plan.Changes {
+		var err error
+		switch c.Action {
+		case models.OrgChangeActionCreateTeam:
+			_, err = fmt.Fprintf(w, "+ team %s\n", c.TeamName)
+		case models.OrgChangeActionUpdateTeam:
+			_, err = fmt.Fprintf(w, "~ team %s: %s %s -> %s\n", c.TeamName, deref(c.Field), deref(c.Old), deref(c.New))
+		case models.OrgChangeActionCreateUser:
+			_, err = fmt.Fprintf(w, "+ user %s (%s)\n", deref(c.UserId), c.TeamName)
+		case models.OrgChangeActionAddMember:
+			_, err = fmt.Fprintf(w, "+ member %s -> %s\n", deref(c.UserId), c.TeamName)
+		case models.OrgChangeActionUpdateUser:
+			_, err = fmt.Fprintf(w, "~ user %s: %s %s -> %s\n", deref(c.UserId), deref(c.Field), deref(c.Old), deref(c.New))
+		case models.OrgChangeActionMoveUser:
+			_, err = fmt.Fprintf(w, "~ user %s: team %s -> %s\n", deref(c.UserId), deref(c.Old), deref(c.New))
+		case models.OrgChangeActionRemoveMember:
+			_, err = fmt.Fprintf(w, "- member %s -> %s\n", deref(c.UserId), c.TeamName)
+		case models.OrgChangeActionDeactivateUser:
+			_, err = fmt.Fprintf(w, "- user %s (deactivate)\n", deref(c.UserId))
+		case models.OrgChangeActionDeleteUser:
+			_, err = fmt.Fprintf(w, "- user %s (delete)\n", deref(c.UserId))
+		case models.OrgChangeActionDeleteTeam:
+			_, err = fmt.Fprintf(w, "- team %s\n", c.TeamName)
+		default:
+			_, err = fmt.Fprintf(w, "? %s %s %s\n", c.Action, c.TeamName, deref(c.UserId))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if plan.Reviews != nil {
+		for _, r := range *plan.Reviews {
+			if _, err := fmt.Fprintf(w, "review %s: %s -> %s\n", r.PullRequestId, r.OldUserId, deref(r.NewUserId)); err != nil {
+				return err
+			}
+		}
+	}
+
+	status := "applied"
+	if !plan.Applied {
+		status = "dry run, nothing applied"
+	}
+	_, err := fmt.Fprintf(w, "%d change(s), %s\n", len(plan.Changes), status)
+	return err
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return "<none>"
+	}
+	return *s
+}
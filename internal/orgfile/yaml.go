@@ -0,0 +1,136 @@
+package orgfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"pull-request-api.com/internal/models"
+)
+
+// parseYAML разбирает файл вида
+//
+//	teams:
+//	  - team_name: payments-core
+//	    parent_team: payments
+//	    escalation_policy: siblings
+//	    members:
+//	      - user_id: u1
+//	        username: Alice
+//	        is_active: true   # по умолчанию true
+func parseYAML(data []byte) ([]models.Team, []teamPaths, []models.FieldError) {
+	var doc map[string]any
+	err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&doc)
+	if errors.Is(err, io.EOF) {
+		return nil, nil, []models.FieldError{{Field: "teams", Message: "is required"}}
+	} else if err != nil {
+		return nil, nil, []models.FieldError{{Field: "body", Message: err.Error()}}
+	}
+
+	var errs []models.FieldError
+	add := func(field, format string, args ...any) {
+		errs = append(errs, models.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	checkKeys(doc, "", []string{"teams"}, add)
+	rawTeams, ok := doc["teams"].([]any)
+	if !ok {
+		add("teams", "must be a list")
+		return nil, nil, errs
+	}
+
+	var (
+		teams []models.Team
+		paths []teamPaths
+	)
+	for i, raw := range rawTeams {
+		prefix := "teams." + strconv.Itoa(i) + "."
+		obj, ok := raw.(map[string]any)
+		if !ok {
+			add(prefix[:len(prefix)-1], "must be an object")
+			continue
+		}
+		checkKeys(obj, prefix, []string{"team_name", "parent_team", "escalation_policy", "members"}, add)
+
+		team := models.Team{
+			TeamName: str(obj, prefix, "team_name", add),
+			Members:  []models.TeamMember{},
+		}
+		if v, ok := obj["parent_team"]; ok && v != nil {
+			parent := str(obj, prefix, "parent_team", add)
+			team.ParentTeam = &parent
+		}
+		if _, ok := obj["escalation_policy"]; ok {
+			policy := models.EscalationPolicy(str(obj, prefix, "escalation_policy", add))
+			team.EscalationPolicy = &policy
+		}
+
+		tp := teamPaths{team: prefix}
+		rawMembers, _ := obj["members"].([]any)
+		if v, ok := obj["members"]; ok && v != nil && rawMembers == nil {
+			add(prefix+"members", "must be a list")
+		}
+		for j, rm := range rawMembers {
+			mp := prefix + "members." + strconv.Itoa(j) + "."
+			m, ok := rm.(map[string]any)
+			if !ok {
+				add(mp[:len(mp)-1], "must be an object")
+				continue
+			}
+			checkKeys(m, mp, []string{"user_id", "username", "is_active"}, add)
+
+			member := models.TeamMember{
+				UserId:   str(m, mp, "user_id", add),
+				Username: str(m, mp, "username", add),
+				IsActive: true,
+			}
+			if v, ok := m["is_active"]; ok {
+				active, isBool := v.(bool)
+				if !isBool {
+					add(mp+"is_active", "must be a boolean")
+				}
+				member.IsActive = active
+			}
+			team.Members = append(team.Members, member)
+			tp.members = append(tp.members, mp)
+		}
+
+		teams = append(teams, team)
+		paths = append(paths, tp)
+	}
+	return teams, paths, errs
+}
+
+func checkKeys(obj map[string]any, prefix string, allowed []string, add func(field, format string, args ...any)) {
+	var unknown []string
+	for k := range obj {
+		if !slices.Contains(allowed, k) {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	for _, k := range unknown {
+		add(prefix+k, "unknown field")
+	}
+}
+
+// str читает строковое поле; числа (например, user_id: 42) принимаются как строки.
+func str(obj map[string]any, prefix, key string, add func(field, format string, args ...any)) string {
+	switch v := obj[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int, int64, uint64, float64:
+		return fmt.Sprint(v)
+	default:
+		add(prefix+key, "must be a string")
+		return ""
+	}
+}
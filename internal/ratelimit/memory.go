@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// refill — время полного заполнения при лимите последнего Take.
+	refill time.Duration
+}
+
+// MemoryStore хранит корзины в памяти процесса. Подходит для одной реплики.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		s.buckets[key] = b
+	}
+
+	var res Result
+	b.tokens, res = take(b.tokens, now.Sub(b.updated), limit)
+	b.updated = now
+	b.refill = limit.RefillTime()
+	return res, nil
+}
+
+// sweep раз в минуту удаляет корзины, простоявшие дольше времени полного
+// заполнения: такая корзина ничем не отличается от новой. Фиксированный срок
+// не годится — при медленном лимите корзина за него не успевает заполниться.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if now.Sub(b.updated) >= b.refill {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_TokenBucket(t *testing.T) {
+	now := time.Date(2025, 11, 22, 12, 0, 0, 0, time.UTC)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	limit := Limit{Rate: 2, Burst: 3}
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		res, err := s.Take(ctx, "ip:1", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed, "запрос %d укладывается в burst", i)
+	}
+
+	res, _ := s.Take(ctx, "ip:1", limit)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+
+	// Другой клиент не затронут.
+	res, _ = s.Take(ctx, "ip:2", limit)
+	assert.True(t, res.Allowed)
+
+	now = now.Add(500 * time.Millisecond)
+	res, _ = s.Take(ctx, "ip:1", limit)
+	assert.True(t, res.Allowed, "за 0.5с при rate=2 появился один токен")
+}
+
+func TestMemoryStore_SweepKeepsSlowBuckets(t *testing.T) {
+	now := time.Date(2025, 11, 22, 12, 0, 0, 0, time.UTC)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	// Один запрос в два часа: корзина заполняется за 2ч.
+	limit := Limit{Rate: 1.0 / 7200, Burst: 1}
+	ctx := context.Background()
+
+	res, _ := s.Take(ctx, "ip:1", limit)
+	require.True(t, res.Allowed)
+
+	now = now.Add(90 * time.Minute)
+	res, _ = s.Take(ctx, "ip:1", limit)
+	assert.False(t, res.Allowed, "после часа простоя корзина ещё не заполнилась")
+
+	now = now.Add(2 * time.Hour)
+	res, _ = s.Take(ctx, "ip:1", limit)
+	assert.True(t, res.Allowed)
+}
+
+func TestParseLimit(t *testing.T) {
+	l, err := ParseLimit("0.5/10")
+	require.NoError(t, err)
+	assert.Equal(t, Limit{Rate: 0.5, Burst: 10}, l)
+
+	l, err = ParseLimit("")
+	require.NoError(t, err)
+	assert.False(t, l.Enabled())
+
+	for _, bad := range []string{"5", "a/1", "1/b", "-1/3"} {
+		_, err := ParseLimit(bad)
+		assert.Error(t, err, bad)
+	}
+}
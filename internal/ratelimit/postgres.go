@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// PostgresStore хранит корзины в таблице rate_limit_buckets, поэтому лимит
+// общий для всех реплик. Время берётся из БД, чтобы не зависеть от часов реплик.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Result{}, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO rate_limit_buckets (bucket_key, tokens, updated_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP) ON CONFLICT (bucket_key) DO NOTHING`, key, limit.Burst)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var tokens, elapsed float64
+	err = tx.QueryRowContext(ctx, `
+		SELECT tokens, EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - updated_at))
+		FROM rate_limit_buckets WHERE bucket_key = $1 FOR UPDATE
+	`, key).Scan(&tokens, &elapsed)
+	if err != nil {
+		return Result{}, err
+	}
+
+	tokens, res := take(tokens, time.Duration(elapsed*float64(time.Second)), limit)
+
+	_, err = tx.ExecContext(ctx, `UPDATE rate_limit_buckets SET tokens = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE bucket_key = $2`, tokens, key)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Result{}, err
+	}
+	return res, nil
+}
+
+// Cleanup удаляет корзины, которые не трогали дольше olderThan.
+func (s *PostgresStore) Cleanup(ctx context.Context, olderThan time.Duration) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM rate_limit_buckets
+		WHERE updated_at < CURRENT_TIMESTAMP - make_interval(secs => $1)`, olderThan.Seconds())
+	return err
+}
@@ -0,0 +1,78 @@
+// Package ratelimit реализует token bucket с хранением состояния в памяти
+// процесса или в Postgres (общий лимит для нескольких реплик).
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit — скорость пополнения (токенов в секунду) и ёмкость корзины.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// RefillTime — за сколько пустая корзина заполняется полностью. Корзина,
+// простоявшая дольше, ничем не отличается от новой.
+func (l Limit) RefillTime() time.Duration {
+	if !l.Enabled() {
+		return 0
+	}
+	return time.Duration(float64(l.Burst) / l.Rate * float64(time.Second))
+}
+
+type Result struct {
+	Allowed bool
+	// RetryAfter — через сколько появится следующий токен (только при Allowed == false).
+	RetryAfter time.Duration
+}
+
+type Store interface {
+	// Take пытается забрать один токен из корзины key.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// take пополняет корзину за прошедшее время и пытается забрать токен.
+// Возвращает новое количество токенов.
+func take(tokens float64, elapsed time.Duration, limit Limit) (float64, Result) {
+	if elapsed > 0 {
+		tokens = math.Min(float64(limit.Burst), tokens+elapsed.Seconds()*limit.Rate)
+	}
+	if tokens >= 1 {
+		return tokens - 1, Result{Allowed: true}
+	}
+
+	wait := time.Duration((1 - tokens) / limit.Rate * float64(time.Second))
+	return tokens, Result{RetryAfter: wait}
+}
+
+// ParseLimit разбирает лимит вида "RATE/BURST", например "5/10" — 5 запросов
+// в секунду с всплеском до 10. Пустая строка означает «без лимита».
+func ParseLimit(s string) (Limit, error) {
+	if s == "" {
+		return Limit{}, nil
+	}
+
+	rateStr, burstStr, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid limit %q: want RATE/BURST", s)
+	}
+	rate, err := strconv.ParseFloat(rateStr, 64)
+	if err != nil || rate < 0 {
+		return Limit{}, fmt.Errorf("invalid rate in %q", s)
+	}
+	burst, err := strconv.Atoi(burstStr)
+	if err != nil || burst < 0 {
+		return Limit{}, fmt.Errorf("invalid burst in %q", s)
+	}
+	return Limit{Rate: rate, Burst: burst}, nil
+}
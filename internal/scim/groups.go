@@ -0,0 +1,251 @@
+package scim
+
+import (
+	"cmp"
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/chi/v5"
+
+	"pull-request-api.com/internal/models"
+)
+
+// Group — ресурс Group. id и displayName — это team_name, members — все
+// участники команды (включая тех, для кого она дополнительная).
+type Group struct {
+	Schemas     []string    `json:"schemas"`
+	Id          string      `json:"id"`
+	DisplayName string      `json:"displayName"`
+	Members     []MemberRef `json:"members,omitempty"`
+	Meta        Meta        `json:"meta"`
+}
+
+type groupRequest struct {
+	DisplayName string      `json:"displayName"`
+	Members     []MemberRef `json:"members"`
+}
+
+func (h *Handler) toGroup(t *models.Team, withMembers bool) Group {
+	g := Group{
+		Schemas:     []string{schemaGroup},
+		Id:          t.TeamName,
+		DisplayName: t.TeamName,
+		Meta:        Meta{ResourceType: "Group", Location: h.basePath + "/Groups/" + t.TeamName},
+	}
+	if withMembers {
+		g.Members = []MemberRef{}
+		for _, m := range t.Members {
+			g.Members = append(g.Members, MemberRef{Value: m.UserId, Display: m.Username, Ref: h.basePath + "/Users/" + m.UserId})
+		}
+		slices.SortFunc(g.Members, func(a, b MemberRef) int { return cmp.Compare(a.Value, b.Value) })
+	}
+	return g
+}
+
+// withMembers: провайдеры запрашивают группы без участников через
+// excludedAttributes=members, чтобы не тянуть большие списки.
+func withMembers(r *http.Request) bool {
+	return r.URL.Query().Get("excludedAttributes") != "members"
+}
+
+func memberIDs(refs []MemberRef) []string {
+	ids := make([]string, 0, len(refs))
+	for _, m := range refs {
+		ids = append(ids, m.Value)
+	}
+	return ids
+}
+
+func (h *Handler) listGroups(w http.ResponseWriter, r *http.Request) {
+	start, count, err := page(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	attr, value, err := parseFilter(r.URL.Query().Get("filter"), "displayName", "id")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	teams, err := h.b.ListTeams(r.Context())
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	groups := []Group{}
+	for i := range teams {
+		if attr != "" && teams[i].TeamName != value {
+			continue
+		}
+		groups = append(groups, h.toGroup(&teams[i], withMembers(r)))
+	}
+	sendJSON(w, http.StatusOK, listResponse(groups, start, count))
+}
+
+func (h *Handler) createGroup(w http.ResponseWriter, r *http.Request) {
+	var req groupRequest
+	if err := decode(w, r, &req); err != nil {
+		handleError(w, err)
+		return
+	}
+	if req.DisplayName == "" {
+		handleError(w, invalidValue("displayName is required"))
+		return
+	}
+
+	team, err := h.b.CreateTeam(r.Context(), req.DisplayName, memberIDs(req.Members))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	res := h.toGroup(team, true)
+	w.Header().Set("Location", res.Meta.Location)
+	sendJSON(w, http.StatusCreated, res)
+}
+
+func (h *Handler) getGroup(w http.ResponseWriter, r *http.Request) {
+	team, err := h.b.GetTeam(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, h.toGroup(team, withMembers(r)))
+}
+
+// patchGroup переименовывает команду (displayName) и меняет состав
+// участников. Открытые ревью исключённых участников на PR команды передаются
+// другим её участникам.
+func (h *Handler) patchGroup(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "id")
+	var req PatchRequest
+	if err := decode(w, r, &req); err != nil {
+		handleError(w, err)
+		return
+	}
+	changes, err := req.changes()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	team, err := h.b.GetTeam(r.Context(), name)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	current := make([]string, 0, len(team.Members))
+	for _, m := range team.Members {
+		current = append(current, m.UserId)
+	}
+
+	var (
+		newName     = name
+		add, remove []string
+	)
+	for _, c := range changes {
+		switch c.attr {
+		case "displayname":
+			if c.op == "remove" {
+				handleError(w, &badRequest{scimType: "mutability", detail: "displayName cannot be removed"})
+				return
+			}
+			if newName, err = stringValue(c.value); err != nil {
+				handleError(w, err)
+				return
+			}
+		case "members":
+			var refs []MemberRef
+			if len(c.value) > 0 {
+				if err := json.Unmarshal(c.value, &refs); err != nil {
+					handleError(w, invalidValue("members must be a list of {value}"))
+					return
+				}
+			}
+			ids := memberIDs(refs)
+			switch {
+			case c.op == "add":
+				add = append(add, ids...)
+			case c.op == "remove" && c.filter != "":
+				remove = append(remove, c.filter)
+			case c.op == "remove" && len(ids) > 0:
+				remove = append(remove, ids...)
+			case c.op == "remove":
+				remove = append(remove, current...)
+			default: // replace
+				remove = append(remove, current...)
+				add = append(add, ids...)
+			}
+		}
+	}
+
+	// Пользователь, которого удаляют и тут же добавляют (replace), остаётся в
+	// команде без переназначения его ревью.
+	var toRemove []string
+	for _, uid := range remove {
+		if !slices.Contains(add, uid) && slices.Contains(current, uid) && !slices.Contains(toRemove, uid) {
+			toRemove = append(toRemove, uid)
+		}
+	}
+	var toAdd []string
+	for _, uid := range add {
+		if !slices.Contains(current, uid) && !slices.Contains(toAdd, uid) {
+			toAdd = append(toAdd, uid)
+		}
+	}
+
+	ctx := r.Context()
+	if len(toRemove) > 0 {
+		if _, err := h.b.RemoveTeamMembers(ctx, name, toRemove, models.ReviewPolicyReassign); err != nil {
+			handleError(w, err)
+			return
+		}
+	}
+	if len(toAdd) > 0 {
+		if _, err := h.b.AddTeamMembers(ctx, name, toAdd); err != nil {
+			handleError(w, err)
+			return
+		}
+	}
+	if newName != name {
+		if _, err := h.b.RenameTeam(ctx, models.PostTeamRenameJSONRequestBody{TeamName: name, NewTeamName: newName}); err != nil {
+			handleError(w, err)
+			return
+		}
+	}
+
+	if team, err = h.b.GetTeam(ctx, newName); err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, h.toGroup(team, true))
+}
+
+// deleteGroup исключает всех участников (с переназначением их ревью на PR
+// команды) и удаляет команду. Пользователи при этом не удаляются.
+func (h *Handler) deleteGroup(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "id")
+	team, err := h.b.GetTeam(r.Context(), name)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if len(team.Members) > 0 {
+		ids := make([]string, 0, len(team.Members))
+		for _, m := range team.Members {
+			ids = append(ids, m.UserId)
+		}
+		if _, err := h.b.RemoveTeamMembers(r.Context(), name, ids, models.ReviewPolicyReassign); err != nil {
+			handleError(w, err)
+			return
+		}
+	}
+	if _, err := h.b.DeleteTeam(r.Context(), models.PostTeamDeleteJSONRequestBody{TeamName: name}); err != nil {
+		handleError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
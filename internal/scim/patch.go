@@ -0,0 +1,128 @@
+package scim
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// eqFilter — фильтр вида `attr eq "value"`; другие операторы сервис не поддерживает.
+var eqFilter = regexp.MustCompile(`(?i)^\s*([a-z][a-z0-9.]*)\s+eq\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+// parseFilter разбирает filter из запроса поиска. Пустой filter — (“”, “”, nil);
+// атрибут должен быть одним из allowed (без учёта регистра, RFC 7643 2.1).
+func parseFilter(filter string, allowed ...string) (attr, value string, err error) {
+	if filter == "" {
+		return "", "", nil
+	}
+	m := eqFilter.FindStringSubmatch(filter)
+	if m == nil {
+		return "", "", &badRequest{scimType: "invalidFilter", detail: `only filters of the form 'attribute eq "value"' are supported`}
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(m[1], a) {
+			value, err := strconv.Unquote(`"` + m[2] + `"`)
+			if err != nil {
+				return "", "", &badRequest{scimType: "invalidFilter", detail: "invalid filter value"}
+			}
+			return a, value, nil
+		}
+	}
+	return "", "", &badRequest{scimType: "invalidFilter", detail: "filtering by " + m[1] + " is not supported"}
+}
+
+// PatchRequest — тело PATCH (RFC 7644, раздел 3.5.2).
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation — одна операция PATCH. Op сравнивается без учёта регистра:
+// часть провайдеров присылает "Replace".
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// patchChange — операция над одним атрибутом. filter — значение из пути вида
+// members[value eq "u1"].
+type patchChange struct {
+	op     string
+	attr   string
+	filter string
+	value  json.RawMessage
+}
+
+var valuePath = regexp.MustCompile(`(?i)^([a-z][a-z0-9]*)\[\s*value\s+eq\s+"((?:[^"\\]|\\.)*)"\s*\]$`)
+
+// changes раскладывает операции на изменения отдельных атрибутов. Операция
+// без path со значением-объектом эквивалентна операциям над каждым его ключом.
+func (p PatchRequest) changes() ([]patchChange, error) {
+	if len(p.Schemas) > 0 && !slices.Contains(p.Schemas, schemaPatchOp) {
+		return nil, &badRequest{scimType: "invalidSyntax", detail: "schemas must contain " + schemaPatchOp}
+	}
+	if len(p.Operations) == 0 {
+		return nil, invalidValue("Operations must not be empty")
+	}
+
+	var out []patchChange
+	for _, o := range p.Operations {
+		op := strings.ToLower(o.Op)
+		switch op {
+		case "add", "replace", "remove":
+		default:
+			return nil, invalidValue("unknown op %q", o.Op)
+		}
+
+		if o.Path == "" {
+			if op == "remove" {
+				return nil, &badRequest{scimType: "noTarget", detail: "remove requires a path"}
+			}
+			var attrs map[string]json.RawMessage
+			if err := json.Unmarshal(o.Value, &attrs); err != nil {
+				return nil, invalidValue("value must be an object when path is omitted")
+			}
+			for attr, v := range attrs {
+				out = append(out, patchChange{op: op, attr: strings.ToLower(attr), value: v})
+			}
+			continue
+		}
+
+		c := patchChange{op: op, attr: strings.ToLower(o.Path), value: o.Value}
+		if m := valuePath.FindStringSubmatch(o.Path); m != nil {
+			filter, err := strconv.Unquote(`"` + m[2] + `"`)
+			if err != nil {
+				return nil, &badRequest{scimType: "invalidPath", detail: "invalid path " + o.Path}
+			}
+			c.attr, c.filter = strings.ToLower(m[1]), filter
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// boolValue разбирает булево значение; некоторые провайдеры присылают его строкой.
+func boolValue(raw json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		if b, err := strconv.ParseBool(strings.ToLower(s)); err == nil {
+			return b, nil
+		}
+	}
+	return false, invalidValue("expected a boolean, got %s", raw)
+}
+
+func stringValue(raw json.RawMessage) (string, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil || s == "" {
+		return "", invalidValue("expected a non-empty string, got %s", raw)
+	}
+	return s, nil
+}
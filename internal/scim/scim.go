@@ -0,0 +1,213 @@
+// Package scim реализует провижининг пользователей и команд по SCIM 2.0
+// (RFC 7643, RFC 7644): ресурсы /Users и /Groups отображаются на таблицы users
+// и teams через методы сервиса.
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"pull-request-api.com/internal/models"
+	"pull-request-api.com/internal/service"
+)
+
+const (
+	schemaUser       = "urn:ietf:params:scim:schemas:core:2.0:User"
+	schemaGroup      = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	schemaList       = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	schemaPatchOp    = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	schemaError      = "urn:ietf:params:scim:api:messages:2.0:Error"
+	schemaSPConfig   = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	contentType      = "application/scim+json"
+	defaultPageSize  = 100
+	maxPageSize      = 200
+	maxRequestBodyMB = 1
+)
+
+// Backend — операции сервиса, на которые отображаются SCIM-запросы.
+// Реализуется *service.Service.
+type Backend interface {
+	GetUser(ctx context.Context, userID string) (*models.User, error)
+	ListUsersRange(ctx context.Context, offset, limit int) ([]models.User, int, error)
+	CreateUser(ctx context.Context, user models.User) (*models.User, error)
+	PatchUser(ctx context.Context, userID string, username *string, isActive *bool) (*models.User, error)
+	DeleteUser(ctx context.Context, req models.PostUsersDeleteJSONRequestBody) (*models.UserDeleteResult, error)
+
+	GetTeam(ctx context.Context, teamName string) (*models.Team, error)
+	ListTeams(ctx context.Context) ([]models.Team, error)
+	CreateTeam(ctx context.Context, teamName string, userIDs []string) (*models.Team, error)
+	RenameTeam(ctx context.Context, req models.PostTeamRenameJSONRequestBody) (*models.Team, error)
+	AddTeamMembers(ctx context.Context, teamName string, userIDs []string) (*models.Team, error)
+	RemoveTeamMembers(ctx context.Context, teamName string, userIDs []string, policy models.ReviewPolicy) ([]models.ReviewReassignment, error)
+	DeleteTeam(ctx context.Context, req models.PostTeamDeleteJSONRequestBody) (*models.TeamDeleteResult, error)
+}
+
+// Handler обслуживает SCIM-эндпоинты. basePath — префикс, под которым он
+// смонтирован (для meta.location).
+type Handler struct {
+	b        Backend
+	basePath string
+}
+
+// NewHandler возвращает роутер SCIM, который монтируется под basePath.
+func NewHandler(b Backend, basePath string) http.Handler {
+	h := &Handler{b: b, basePath: basePath}
+
+	r := chi.NewRouter()
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		sendError(w, http.StatusNotFound, "", "Resource not found")
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		sendError(w, http.StatusMethodNotAllowed, "", "Method not allowed")
+	})
+	r.Get("/ServiceProviderConfig", h.serviceProviderConfig)
+
+	r.Get("/Users", h.listUsers)
+	r.Post("/Users", h.createUser)
+	r.Get("/Users/{id}", h.getUser)
+	r.Patch("/Users/{id}", h.patchUser)
+	r.Delete("/Users/{id}", h.deleteUser)
+
+	r.Get("/Groups", h.listGroups)
+	r.Post("/Groups", h.createGroup)
+	r.Get("/Groups/{id}", h.getGroup)
+	r.Patch("/Groups/{id}", h.patchGroup)
+	r.Delete("/Groups/{id}", h.deleteGroup)
+	return r
+}
+
+// Error — ответ об ошибке по RFC 7644, раздел 3.12.
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+// ListResponse — страница результатов поиска (RFC 7644, раздел 3.4.2).
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []any    `json:"Resources"`
+}
+
+// Meta — служебные атрибуты ресурса.
+type Meta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location"`
+}
+
+func sendJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func sendError(w http.ResponseWriter, status int, scimType, detail string) {
+	sendJSON(w, status, Error{
+		Schemas:  []string{schemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+// badRequest — ошибка в запросе клиента с scimType из RFC 7644 (таблица 9).
+type badRequest struct {
+	scimType string
+	detail   string
+}
+
+func (e *badRequest) Error() string { return e.detail }
+
+func invalidValue(format string, args ...any) error {
+	return &badRequest{scimType: "invalidValue", detail: fmt.Sprintf(format, args...)}
+}
+
+func handleError(w http.ResponseWriter, err error) {
+	var bad *badRequest
+	switch {
+	case errors.As(err, &bad):
+		sendError(w, http.StatusBadRequest, bad.scimType, bad.detail)
+	case errors.Is(err, service.ErrNotFound):
+		sendError(w, http.StatusNotFound, "", err.Error())
+	case errors.Is(err, service.ErrConflict):
+		sendError(w, http.StatusConflict, "uniqueness", "Resource already exists")
+	case errors.Is(err, service.ErrInvalidInput):
+		sendError(w, http.StatusBadRequest, "invalidValue", err.Error())
+	case errors.Is(err, service.ErrPrecondition):
+		sendError(w, http.StatusConflict, "", err.Error())
+	default:
+		sendError(w, http.StatusInternalServerError, "", "Internal server error")
+	}
+}
+
+// decode разбирает тело запроса. Неизвестные атрибуты допускаются: провайдеры
+// присылают расширения схемы, которые сервис не хранит.
+func decode(w http.ResponseWriter, r *http.Request, dst any) error {
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodyMB<<20)).Decode(dst)
+	if err != nil {
+		return invalidValue("Invalid body: %v", err)
+	}
+	return nil
+}
+
+// page разбирает startIndex и count (RFC 7644, раздел 3.4.2.4).
+func page(r *http.Request) (start, count int, err error) {
+	start, count = 1, defaultPageSize
+	if v := r.URL.Query().Get("startIndex"); v != "" {
+		if start, err = strconv.Atoi(v); err != nil {
+			return 0, 0, invalidValue("startIndex must be an integer")
+		}
+		start = max(start, 1)
+	}
+	if v := r.URL.Query().Get("count"); v != "" {
+		if count, err = strconv.Atoi(v); err != nil {
+			return 0, 0, invalidValue("count must be an integer")
+		}
+		count = min(max(count, 0), maxPageSize)
+	}
+	return start, count, nil
+}
+
+// listResponse вырезает страницу из всех найденных ресурсов.
+func listResponse[T any](all []T, start, count int) ListResponse {
+	resources := []any{}
+	for i := start - 1; i < len(all) && len(resources) < count; i++ {
+		resources = append(resources, all[i])
+	}
+	return ListResponse{
+		Schemas:      []string{schemaList},
+		TotalResults: len(all),
+		StartIndex:   start,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}
+}
+
+func (h *Handler) serviceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	supported := func(ok bool) map[string]bool { return map[string]bool{"supported": ok} }
+	sendJSON(w, http.StatusOK, map[string]any{
+		"schemas":        []string{schemaSPConfig},
+		"patch":          supported(true),
+		"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]any{"supported": true, "maxResults": maxPageSize},
+		"changePassword": supported(false),
+		"sort":           supported(false),
+		"etag":           supported(false),
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "OAuth Bearer Token",
+			"description": "JWT в заголовке Authorization, как для остального API",
+		}},
+		"meta": Meta{ResourceType: "ServiceProviderConfig", Location: h.basePath + "/ServiceProviderConfig"},
+	})
+}
@@ -0,0 +1,286 @@
+package scim_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pull-request-api.com/internal/models"
+	"pull-request-api.com/internal/scim"
+	"pull-request-api.com/internal/service"
+)
+
+// fixture — записанный обмен с провайдером: запросы выполняются по порядку,
+// ответ сравнивается со статусом и телом (если оно задано).
+type fixture struct {
+	Description string `json:"description"`
+	Steps       []struct {
+		Request struct {
+			Method string          `json:"method"`
+			Path   string          `json:"path"`
+			Body   json.RawMessage `json:"body"`
+		} `json:"request"`
+		Response struct {
+			Status int             `json:"status"`
+			Body   json.RawMessage `json:"body"`
+		} `json:"response"`
+	} `json:"steps"`
+}
+
+func TestConformance_Fixtures(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			require.NoError(t, err)
+			var fx fixture
+			require.NoError(t, json.Unmarshal(data, &fx))
+
+			r := chi.NewRouter()
+			r.Mount("/scim/v2", scim.NewHandler(newFakeBackend(), "/scim/v2"))
+
+			for i, step := range fx.Steps {
+				req := httptest.NewRequest(step.Request.Method, step.Request.Path, bytes.NewReader(step.Request.Body))
+				req.Header.Set("Content-Type", "application/scim+json")
+				rec := httptest.NewRecorder()
+				r.ServeHTTP(rec, req)
+
+				name := fmt.Sprintf("step %d: %s %s", i, step.Request.Method, step.Request.Path)
+				require.Equal(t, step.Response.Status, rec.Code, "%s\n%s", name, rec.Body.String())
+				if len(step.Response.Body) > 0 {
+					assert.JSONEq(t, string(step.Response.Body), rec.Body.String(), name)
+				}
+			}
+		})
+	}
+}
+
+// fakeBackend повторяет семантику service.Service в памяти.
+type fakeBackend struct {
+	users map[string]*models.User
+	teams map[string][]string
+
+	// ranges и patches считают вызовы ListUsersRange и PatchUser.
+	ranges, patches int
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{users: map[string]*models.User{}, teams: map[string][]string{}}
+}
+
+func (f *fakeBackend) GetUser(_ context.Context, userID string) (*models.User, error) {
+	u, ok := f.users[userID]
+	if !ok {
+		return nil, service.ErrNotFound
+	}
+	res := *u
+	res.Teams = []string{}
+	for name, members := range f.teams {
+		if slices.Contains(members, userID) {
+			res.Teams = append(res.Teams, name)
+		}
+	}
+	sort.Strings(res.Teams)
+	return &res, nil
+}
+
+func (f *fakeBackend) ListUsersRange(ctx context.Context, offset, limit int) ([]models.User, int, error) {
+	f.ranges++
+	var ids []string
+	for id := range f.users {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	users := []models.User{}
+	for i := offset; i < len(ids) && len(users) < limit; i++ {
+		u, _ := f.GetUser(ctx, ids[i])
+		users = append(users, *u)
+	}
+	return users, len(ids), nil
+}
+
+func (f *fakeBackend) CreateUser(ctx context.Context, user models.User) (*models.User, error) {
+	if _, ok := f.users[user.UserId]; ok {
+		return nil, service.ErrConflict
+	}
+	f.users[user.UserId] = &user
+	return f.GetUser(ctx, user.UserId)
+}
+
+func (f *fakeBackend) PatchUser(ctx context.Context, userID string, username *string, isActive *bool) (*models.User, error) {
+	u, ok := f.users[userID]
+	if !ok {
+		return nil, service.ErrNotFound
+	}
+	f.patches++
+	if username != nil {
+		u.Username = *username
+	}
+	if isActive != nil {
+		u.IsActive = *isActive
+	}
+	return f.GetUser(ctx, userID)
+}
+
+func (f *fakeBackend) DeleteUser(_ context.Context, req models.PostUsersDeleteJSONRequestBody) (*models.UserDeleteResult, error) {
+	if _, ok := f.users[req.UserId]; !ok {
+		return nil, service.ErrNotFound
+	}
+	delete(f.users, req.UserId)
+	for name, members := range f.teams {
+		f.teams[name] = slices.DeleteFunc(members, func(id string) bool { return id == req.UserId })
+	}
+	return &models.UserDeleteResult{UserId: req.UserId, Result: models.UserDeleteResultResultDeleted}, nil
+}
+
+func (f *fakeBackend) GetTeam(_ context.Context, teamName string) (*models.Team, error) {
+	members, ok := f.teams[teamName]
+	if !ok {
+		return nil, service.ErrNotFound
+	}
+	team := &models.Team{TeamName: teamName, Members: []models.TeamMember{}}
+	for _, id := range members {
+		u := f.users[id]
+		team.Members = append(team.Members, models.TeamMember{UserId: id, Username: u.Username, IsActive: u.IsActive})
+	}
+	return team, nil
+}
+
+func (f *fakeBackend) ListTeams(ctx context.Context) ([]models.Team, error) {
+	var names []string
+	for name := range f.teams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	teams := []models.Team{}
+	for _, name := range names {
+		t, _ := f.GetTeam(ctx, name)
+		teams = append(teams, *t)
+	}
+	return teams, nil
+}
+
+func (f *fakeBackend) CreateTeam(ctx context.Context, teamName string, userIDs []string) (*models.Team, error) {
+	if _, ok := f.teams[teamName]; ok {
+		return nil, service.ErrConflict
+	}
+	for _, id := range userIDs {
+		if _, ok := f.users[id]; !ok {
+			return nil, fmt.Errorf("%w: user %q", service.ErrNotFound, id)
+		}
+	}
+	f.teams[teamName] = slices.Clone(userIDs)
+	return f.GetTeam(ctx, teamName)
+}
+
+func (f *fakeBackend) RenameTeam(ctx context.Context, req models.PostTeamRenameJSONRequestBody) (*models.Team, error) {
+	members, ok := f.teams[req.TeamName]
+	if !ok {
+		return nil, service.ErrNotFound
+	}
+	if _, ok := f.teams[req.NewTeamName]; ok {
+		return nil, service.ErrConflict
+	}
+	delete(f.teams, req.TeamName)
+	f.teams[req.NewTeamName] = members
+	return f.GetTeam(ctx, req.NewTeamName)
+}
+
+func (f *fakeBackend) AddTeamMembers(ctx context.Context, teamName string, userIDs []string) (*models.Team, error) {
+	if _, ok := f.teams[teamName]; !ok {
+		return nil, service.ErrNotFound
+	}
+	for _, id := range userIDs {
+		if _, ok := f.users[id]; !ok {
+			return nil, fmt.Errorf("%w: user %q", service.ErrNotFound, id)
+		}
+		if !slices.Contains(f.teams[teamName], id) {
+			f.teams[teamName] = append(f.teams[teamName], id)
+		}
+	}
+	return f.GetTeam(ctx, teamName)
+}
+
+func (f *fakeBackend) RemoveTeamMembers(_ context.Context, teamName string, userIDs []string, _ models.ReviewPolicy) ([]models.ReviewReassignment, error) {
+	if _, ok := f.teams[teamName]; !ok {
+		return nil, service.ErrNotFound
+	}
+	f.teams[teamName] = slices.DeleteFunc(f.teams[teamName], func(id string) bool { return slices.Contains(userIDs, id) })
+	return []models.ReviewReassignment{}, nil
+}
+
+func (f *fakeBackend) DeleteTeam(_ context.Context, req models.PostTeamDeleteJSONRequestBody) (*models.TeamDeleteResult, error) {
+	members, ok := f.teams[req.TeamName]
+	if !ok {
+		return nil, service.ErrNotFound
+	}
+	if len(members) > 0 {
+		return nil, service.ErrPrecondition
+	}
+	delete(f.teams, req.TeamName)
+	return &models.TeamDeleteResult{TeamName: req.TeamName}, nil
+}
+
+func TestUsers_PagesThroughBackend(t *testing.T) {
+	b := newFakeBackend()
+	for _, id := range []string{"u1", "u2", "u3", "u4", "u5"} {
+		b.users[id] = &models.User{UserId: id, Username: id, IsActive: true}
+	}
+	h := scim.NewHandler(b, "/scim/v2")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/Users?startIndex=2&count=2", nil))
+	require.Equal(t, 200, rec.Code, rec.Body.String())
+
+	var list struct {
+		TotalResults int `json:"totalResults"`
+		StartIndex   int `json:"startIndex"`
+		ItemsPerPage int `json:"itemsPerPage"`
+		Resources    []struct {
+			ID string `json:"id"`
+		} `json:"Resources"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	assert.Equal(t, 5, list.TotalResults)
+	assert.Equal(t, 2, list.StartIndex)
+	assert.Equal(t, 2, list.ItemsPerPage)
+	require.Len(t, list.Resources, 2)
+	assert.Equal(t, "u2", list.Resources[0].ID)
+	assert.Equal(t, "u3", list.Resources[1].ID)
+	assert.Equal(t, 1, b.ranges)
+}
+
+func TestUsers_PatchAppliesChangesInOneCall(t *testing.T) {
+	b := newFakeBackend()
+	b.users["u1"] = &models.User{UserId: "u1", Username: "Old", IsActive: true}
+	h := scim.NewHandler(b, "/scim/v2")
+
+	body := `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[
+		{"op":"replace","path":"displayName","value":"New"},
+		{"op":"replace","path":"active","value":false}]}`
+	req := httptest.NewRequest("PATCH", "/Users/u1", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/scim+json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, 200, rec.Code, rec.Body.String())
+
+	assert.Equal(t, 1, b.patches)
+	assert.Equal(t, "New", b.users["u1"].Username)
+	assert.False(t, b.users["u1"].IsActive)
+}
@@ -0,0 +1,253 @@
+package scim
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"pull-request-api.com/internal/models"
+	"pull-request-api.com/internal/service"
+)
+
+// User — ресурс User. id и userName — это user_id, displayName — username.
+type User struct {
+	Schemas     []string    `json:"schemas"`
+	Id          string      `json:"id"`
+	UserName    string      `json:"userName"`
+	DisplayName string      `json:"displayName"`
+	Active      bool        `json:"active"`
+	Groups      []MemberRef `json:"groups"`
+	Meta        Meta        `json:"meta"`
+}
+
+// MemberRef — ссылка на пользователя (в Group.members) или группу (в User.groups).
+type MemberRef struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// userRequest — тело POST /Users. Имя берётся из displayName, затем из name,
+// затем из userName.
+type userRequest struct {
+	UserName    string `json:"userName"`
+	DisplayName string `json:"displayName"`
+	Name        struct {
+		Formatted  string `json:"formatted"`
+		GivenName  string `json:"givenName"`
+		FamilyName string `json:"familyName"`
+	} `json:"name"`
+	Active *bool `json:"active"`
+}
+
+func (u userRequest) displayName() string {
+	switch {
+	case u.DisplayName != "":
+		return u.DisplayName
+	case u.Name.Formatted != "":
+		return u.Name.Formatted
+	}
+	if name := strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName); name != "" {
+		return name
+	}
+	return u.UserName
+}
+
+func (h *Handler) toUser(u *models.User) User {
+	groups := []MemberRef{}
+	for _, t := range u.Teams {
+		groups = append(groups, MemberRef{Value: t, Display: t, Ref: h.basePath + "/Groups/" + t})
+	}
+	return User{
+		Schemas:     []string{schemaUser},
+		Id:          u.UserId,
+		UserName:    u.UserId,
+		DisplayName: u.Username,
+		Active:      u.IsActive,
+		Groups:      groups,
+		Meta:        Meta{ResourceType: "User", Location: h.basePath + "/Users/" + u.UserId},
+	}
+}
+
+// lookupUser возвращает пользователя, считая обезличенных несуществующими.
+func (h *Handler) lookupUser(r *http.Request, id string) (*models.User, error) {
+	u, err := h.b.GetUser(r.Context(), id)
+	if err != nil {
+		return nil, err
+	}
+	if u.DeletedAt != nil {
+		return nil, service.ErrNotFound
+	}
+	return u, nil
+}
+
+func (h *Handler) listUsers(w http.ResponseWriter, r *http.Request) {
+	start, count, err := page(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	attr, value, err := parseFilter(r.URL.Query().Get("filter"), "userName", "id")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if attr != "" {
+		users := []User{}
+		u, err := h.lookupUser(r, value)
+		if err != nil && !errors.Is(err, service.ErrNotFound) {
+			handleError(w, err)
+			return
+		}
+		if u != nil {
+			users = append(users, h.toUser(u))
+		}
+		sendJSON(w, http.StatusOK, listResponse(users, start, count))
+		return
+	}
+
+	// Без фильтра читаем из справочника только запрошенную страницу.
+	page, total, err := h.b.ListUsersRange(r.Context(), start-1, count)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	resources := make([]any, 0, len(page))
+	for i := range page {
+		resources = append(resources, h.toUser(&page[i]))
+	}
+	sendJSON(w, http.StatusOK, ListResponse{
+		Schemas:      []string{schemaList},
+		TotalResults: total,
+		StartIndex:   start,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+func (h *Handler) createUser(w http.ResponseWriter, r *http.Request) {
+	var req userRequest
+	if err := decode(w, r, &req); err != nil {
+		handleError(w, err)
+		return
+	}
+	if req.UserName == "" {
+		handleError(w, invalidValue("userName is required"))
+		return
+	}
+
+	user := models.User{UserId: req.UserName, Username: req.displayName(), IsActive: true}
+	if req.Active != nil {
+		user.IsActive = *req.Active
+	}
+	created, err := h.b.CreateUser(r.Context(), user)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	res := h.toUser(created)
+	w.Header().Set("Location", res.Meta.Location)
+	sendJSON(w, http.StatusCreated, res)
+}
+
+func (h *Handler) getUser(w http.ResponseWriter, r *http.Request) {
+	u, err := h.lookupUser(r, chi.URLParam(r, "id"))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, h.toUser(u))
+}
+
+// patchUser меняет displayName и active. Деактивация работает так же, как
+// POST /users/setIsActive. Атрибуты, которые сервис не хранит (emails, name,
+// расширения схемы), игнорируются.
+func (h *Handler) patchUser(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	var req PatchRequest
+	if err := decode(w, r, &req); err != nil {
+		handleError(w, err)
+		return
+	}
+	changes, err := req.changes()
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	u, err := h.lookupUser(r, id)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	username, active := u.Username, u.IsActive
+	for _, c := range changes {
+		switch c.attr {
+		case "active", "displayname", "username":
+			if c.op == "remove" {
+				handleError(w, &badRequest{scimType: "mutability", detail: c.attr + " cannot be removed"})
+				return
+			}
+		}
+		switch c.attr {
+		case "active":
+			if active, err = boolValue(c.value); err != nil {
+				handleError(w, err)
+				return
+			}
+		case "displayname":
+			if username, err = stringValue(c.value); err != nil {
+				handleError(w, err)
+				return
+			}
+		case "username":
+			v, err := stringValue(c.value)
+			if err != nil {
+				handleError(w, err)
+				return
+			}
+			if v != u.UserId {
+				handleError(w, &badRequest{scimType: "mutability", detail: "userName is immutable"})
+				return
+			}
+		}
+	}
+
+	var newUsername *string
+	var newActive *bool
+	if username != u.Username {
+		newUsername = &username
+	}
+	if active != u.IsActive {
+		newActive = &active
+	}
+	if newUsername != nil || newActive != nil {
+		if u, err = h.b.PatchUser(r.Context(), id, newUsername, newActive); err != nil {
+			handleError(w, err)
+			return
+		}
+	}
+	sendJSON(w, http.StatusOK, h.toUser(u))
+}
+
+// deleteUser удаляет пользователя; с историей PR он обезличивается, а его
+// открытые ревью передаются другим участникам (см. POST /users/delete).
+func (h *Handler) deleteUser(w http.ResponseWriter, r *http.Request) {
+	mode := models.UserDeleteModeAnonymize
+	policy := models.ReviewPolicyReassign
+	_, err := h.b.DeleteUser(r.Context(), models.PostUsersDeleteJSONRequestBody{
+		UserId:       chi.URLParam(r, "id"),
+		Mode:         &mode,
+		ReviewPolicy: &policy,
+	})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"pull-request-api.com/internal/auth"
+)
+
+// audit записывает изменение в audit_log в рамках транзакции операции.
+// Автор изменения берётся из контекста запроса (пусто, если аутентификация выключена).
+func audit(ctx context.Context, tx *sql.Tx, action, entity string, details any) error {
+	data, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+
+	var actor sql.NullString
+	if p := auth.PrincipalFromContext(ctx); p != nil {
+		actor = sql.NullString{String: p.UserID, Valid: true}
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO audit_log (actor, action, entity, details) VALUES ($1, $2, $3, $4)`,
+		actor, action, entity, string(data))
+	return err
+}
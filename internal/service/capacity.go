@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"pull-request-api.com/internal/models"
+)
+
+// SetMaxOpenReviews задаёт лимит открытых ревью пользователя; nil снимает
+// собственный лимит, и действует лимит основной команды. Уже назначенные ревью
+// не снимаются: лимит учитывается только при выборе ревьюверов.
+func (s *Service) SetMaxOpenReviews(ctx context.Context, req models.PostUsersSetMaxOpenReviewsJSONRequestBody) (*models.User, error) {
+	if req.MaxOpenReviews != nil && *req.MaxOpenReviews < 0 {
+		return nil, fmt.Errorf("%w: max_open_reviews must not be negative", ErrInvalidInput)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var old sql.NullInt64
+	err = tx.QueryRowContext(ctx, `SELECT max_open_reviews FROM users WHERE user_id = $1 AND deleted_at IS NULL FOR UPDATE`, req.UserId).
+		Scan(&old)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE users SET max_open_reviews = $1 WHERE user_id = $2`, req.MaxOpenReviews, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	err = audit(ctx, tx, "user.set_max_open_reviews", "user:"+req.UserId, map[string]any{
+		"from": nullableInt(old),
+		"to":   req.MaxOpenReviews,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.getUser(ctx, req.UserId)
+}
+
+// SetReviewCapacity задаёт лимит открытых ревью по умолчанию для участников, у
+// которых team — основная команда, и политику для PR команды, когда все
+// кандидаты достигли лимита (см. selectReviewers).
+func (s *Service) SetReviewCapacity(ctx context.Context, req models.PostTeamSetReviewCapacityJSONRequestBody) (*models.Team, error) {
+	if req.DefaultMaxOpenReviews != nil && *req.DefaultMaxOpenReviews < 0 {
+		return nil, fmt.Errorf("%w: default_max_open_reviews must not be negative", ErrInvalidInput)
+	}
+	if !validCapacityPolicy(req.CapacityPolicy) {
+		return nil, fmt.Errorf("%w: unknown capacity policy %q", ErrInvalidInput, req.CapacityPolicy)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockTeam(ctx, tx, req.TeamName); err != nil {
+		return nil, err
+	}
+	if err := setReviewCapacity(ctx, tx, req.TeamName, req.DefaultMaxOpenReviews, req.CapacityPolicy); err != nil {
+		return nil, err
+	}
+
+	err = audit(ctx, tx, "team.set_review_capacity", "team:"+req.TeamName, map[string]any{
+		"default_max_open_reviews": req.DefaultMaxOpenReviews,
+		"capacity_policy":          req.CapacityPolicy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.GetTeam(ctx, req.TeamName)
+}
+
+func setReviewCapacity(ctx context.Context, tx *sql.Tx, team string, limit *int, policy models.CapacityPolicy) error {
+	_, err := tx.ExecContext(ctx, `UPDATE teams SET default_max_open_reviews = $1, capacity_policy = $2 WHERE team_name = $3`,
+		limit, string(policy), team)
+	return err
+}
+
+func validCapacityPolicy(p models.CapacityPolicy) bool {
+	return slices.Contains([]models.CapacityPolicy{
+		models.CapacityPolicyFail,
+		models.CapacityPolicyLeastLoaded,
+		models.CapacityPolicyQueue,
+	}, p)
+}
+
+func nullableInt(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	n := int(v.Int64)
+	return &n
+}
@@ -7,4 +7,5 @@ var (
 	ErrConflict     = errors.New("already exists")
 	ErrInvalidInput = errors.New("invalid input")
 	ErrPrecondition = errors.New("precondition failed") // статус merged
+	ErrNoCandidate  = errors.New("no candidate")        // все кандидаты достигли лимита (capacity_policy=fail)
 )
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"pull-request-api.com/internal/auth"
+	"pull-request-api.com/internal/database"
+	"pull-request-api.com/internal/models"
+)
+
+// Агрегаты журнала событий.
+const (
+	aggregatePR   = "pull_request"
+	aggregateUser = "user"
+	aggregateTeam = "team"
+)
+
+// Типы событий. События PR — источник истины для проекций pull_requests и
+// pr_reviewers; события пользователей и команд нужны для истории и для
+// пересборки (переименование и удаление команды меняют team_name у PR).
+const (
+	eventPRCreated        = "PRCreated"
+	eventReviewerAssigned = "ReviewerAssigned"
+	eventReviewerRemoved  = "ReviewerRemoved"
+	eventReviewersPending = "ReviewersPending"
+	eventPRMerged         = "PRMerged"
+	eventUserActivated    = "UserActivated"
+	eventUserDeactivated  = "UserDeactivated"
+	eventUserDeleted      = "UserDeleted"
+	eventUserMoved        = "UserMoved"
+	eventTeamRenamed      = "TeamRenamed"
+	eventTeamDeleted      = "TeamDeleted"
+)
+
+type prCreatedData struct {
+	PullRequestName string  `json:"pull_request_name"`
+	AuthorID        string  `json:"author_id"`
+	TeamName        *string `json:"team_name"`
+}
+
+type reviewerData struct {
+	ReviewerID string `json:"reviewer_id"`
+	// Selection — решение, по которому ревьювер назначен (у ReviewerAssigned
+	// после автоматического выбора).
+	Selection *models.ReviewerSelection `json:"selection,omitempty"`
+}
+
+// pendingData — число незаполненных слотов ревьюверов PR после события.
+type pendingData struct {
+	PendingReviewers int `json:"pending_reviewers"`
+}
+
+// userMovedData — смена основной команды пользователя.
+type userMovedData struct {
+	FromTeam string `json:"from_team"`
+	ToTeam   string `json:"to_team"`
+}
+
+type teamRenamedData struct {
+	NewTeamName string `json:"new_team_name"`
+}
+
+// event — запись журнала events.
+type event struct {
+	aggregateType string
+	aggregateID   string
+	eventType     string
+	data          []byte
+	occurredAt    time.Time
+}
+
+// RebuildStats — результат пересборки проекций.
+type RebuildStats struct {
+	Events       int
+	PullRequests int
+}
+
+// emit записывает событие в журнал и обновляет проекции в транзакции операции.
+// Автор изменения берётся из контекста запроса, как в audit.
+func (s *Service) emit(ctx context.Context, tx *sql.Tx, aggregateType, aggregateID, eventType string, data any) error {
+	payload := []byte("{}")
+	if data != nil {
+		var err error
+		if payload, err = json.Marshal(data); err != nil {
+			return err
+		}
+	}
+
+	var actor sql.NullString
+	if p := auth.PrincipalFromContext(ctx); p != nil {
+		actor = sql.NullString{String: p.UserID, Valid: true}
+	}
+
+	ev := event{
+		aggregateType: aggregateType,
+		aggregateID:   aggregateID,
+		eventType:     eventType,
+		data:          payload,
+		occurredAt:    s.now().UTC(),
+	}
+	_, err := tx.ExecContext(ctx, `INSERT INTO events (aggregate_type, aggregate_id, event_type, data, actor, occurred_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`, ev.aggregateType, ev.aggregateID, ev.eventType, string(ev.data), actor, ev.occurredAt)
+	if err != nil {
+		return err
+	}
+	return project(ctx, tx, ev)
+}
+
+// project применяет событие PR к проекциям. Переименование и удаление команды
+// доходят до pull_requests.team_name каскадом внешнего ключа.
+func project(ctx context.Context, tx *sql.Tx, ev event) error {
+	if ev.aggregateType != aggregatePR {
+		return nil
+	}
+
+	var err error
+	switch ev.eventType {
+	case eventPRCreated:
+		var d prCreatedData
+		if err := json.Unmarshal(ev.data, &d); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at, team_name)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			ev.aggregateID, d.PullRequestName, d.AuthorID, models.PullRequestStatusOPEN, ev.occurredAt, d.TeamName)
+	case eventReviewerAssigned:
+		var d reviewerData
+		if err := json.Unmarshal(ev.data, &d); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO pr_reviewers (pull_request_id, reviewer_id) VALUES ($1, $2)`, ev.aggregateID, d.ReviewerID)
+	case eventReviewerRemoved:
+		var d reviewerData
+		if err := json.Unmarshal(ev.data, &d); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM pr_reviewers WHERE pull_request_id = $1 AND reviewer_id = $2`, ev.aggregateID, d.ReviewerID)
+	case eventReviewersPending:
+		var d pendingData
+		if err := json.Unmarshal(ev.data, &d); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE pull_requests SET pending_reviewers = $1 WHERE pull_request_id = $2`, d.PendingReviewers, ev.aggregateID)
+	case eventPRMerged:
+		// Слоты смерженного PR заполнять уже не нужно.
+		_, err = tx.ExecContext(ctx, `UPDATE pull_requests SET status = $1, merged_at = $2, pending_reviewers = 0 WHERE pull_request_id = $3`,
+			models.PullRequestStatusMERGED, ev.occurredAt, ev.aggregateID)
+	default:
+		return fmt.Errorf("unknown pull request event %q", ev.eventType)
+	}
+	return err
+}
+
+// recordUserActivity записывает UserActivated/UserDeactivated, если активность
+// пользователя изменилась.
+func (s *Service) recordUserActivity(ctx context.Context, tx *sql.Tx, userID string, wasActive, isActive bool) error {
+	switch {
+	case !wasActive && isActive:
+		return s.emit(ctx, tx, aggregateUser, userID, eventUserActivated, nil)
+	case wasActive && !isActive:
+		return s.emit(ctx, tx, aggregateUser, userID, eventUserDeactivated, nil)
+	}
+	return nil
+}
+
+// prHistory — состояние PR, свёрнутое из событий.
+type prHistory struct {
+	prs   map[string]*models.PullRequest
+	order []string
+}
+
+func newPRHistory() *prHistory {
+	return &prHistory{prs: map[string]*models.PullRequest{}}
+}
+
+func (h *prHistory) apply(ev event) error {
+	if ev.aggregateType == aggregateTeam {
+		var newName *string
+		switch ev.eventType {
+		case eventTeamRenamed:
+			var d teamRenamedData
+			if err := json.Unmarshal(ev.data, &d); err != nil {
+				return err
+			}
+			newName = &d.NewTeamName
+		case eventTeamDeleted:
+		default:
+			return nil
+		}
+		for _, pr := range h.prs {
+			if pr.TeamName != nil && *pr.TeamName == ev.aggregateID {
+				pr.TeamName = newName
+			}
+		}
+		return nil
+	}
+	if ev.aggregateType != aggregatePR {
+		return nil
+	}
+
+	occurredAt := ev.occurredAt
+	if ev.eventType == eventPRCreated {
+		var d prCreatedData
+		if err := json.Unmarshal(ev.data, &d); err != nil {
+			return err
+		}
+		h.prs[ev.aggregateID] = &models.PullRequest{
+			PullRequestId:     ev.aggregateID,
+			PullRequestName:   d.PullRequestName,
+			AuthorId:          d.AuthorID,
+			Status:            models.PullRequestStatusOPEN,
+			AssignedReviewers: []string{},
+			CreatedAt:         &occurredAt,
+			TeamName:          d.TeamName,
+		}
+		h.order = append(h.order, ev.aggregateID)
+		return nil
+	}
+
+	pr := h.prs[ev.aggregateID]
+	if pr == nil {
+		return fmt.Errorf("event %s for pull request %q before %s", ev.eventType, ev.aggregateID, eventPRCreated)
+	}
+	switch ev.eventType {
+	case eventReviewerAssigned, eventReviewerRemoved:
+		var d reviewerData
+		if err := json.Unmarshal(ev.data, &d); err != nil {
+			return err
+		}
+		reviewers := pr.AssignedReviewers[:0:0]
+		for _, r := range pr.AssignedReviewers {
+			if r != d.ReviewerID {
+				reviewers = append(reviewers, r)
+			}
+		}
+		if ev.eventType == eventReviewerAssigned {
+			reviewers = append(reviewers, d.ReviewerID)
+		}
+		pr.AssignedReviewers = reviewers
+	case eventReviewersPending:
+		var d pendingData
+		if err := json.Unmarshal(ev.data, &d); err != nil {
+			return err
+		}
+		pr.PendingReviewers = d.PendingReviewers
+	case eventPRMerged:
+		pr.Status = models.PullRequestStatusMERGED
+		pr.MergedAt = &occurredAt
+		pr.PendingReviewers = 0
+	}
+	return nil
+}
+
+// querier — *sql.DB или *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// loadEvents сворачивает в h события, отобранные условием query, и
+// возвращает их число.
+func loadEvents(ctx context.Context, q querier, h *prHistory, query string, args ...any) (int, error) {
+	rows, err := q.QueryContext(ctx, `SELECT aggregate_type, aggregate_id, event_type, data, occurred_at FROM events `+query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		var (
+			ev   event
+			data string
+		)
+		if err := rows.Scan(&ev.aggregateType, &ev.aggregateID, &ev.eventType, &data, &ev.occurredAt); err != nil {
+			return 0, err
+		}
+		ev.data = []byte(data)
+		if err := h.apply(ev); err != nil {
+			return 0, err
+		}
+		n++
+	}
+	return n, rows.Err()
+}
+
+// GetPullRequestAsOf восстанавливает состояние PR на момент at по журналу
+// событий. ErrNotFound, если к этому моменту PR ещё не был создан.
+func (s *Service) GetPullRequestAsOf(ctx context.Context, prID string, at time.Time) (*models.PullRequest, error) {
+	h := newPRHistory()
+	_, err := loadEvents(ctx, s.db, h, `
+		WHERE ((aggregate_type = $1 AND aggregate_id = $2) OR aggregate_type = $3) AND occurred_at <= $4
+		ORDER BY id`, aggregatePR, prID, aggregateTeam, at.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	pr := h.prs[prID]
+	if pr == nil {
+		return nil, ErrNotFound
+	}
+	return pr, nil
+}
+
+// RebuildProjections пересобирает pull_requests и pr_reviewers по журналу
+// событий. Выполняется в одной транзакции; на время пересборки PR
+// не создаются и не меняются.
+func (s *Service) RebuildProjections(ctx context.Context) (*RebuildStats, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if s.dialect == database.Postgres {
+		if _, err := tx.ExecContext(ctx, `LOCK TABLE events, pull_requests, pr_reviewers IN EXCLUSIVE MODE`); err != nil {
+			return nil, err
+		}
+	}
+
+	h := newPRHistory()
+	n, err := loadEvents(ctx, tx, h, `ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM pr_reviewers`); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM pull_requests`); err != nil {
+		return nil, err
+	}
+	for _, id := range h.order {
+		pr := h.prs[id]
+		_, err := tx.ExecContext(ctx, `INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at, merged_at, team_name, pending_reviewers)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			pr.PullRequestId, pr.PullRequestName, pr.AuthorId, pr.Status, pr.CreatedAt, pr.MergedAt, pr.TeamName, pr.PendingReviewers)
+		if err != nil {
+			return nil, fmt.Errorf("pull request %q: %w", id, err)
+		}
+		for _, r := range pr.AssignedReviewers {
+			_, err := tx.ExecContext(ctx, `INSERT INTO pr_reviewers (pull_request_id, reviewer_id) VALUES ($1, $2)`, id, r)
+			if err != nil {
+				return nil, fmt.Errorf("pull request %q: %w", id, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &RebuildStats{Events: n, PullRequests: len(h.order)}, nil
+}
@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"pull-request-api.com/internal/models"
+)
+
+// subtreeCTE — рекурсивный CTE subtree(team_name): команда $1 и, если $2 = TRUE,
+// все её подкоманды.
+const subtreeCTE = `WITH RECURSIVE subtree(team_name) AS (
+	SELECT team_name FROM teams WHERE team_name = $1
+	UNION
+	SELECT t.team_name FROM teams t JOIN subtree s ON t.parent_team = s.team_name WHERE $2
+)`
+
+// SetTeamParent задаёт родительскую команду (или делает команду корневой при
+// parent_team = null). Циклы в иерархии запрещены.
+func (s *Service) SetTeamParent(ctx context.Context, req models.PostTeamSetParentJSONRequestBody) (*models.Team, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockTeam(ctx, tx, req.TeamName); err != nil {
+		return nil, err
+	}
+	if err := setParent(ctx, tx, req.TeamName, req.ParentTeam); err != nil {
+		return nil, err
+	}
+
+	err = audit(ctx, tx, "team.set_parent", "team:"+req.TeamName, map[string]any{
+		"parent_team": req.ParentTeam,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.GetTeam(ctx, req.TeamName)
+}
+
+func setParent(ctx context.Context, tx *sql.Tx, team string, parent *string) error {
+	if parent != nil {
+		if *parent == team {
+			return fmt.Errorf("%w: team cannot be its own parent", ErrInvalidInput)
+		}
+		if err := lockTeam(ctx, tx, *parent); err != nil {
+			return err
+		}
+
+		// Цикл возникает, если team уже является предком parent.
+		var cycle bool
+		err := tx.QueryRowContext(ctx, `
+			WITH RECURSIVE ancestors(team_name, parent_team) AS (
+				SELECT team_name, parent_team FROM teams WHERE team_name = $1
+				UNION
+				SELECT t.team_name, t.parent_team FROM teams t JOIN ancestors a ON t.team_name = a.parent_team
+			)
+			SELECT EXISTS(SELECT 1 FROM ancestors WHERE team_name = $2)
+		`, *parent, team).Scan(&cycle)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return fmt.Errorf("%w: team %q is an ancestor of %q", ErrInvalidInput, team, *parent)
+		}
+	}
+
+	_, err := tx.ExecContext(ctx, `UPDATE teams SET parent_team = $1 WHERE team_name = $2`, parent, team)
+	return err
+}
+
+// SetEscalationPolicy задаёт, откуда добирать ревьюверов, когда в команде не
+// хватает кандидатов (см. selectReviewers).
+func (s *Service) SetEscalationPolicy(ctx context.Context, req models.PostTeamSetEscalationPolicyJSONRequestBody) (*models.Team, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockTeam(ctx, tx, req.TeamName); err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE teams SET escalation_policy = $1 WHERE team_name = $2`,
+		string(req.EscalationPolicy), req.TeamName)
+	if err != nil {
+		return nil, err
+	}
+
+	err = audit(ctx, tx, "team.set_escalation_policy", "team:"+req.TeamName, map[string]any{
+		"escalation_policy": req.EscalationPolicy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.GetTeam(ctx, req.TeamName)
+}
+
+// GetTeamTree возвращает иерархию команд: поддерево teamName или, без него,
+// все корневые команды с их подкомандами.
+func (s *Service) GetTeamTree(ctx context.Context, teamName *string) ([]models.TeamNode, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t.team_name, t.parent_team, t.escalation_policy, COUNT(tm.user_id)
+		FROM teams t LEFT JOIN team_members tm ON tm.team_name = t.team_name
+		GROUP BY t.team_name, t.parent_team, t.escalation_policy
+		ORDER BY t.team_name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nodes := map[string]*models.TeamNode{}
+	var order []string
+	for rows.Next() {
+		var (
+			n      models.TeamNode
+			parent sql.NullString
+			policy string
+		)
+		if err := rows.Scan(&n.TeamName, &parent, &policy, &n.MembersCount); err != nil {
+			return nil, err
+		}
+		if parent.Valid {
+			n.ParentTeam = &parent.String
+		}
+		n.EscalationPolicy = models.EscalationPolicy(policy)
+		n.Children = []models.TeamNode{}
+		nodes[n.TeamName] = &n
+		order = append(order, n.TeamName)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	children := map[string][]string{}
+	var roots []string
+	for _, name := range order {
+		if p := nodes[name].ParentTeam; p != nil {
+			children[*p] = append(children[*p], name)
+		} else {
+			roots = append(roots, name)
+		}
+	}
+
+	var build func(name string) models.TeamNode
+	build = func(name string) models.TeamNode {
+		n := *nodes[name]
+		for _, c := range children[name] {
+			n.Children = append(n.Children, build(c))
+		}
+		return n
+	}
+
+	if teamName != nil {
+		if _, ok := nodes[*teamName]; !ok {
+			return nil, ErrNotFound
+		}
+		roots = []string{*teamName}
+	}
+
+	tree := []models.TeamNode{}
+	for _, name := range roots {
+		tree = append(tree, build(name))
+	}
+	return tree, nil
+}
+
+// GetPullRequest возвращает PR с назначенными ревьюверами.
+func (s *Service) GetPullRequest(ctx context.Context, prID string) (*models.PullRequest, error) {
+	return s.getPullRequest(ctx, prID)
+}
+
+const (
+	defaultPRPageSize = 50
+	maxPRPageSize     = 200
+)
+
+// ListPullRequests возвращает страницу PR с фильтрами по команде (опционально
+// вместе с подкомандами) и статусу в порядке создания. Следующая страница —
+// с cursor из next_cursor.
+func (s *Service) ListPullRequests(ctx context.Context, params models.GetPullRequestListParams) (*models.PullRequestList, error) {
+	limit := defaultPRPageSize
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	if limit < 1 || limit > maxPRPageSize {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidInput, maxPRPageSize)
+	}
+
+	// Берём на одну запись больше, чтобы понять, есть ли следующая страница.
+	fetch := limit + 1
+	params.Limit = &fetch
+	ids, err := s.ListPullRequestIDs(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	list := &models.PullRequestList{PullRequests: []models.PullRequest{}}
+	if len(ids) > limit {
+		ids = ids[:limit]
+		list.NextCursor = &ids[limit-1]
+	}
+
+	byID, err := s.GetPullRequestsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if pr := byID[id]; pr != nil {
+			list.PullRequests = append(list.PullRequests, *pr)
+		}
+	}
+	return list, nil
+}
+
+// ListPullRequestIDs возвращает идентификаторы PR для ListPullRequests в
+// порядке создания: после PR params.Cursor и не больше params.Limit (без
+// лимита, если он не задан).
+func (s *Service) ListPullRequestIDs(ctx context.Context, params models.GetPullRequestListParams) ([]string, error) {
+	var (
+		status     sql.NullString
+		team       string
+		subteams   bool
+		filterTeam bool
+		cursor     sql.NullString
+	)
+	if params.Status != nil {
+		status = sql.NullString{String: string(*params.Status), Valid: true}
+	}
+	if params.TeamName != nil {
+		team, filterTeam = *params.TeamName, true
+		subteams = params.IncludeSubteams != nil && *params.IncludeSubteams
+
+		var exists bool
+		err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)`, team).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrNotFound
+		}
+	}
+	if params.Cursor != nil {
+		cursor = sql.NullString{String: *params.Cursor, Valid: true}
+
+		var exists bool
+		err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)`, cursor).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("%w: unknown cursor", ErrInvalidInput)
+		}
+	}
+
+	// Курсор — последний PR предыдущей страницы; порядок (created_at,
+	// pull_request_id) однозначен, поэтому страница продолжается сразу за ним.
+	query := subtreeCTE + `
+		SELECT pull_request_id FROM pull_requests
+		WHERE (NOT $3 OR team_name IN (SELECT team_name FROM subtree))
+			AND (CAST($4 AS TEXT) IS NULL OR status = $4)
+			AND (CAST($5 AS TEXT) IS NULL OR (created_at, pull_request_id) >
+				(SELECT created_at, pull_request_id FROM pull_requests WHERE pull_request_id = $5))
+		ORDER BY created_at, pull_request_id`
+	args := []any{team, subteams, filterTeam, status, cursor}
+	if params.Limit != nil {
+		query += ` LIMIT $6`
+		args = append(args, *params.Limit)
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
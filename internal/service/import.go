@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"pull-request-api.com/internal/models"
+)
+
+// ImportTeams массово создаёт и дополняет команды по той же логике, что и
+// AddTeam: отсутствующие в файле участники и команды не затрагиваются.
+// Возвращает список изменений относительно текущего состояния; с dryRun
+// ничего не применяет. Применение атомарно — либо весь файл, либо ничего.
+func (s *Service) ImportTeams(ctx context.Context, teams []models.Team, dryRun bool) (*models.OrgPlan, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	changes, err := planImport(ctx, tx, teams)
+	if err != nil {
+		return nil, err
+	}
+	plan := &models.OrgPlan{Changes: changes}
+	if dryRun {
+		return plan, nil
+	}
+
+	// Сначала заводим все команды, чтобы parent_team мог ссылаться на команду
+	// ниже по файлу.
+	for _, t := range teams {
+		_, err := tx.ExecContext(ctx, `INSERT INTO teams (team_name) VALUES ($1) ON CONFLICT (team_name) DO NOTHING`, t.TeamName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// План импорта считает существующих пользователей новыми участниками
+	// (add_member), а не переводом — основная команда у них не меняется.
+	additional := models.TeamMembershipAdditional
+	for _, t := range teams {
+		t.Membership = &additional
+		if err := s.addTeam(ctx, tx, t); err != nil {
+			return nil, err
+		}
+	}
+
+	names := make([]string, 0, len(teams))
+	for _, t := range teams {
+		names = append(names, t.TeamName)
+	}
+	err = audit(ctx, tx, "org.import", "org", map[string]any{
+		"teams":   names,
+		"changes": len(changes),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	plan.Applied = true
+	return plan, nil
+}
+
+func planImport(ctx context.Context, tx *sql.Tx, teams []models.Team) ([]models.OrgChange, error) {
+	inFile := map[string]bool{}
+	for _, t := range teams {
+		inFile[t.TeamName] = true
+	}
+
+	changes := []models.OrgChange{}
+	for _, t := range teams {
+		var (
+			parent sql.NullString
+			policy string
+		)
+		err := tx.QueryRowContext(ctx, `SELECT parent_team, escalation_policy FROM teams WHERE team_name = $1`, t.TeamName).
+			Scan(&parent, &policy)
+		exists := err == nil
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		if !exists {
+			changes = append(changes, models.OrgChange{Action: models.OrgChangeActionCreateTeam, TeamName: t.TeamName})
+		}
+
+		if t.ParentTeam != nil {
+			if !inFile[*t.ParentTeam] {
+				var found bool
+				err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)`, *t.ParentTeam).Scan(&found)
+				if err != nil {
+					return nil, err
+				}
+				if !found {
+					return nil, fmt.Errorf("%w: parent_team %q of team %q does not exist", ErrInvalidInput, *t.ParentTeam, t.TeamName)
+				}
+			}
+			if !parent.Valid || parent.String != *t.ParentTeam {
+				changes = append(changes, fieldChange(models.OrgChangeActionUpdateTeam, t.TeamName, nil, "parent_team",
+					nullable(parent), *t.ParentTeam))
+			}
+		}
+		if t.EscalationPolicy != nil && (!exists || policy != string(*t.EscalationPolicy)) {
+			var old *string
+			if exists {
+				old = &policy
+			}
+			changes = append(changes, fieldChange(models.OrgChangeActionUpdateTeam, t.TeamName, nil, "escalation_policy",
+				old, string(*t.EscalationPolicy)))
+		}
+
+		for _, m := range t.Members {
+			userChanges, err := planMember(ctx, tx, t.TeamName, m)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, userChanges...)
+		}
+	}
+	return changes, nil
+}
+
+func planMember(ctx context.Context, tx *sql.Tx, team string, m models.TeamMember) ([]models.OrgChange, error) {
+	uid := m.UserId
+	var (
+		username string
+		active   bool
+		deleted  bool
+		member   bool
+	)
+	err := tx.QueryRowContext(ctx, `
+		SELECT username, is_active, deleted_at IS NOT NULL,
+			EXISTS(SELECT 1 FROM team_members WHERE team_name = $2 AND user_id = $1)
+		FROM users WHERE user_id = $1
+	`, uid, team).Scan(&username, &active, &deleted, &member)
+	if err == sql.ErrNoRows {
+		return []models.OrgChange{{Action: models.OrgChangeActionCreateUser, TeamName: team, UserId: &uid}}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if deleted {
+		return nil, fmt.Errorf("%w: user %q is deleted", ErrInvalidInput, uid)
+	}
+
+	var changes []models.OrgChange
+	if !member {
+		changes = append(changes, models.OrgChange{Action: models.OrgChangeActionAddMember, TeamName: team, UserId: &uid})
+	}
+	if username != m.Username {
+		changes = append(changes, fieldChange(models.OrgChangeActionUpdateUser, team, &uid, "username", &username, m.Username))
+	}
+	if active != m.IsActive {
+		old := strconv.FormatBool(active)
+		changes = append(changes, fieldChange(models.OrgChangeActionUpdateUser, team, &uid, "is_active", &old, strconv.FormatBool(m.IsActive)))
+	}
+	return changes, nil
+}
+
+func fieldChange(action models.OrgChangeAction, team string, userID *string, field string, oldValue *string, newValue string) models.OrgChange {
+	return models.OrgChange{Action: action, TeamName: team, UserId: userID, Field: &field, Old: oldValue, New: &newValue}
+}
+
+func nullable(v sql.NullString) *string {
+	if !v.Valid {
+		return nil
+	}
+	return &v.String
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"pull-request-api.com/internal/models"
+)
+
+// inChunkSize ограничивает число параметров в одном запросе IN (...).
+const inChunkSize = 500
+
+// inList возвращает "$from, $from+1, ..." для n параметров.
+func inList(from, n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "$%d", from+i)
+	}
+	return b.String()
+}
+
+// inChunks вызывает fn для ids порциями не больше inChunkSize.
+func inChunks(ids []string, fn func(chunk []any) error) error {
+	for len(ids) > 0 {
+		n := min(len(ids), inChunkSize)
+		chunk := make([]any, n)
+		for i, id := range ids[:n] {
+			chunk[i] = id
+		}
+		if err := fn(chunk); err != nil {
+			return err
+		}
+		ids = ids[n:]
+	}
+	return nil
+}
+
+// GetUsersByIDs загружает пользователей по списку user_id двумя запросами на
+// порцию. Неизвестных пользователей в результате нет.
+func (s *Service) GetUsersByIDs(ctx context.Context, ids []string) (map[string]*models.User, error) {
+	users := map[string]*models.User{}
+	err := inChunks(ids, func(chunk []any) error {
+		rows, err := s.db.QueryContext(ctx, `SELECT user_id, username, team_name, is_active, deleted_at, max_open_reviews FROM users
+			WHERE user_id IN (`+inList(1, len(chunk))+`)`, chunk...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				u         models.User
+				team      sql.NullString
+				deletedAt sql.NullTime
+				limit     sql.NullInt64
+			)
+			if err := rows.Scan(&u.UserId, &u.Username, &team, &u.IsActive, &deletedAt, &limit); err != nil {
+				return err
+			}
+			u.MaxOpenReviews = nullableInt(limit)
+			u.TeamName = team.String
+			if deletedAt.Valid {
+				u.DeletedAt = &deletedAt.Time
+			}
+			u.Teams = []string{}
+			users[u.UserId] = &u
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		rows.Close()
+
+		rows, err = s.db.QueryContext(ctx, `SELECT user_id, team_name FROM team_members
+			WHERE user_id IN (`+inList(1, len(chunk))+`) ORDER BY team_name`, chunk...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var userID, team string
+			if err := rows.Scan(&userID, &team); err != nil {
+				return err
+			}
+			if u := users[userID]; u != nil {
+				u.Teams = append(u.Teams, team)
+			}
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetPullRequestsByIDs загружает PR с ревьюверами по списку идентификаторов
+// двумя запросами на порцию. Неизвестных PR в результате нет.
+func (s *Service) GetPullRequestsByIDs(ctx context.Context, ids []string) (map[string]*models.PullRequest, error) {
+	prs := map[string]*models.PullRequest{}
+	err := inChunks(ids, func(chunk []any) error {
+		rows, err := s.db.QueryContext(ctx, `SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at, team_name, pending_reviewers
+			FROM pull_requests WHERE pull_request_id IN (`+inList(1, len(chunk))+`)`, chunk...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				pr        models.PullRequest
+				status    string
+				createdAt time.Time
+				mergedAt  sql.NullTime
+				teamName  sql.NullString
+			)
+			if err := rows.Scan(&pr.PullRequestId, &pr.PullRequestName, &pr.AuthorId, &status, &createdAt, &mergedAt, &teamName, &pr.PendingReviewers); err != nil {
+				return err
+			}
+			pr.Status = models.PullRequestStatus(status)
+			pr.CreatedAt = &createdAt
+			if mergedAt.Valid {
+				pr.MergedAt = &mergedAt.Time
+			}
+			pr.TeamName = nullable(teamName)
+			prs[pr.PullRequestId] = &pr
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		rows.Close()
+
+		rows, err = s.db.QueryContext(ctx, `SELECT pull_request_id, reviewer_id FROM pr_reviewers
+			WHERE pull_request_id IN (`+inList(1, len(chunk))+`)`, chunk...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var prID, reviewer string
+			if err := rows.Scan(&prID, &reviewer); err != nil {
+				return err
+			}
+			if pr := prs[prID]; pr != nil {
+				pr.AssignedReviewers = append(pr.AssignedReviewers, reviewer)
+			}
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return prs, nil
+}
+
+// GetReviewsByUsers возвращает PR, где пользователи назначены ревьюверами,
+// одним запросом на порцию (см. GetUsersReviews для одного пользователя).
+// PR упорядочены по времени создания.
+func (s *Service) GetReviewsByUsers(ctx context.Context, userIDs []string) (map[string][]models.PullRequestShort, error) {
+	reviews := map[string][]models.PullRequestShort{}
+	err := inChunks(userIDs, func(chunk []any) error {
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT prr.reviewer_id, pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status
+			FROM pr_reviewers prr
+			JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+			WHERE prr.reviewer_id IN (`+inList(1, len(chunk))+`)
+			ORDER BY pr.created_at, pr.pull_request_id
+		`, chunk...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				reviewer, status string
+				pr               models.PullRequestShort
+			)
+			if err := rows.Scan(&reviewer, &pr.PullRequestId, &pr.PullRequestName, &pr.AuthorId, &status); err != nil {
+				return err
+			}
+			pr.Status = models.PullRequestShortStatus(status)
+			reviews[reviewer] = append(reviews[reviewer], pr)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"pull-request-api.com/internal/models"
+)
+
+// maxBatchOperations — наибольшее число операций в одном пакете.
+const maxBatchOperations = 1000
+
+// BatchItem — результат операции пакета. Err — ошибка сервиса; вызывающий
+// переводит её в код ответа так же, как для отдельного эндпоинта операции.
+type BatchItem struct {
+	Op          models.BatchOperationOp
+	Status      models.BatchItemResultStatus
+	PullRequest *models.PullRequest
+	User        *models.User
+	Err         error
+}
+
+// RunBatch проверяет, что у всех операций есть нужные поля, и выполняет их по
+// порядку; каждая видит результаты предыдущих: созданные PR, назначения
+// ревьюверов, смену активности. С atomic все операции выполняются в одной
+// транзакции и при первой ошибке операции отменяются (committed=false); сбой
+// базы в этом режиме возвращается ошибкой, а не результатом операции. Без
+// atomic каждая операция выполняется в своей транзакции: ошибка одной, в том
+// числе сбой её транзакции, записывается в её результат и не мешает
+// остальным, а уже выполненные операции остаются в ответе.
+func (s *Service) RunBatch(ctx context.Context, ops []models.BatchOperation, atomic bool) (items []BatchItem, committed bool, err error) {
+	if len(ops) == 0 || len(ops) > maxBatchOperations {
+		return nil, false, fmt.Errorf("%w: batch must contain 1 to %d operations", ErrInvalidInput, maxBatchOperations)
+	}
+	items = make([]BatchItem, len(ops))
+	for i, op := range ops {
+		if err := validateOperation(op); err != nil {
+			return nil, false, fmt.Errorf("%w: operations[%d]: %s", ErrInvalidInput, i, err)
+		}
+		items[i] = BatchItem{Op: op.Op, Status: models.BatchItemResultStatusSkipped}
+	}
+
+	if !atomic {
+		for i, op := range ops {
+			// Оставшиеся операции остаются skipped.
+			if ctx.Err() != nil {
+				break
+			}
+			items[i] = s.runOperation(ctx, op)
+		}
+		return items, true, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	for i, op := range ops {
+		item := s.applyOperation(ctx, tx, op)
+		if item.Err != nil {
+			if !isServiceError(item.Err) {
+				return nil, false, item.Err
+			}
+			items[i] = item
+			for j := range i {
+				items[j].Status = models.BatchItemResultStatusRolledBack
+				items[j].PullRequest, items[j].User = nil, nil
+			}
+			return items, false, nil
+		}
+		items[i] = item
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+	return items, true, nil
+}
+
+// runOperation выполняет одну операцию в отдельной транзакции. Сбой самой
+// транзакции записывается в BatchItem.Err так же, как ошибка операции.
+func (s *Service) runOperation(ctx context.Context, op models.BatchOperation) BatchItem {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return BatchItem{Op: op.Op, Status: models.BatchItemResultStatusFailed, Err: err}
+	}
+	defer tx.Rollback()
+
+	item := s.applyOperation(ctx, tx, op)
+	if item.Err != nil {
+		return item
+	}
+	if err := tx.Commit(); err != nil {
+		return BatchItem{Op: op.Op, Status: models.BatchItemResultStatusFailed, Err: err}
+	}
+	return item
+}
+
+// isServiceError сообщает, что err — ошибка операции (нарушено условие
+// сервиса), а не сбой базы.
+func isServiceError(err error) bool {
+	for _, target := range []error{ErrNotFound, ErrConflict, ErrInvalidInput, ErrPrecondition, ErrNoCandidate} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateOperation проверяет, что заданы поля, нужные операции.
+func validateOperation(op models.BatchOperation) error {
+	var missing bool
+	switch op.Op {
+	case models.BatchOperationOpCreate:
+		missing = op.PullRequestId == nil || op.PullRequestName == nil || op.AuthorId == nil
+	case models.BatchOperationOpMerge:
+		missing = op.PullRequestId == nil
+	case models.BatchOperationOpReassign:
+		missing = op.PullRequestId == nil || op.OldUserId == nil
+	case models.BatchOperationOpSetIsActive:
+		missing = op.UserId == nil || op.IsActive == nil
+	default:
+		return fmt.Errorf("unknown op %q", op.Op)
+	}
+	if missing {
+		return fmt.Errorf("missing required fields for op %s", op.Op)
+	}
+	return nil
+}
+
+// applyOperation выполняет проверенную операцию пакета в рамках транзакции tx
+// и читает результат через неё же.
+func (s *Service) applyOperation(ctx context.Context, tx *sql.Tx, op models.BatchOperation) BatchItem {
+	item := BatchItem{Op: op.Op, Status: models.BatchItemResultStatusOk}
+	var err error
+	switch op.Op {
+	case models.BatchOperationOpCreate:
+		err = s.createPullRequest(ctx, tx, models.PostPullRequestCreateJSONRequestBody{
+			PullRequestId:   *op.PullRequestId,
+			PullRequestName: *op.PullRequestName,
+			AuthorId:        *op.AuthorId,
+			TeamName:        op.TeamName,
+		})
+		if err == nil {
+			item.PullRequest, err = loadPullRequest(ctx, tx, *op.PullRequestId)
+		}
+	case models.BatchOperationOpMerge:
+		err = s.mergePullRequest(ctx, tx, *op.PullRequestId)
+		if err == nil {
+			item.PullRequest, err = loadPullRequest(ctx, tx, *op.PullRequestId)
+		}
+	case models.BatchOperationOpReassign:
+		err = s.reassignReviewer(ctx, tx, models.PostPullRequestReassignJSONRequestBody{
+			PullRequestId: *op.PullRequestId,
+			OldUserId:     *op.OldUserId,
+		})
+		if err == nil {
+			item.PullRequest, err = loadPullRequest(ctx, tx, *op.PullRequestId)
+		}
+	case models.BatchOperationOpSetIsActive:
+		err = s.setUserActive(ctx, tx, models.PostUsersSetIsActiveJSONRequestBody{UserId: *op.UserId, IsActive: *op.IsActive})
+		if err == nil {
+			item.User, err = loadUser(ctx, tx, *op.UserId)
+		}
+	}
+	if err != nil {
+		return BatchItem{Op: op.Op, Status: models.BatchItemResultStatusFailed, Err: err}
+	}
+	return item
+}
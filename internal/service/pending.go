@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"pull-request-api.com/internal/models"
+)
+
+// FreesCapacity сообщает, что после события ev у ожидающих слотов могут
+// появиться кандидаты: пользователь снова активен, а мерж и снятие ревьювера
+// уменьшают чью-то загрузку.
+func FreesCapacity(ev models.Event) bool {
+	switch ev.Type {
+	case models.EventTypeUserActivated, models.EventTypePRMerged, models.EventTypeReviewerRemoved:
+		return true
+	}
+	return false
+}
+
+// FillPendingReviewers заполняет ожидающие слоты ревьюверов открытых PR, от
+// старых к новым, по тем же правилам, что и при создании PR. Каждый PR
+// обрабатывается в своей транзакции; ошибка одного PR не мешает остальным и
+// возвращается вместе с ошибками других. Возвращает число назначенных
+// ревьюверов.
+func (s *Service) FillPendingReviewers(ctx context.Context) (int, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT pull_request_id FROM pull_requests
+		WHERE pending_reviewers > 0 AND status = $1 ORDER BY created_at, pull_request_id`, models.PullRequestStatusOPEN)
+	if err != nil {
+		return 0, err
+	}
+	var prIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		prIDs = append(prIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	filled := 0
+	var errs []error
+	for _, prID := range prIDs {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+		n, err := s.fillPendingInTx(ctx, prID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("pull request %s: %w", prID, err))
+			continue
+		}
+		filled += n
+	}
+	return filled, errors.Join(errs...)
+}
+
+func (s *Service) fillPendingInTx(ctx context.Context, prID string) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	n, err := s.fillPending(ctx, tx, prID)
+	if err != nil || n == 0 {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
+// fillPending назначает ревьюверов на ожидающие слоты PR prID. Состояние PR
+// перечитывается под блокировкой: слоты могли заполнить параллельно.
+func (s *Service) fillPending(ctx context.Context, tx *sql.Tx, prID string) (int, error) {
+	var (
+		authorID string
+		team     sql.NullString
+		status   models.PullRequestStatus
+		pending  int
+	)
+	err := tx.QueryRowContext(ctx, `SELECT author_id, team_name, status, pending_reviewers FROM pull_requests
+		WHERE pull_request_id = $1 FOR UPDATE`, prID).Scan(&authorID, &team, &status, &pending)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	if status != models.PullRequestStatusOPEN || pending <= 0 || !team.Valid {
+		return 0, nil
+	}
+
+	exclude, err := prReviewerIDs(ctx, tx, prID)
+	if err != nil {
+		return 0, err
+	}
+	chosen, sel, err := s.selectReviewers(ctx, tx, team.String, authorID, exclude, pending)
+	if errors.Is(err, ErrNoCandidate) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	if len(chosen) == 0 {
+		return 0, nil
+	}
+
+	for _, rev := range chosen {
+		err := s.emit(ctx, tx, aggregatePR, prID, eventReviewerAssigned, reviewerData{ReviewerID: rev, Selection: sel})
+		if err != nil {
+			return 0, err
+		}
+	}
+	err = s.emit(ctx, tx, aggregatePR, prID, eventReviewersPending, pendingData{PendingReviewers: pending - len(chosen)})
+	if err != nil {
+		return 0, err
+	}
+	return len(chosen), nil
+}
+
+// addPendingReviewers меняет число ожидающих слотов PR prID на delta.
+func (s *Service) addPendingReviewers(ctx context.Context, tx *sql.Tx, prID string, delta int) error {
+	var pending int
+	err := tx.QueryRowContext(ctx, `SELECT pending_reviewers FROM pull_requests WHERE pull_request_id = $1`, prID).Scan(&pending)
+	if err != nil {
+		return err
+	}
+	return s.emit(ctx, tx, aggregatePR, prID, eventReviewersPending, pendingData{PendingReviewers: max(pending+delta, 0)})
+}
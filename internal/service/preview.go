@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"pull-request-api.com/internal/models"
+)
+
+// PreviewAssignment выполняет выбор ревьюверов так же, как CreatePullRequest,
+// но ничего не записывает: транзакция всегда откатывается. Каждый вызов
+// разыгрывает новое зерно из генератора пробных выборов, поэтому при избытке
+// кандидатов реальное создание PR может выбрать других ревьюверов из числа
+// eligible, а сам предпросмотр не сдвигает зёрна реальных назначений.
+func (s *Service) PreviewAssignment(ctx context.Context, req models.PostPullRequestPreviewAssignmentJSONRequestBody) (*models.AssignmentPreview, error) {
+	ctx = withDryRun(ctx)
+	// Предпросмотр только читает и ничего не блокирует: реальные назначения
+	// его не ждут.
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if req.PullRequestId != nil {
+		var exists bool
+		err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)`, *req.PullRequestId).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, ErrConflict
+		}
+	}
+	prTeam, err := authorPRTeam(ctx, tx, req.AuthorId, req.TeamName)
+	if err != nil {
+		return nil, err
+	}
+
+	// При capacity_policy=fail создание PR будет отклонено: выбор пуст, а
+	// причины видны в candidates.
+	chosen, sel, candidates, err := s.planReviewers(ctx, tx, prTeam, req.AuthorId, nil, reviewersPerPR)
+	if err != nil && !errors.Is(err, ErrNoCandidate) {
+		return nil, err
+	}
+	if chosen == nil {
+		chosen = []string{}
+	}
+	return &models.AssignmentPreview{
+		TeamName:   prTeam,
+		Reviewers:  chosen,
+		Selection:  *sel,
+		Candidates: candidates,
+	}, nil
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"pull-request-api.com/internal/models"
+)
+
+// CreateUser заводит пользователя без команды (например, при провижининге из
+// SCIM до того, как его добавят в группу). Существующий user_id, в том числе
+// обезличенный, — ErrConflict.
+func (s *Service) CreateUser(ctx context.Context, user models.User) (*models.User, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO users (user_id, username, is_active) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO NOTHING`, user.UserId, user.Username, user.IsActive)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrConflict
+	}
+
+	err = audit(ctx, tx, "user.create", "user:"+user.UserId, map[string]any{
+		"username":  user.Username,
+		"is_active": user.IsActive,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.getUser(ctx, user.UserId)
+}
+
+// CreateTeam создаёт пустую команду и добавляет в неё существующих
+// пользователей. В отличие от AddTeam, пользователи не заводятся и не
+// изменяются; существующая команда — ErrConflict.
+func (s *Service) CreateTeam(ctx context.Context, teamName string, userIDs []string) (*models.Team, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO teams (team_name) VALUES ($1) ON CONFLICT (team_name) DO NOTHING`, teamName)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrConflict
+	}
+	if err := addMembers(ctx, tx, teamName, userIDs); err != nil {
+		return nil, err
+	}
+
+	err = audit(ctx, tx, "team.create", "team:"+teamName, map[string]any{"members": userIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.GetTeam(ctx, teamName)
+}
+
+// AddTeamMembers добавляет существующих пользователей в команду. Для
+// пользователя без команды она становится основной, для остальных —
+// дополнительной.
+func (s *Service) AddTeamMembers(ctx context.Context, teamName string, userIDs []string) (*models.Team, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockTeam(ctx, tx, teamName); err != nil {
+		return nil, err
+	}
+	if err := addMembers(ctx, tx, teamName, userIDs); err != nil {
+		return nil, err
+	}
+
+	err = audit(ctx, tx, "team.add_members", "team:"+teamName, map[string]any{"members": userIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.GetTeam(ctx, teamName)
+}
+
+func addMembers(ctx context.Context, tx *sql.Tx, teamName string, userIDs []string) error {
+	for _, uid := range userIDs {
+		res, err := tx.ExecContext(ctx, `UPDATE users SET team_name = COALESCE(team_name, $1)
+			WHERE user_id = $2 AND deleted_at IS NULL`, teamName, uid)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return fmt.Errorf("%w: user %q", ErrNotFound, uid)
+		}
+
+		_, err = tx.ExecContext(ctx, `INSERT INTO team_members (team_name, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			teamName, uid)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveTeamMembers исключает пользователей из команды, в том числе основной:
+// основной становится другая команда пользователя (первая по имени), а без
+// других команд он остаётся без команды. Ревью на PR этой команды
+// обрабатываются по review_policy. Пользователи не из команды пропускаются.
+func (s *Service) RemoveTeamMembers(ctx context.Context, teamName string, userIDs []string, policy models.ReviewPolicy) ([]models.ReviewReassignment, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockTeam(ctx, tx, teamName); err != nil {
+		return nil, err
+	}
+
+	reviews := []models.ReviewReassignment{}
+	var removed []string
+	for _, uid := range userIDs {
+		res, err := tx.ExecContext(ctx, `DELETE FROM team_members WHERE team_name = $1 AND user_id = $2`, teamName, uid)
+		if err != nil {
+			return nil, err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			continue
+		}
+		removed = append(removed, uid)
+
+		_, err = tx.ExecContext(ctx, `UPDATE users SET team_name =
+			(SELECT MIN(team_name) FROM team_members WHERE user_id = $1)
+			WHERE user_id = $1 AND team_name = $2`, uid, teamName)
+		if err != nil {
+			return nil, err
+		}
+
+		r, err := s.releaseReviews(ctx, tx, uid, teamName, teamName, policy)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, r...)
+	}
+
+	err = audit(ctx, tx, "team.remove_members", "team:"+teamName, map[string]any{
+		"members":       removed,
+		"review_policy": policy,
+		"reviews":       reviews,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
+
+// ListTeams возвращает все команды с участниками, упорядоченные по имени.
+func (s *Service) ListTeams(ctx context.Context) ([]models.Team, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT t.team_name, t.parent_team, t.escalation_policy, u.user_id, u.username, u.is_active
+		FROM teams t
+		LEFT JOIN team_members tm ON tm.team_name = t.team_name
+		LEFT JOIN users u ON u.user_id = tm.user_id
+		ORDER BY t.team_name, u.user_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := []models.Team{}
+	for rows.Next() {
+		var (
+			name, policy string
+			parent       sql.NullString
+			uid, uname   sql.NullString
+			active       sql.NullBool
+		)
+		if err := rows.Scan(&name, &parent, &policy, &uid, &uname, &active); err != nil {
+			return nil, err
+		}
+		if len(teams) == 0 || teams[len(teams)-1].TeamName != name {
+			p := models.EscalationPolicy(policy)
+			teams = append(teams, models.Team{
+				TeamName:         name,
+				ParentTeam:       nullable(parent),
+				EscalationPolicy: &p,
+				Members:          []models.TeamMember{},
+			})
+		}
+		if uid.Valid {
+			t := &teams[len(teams)-1]
+			t.Members = append(t.Members, models.TeamMember{UserId: uid.String, Username: uname.String, IsActive: active.Bool})
+		}
+	}
+	return teams, rows.Err()
+}
@@ -0,0 +1,378 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand"
+	"slices"
+
+	"pull-request-api.com/internal/models"
+)
+
+// reviewersPerPR — сколько ревьюверов назначается на новый PR.
+const reviewersPerPR = 2
+
+// selectionStrategy — алгоритм выбора, записываемый с каждым решением. При
+// изменении алгоритма заводится новое имя, а ReplaySelection сохраняет старый,
+// чтобы записанные решения воспроизводились.
+const selectionStrategy = "shuffle-v1"
+
+// selectReviewers выбирает до n случайных активных ревьюверов из команды team,
+// исключая автора, exclude и достигших лимита открытых ревью. Если в команде не
+// хватает кандидатов, недостающие добираются из команд, заданных политикой
+// эскалации team. Оставшиеся слоты, которые можно заполнить только сверх
+// лимита, обрабатываются по capacity_policy team: least_loaded назначает
+// наименее загруженных, queue оставляет слоты пустыми, fail возвращает
+// ErrNoCandidate. Вместе с выбором возвращается запись решения для события
+// ReviewerAssigned.
+//
+// Перед выбором lockCandidates блокирует автора и всех возможных кандидатов,
+// поэтому загрузка, активность и удаление кандидатов читаются уже под
+// блокировкой, и параллельный выбор не превысит лимит.
+func (s *Service) selectReviewers(ctx context.Context, tx *sql.Tx, team, authorID string, exclude []string, n int) ([]string, *models.ReviewerSelection, error) {
+	if err := lockCandidates(ctx, tx, team, authorID); err != nil {
+		return nil, nil, err
+	}
+	chosen, sel, _, err := s.planReviewers(ctx, tx, team, authorID, exclude, n)
+	return chosen, sel, err
+}
+
+// lockCandidates блокирует до конца транзакции tx строки автора и участников
+// team и команд её ступеней эскалации. Все строки берутся одним запросом в
+// порядке user_id: параллельные выборы в пересекающихся командах (в том числе
+// когда авторы выбирают друг друга) выполняются по очереди, а не ждут друг
+// друга по кругу.
+func lockCandidates(ctx context.Context, tx *sql.Tx, team, authorID string) error {
+	tiers, err := escalationTiers(ctx, tx, team)
+	if err != nil {
+		return err
+	}
+	teams := []any{team}
+	for _, tier := range tiers {
+		for _, t := range tier {
+			teams = append(teams, t)
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT user_id FROM users
+		WHERE user_id = $1 OR user_id IN (SELECT user_id FROM team_members WHERE team_name IN (`+inList(2, len(teams))+`))
+		ORDER BY user_id FOR UPDATE`, append([]any{authorID}, teams...)...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+	}
+	return rows.Err()
+}
+
+// planReviewers выполняет выбор selectReviewers и дополнительно возвращает
+// всех участников просмотренных ступеней с причиной включения или исключения.
+// При capacity_policy=fail вместе с ErrNoCandidate возвращаются пустой выбор и
+// причины решений, чтобы предпросмотр показал, почему создание будет отклонено.
+func (s *Service) planReviewers(ctx context.Context, tx *sql.Tx, team, authorID string, exclude []string, n int) ([]string, *models.ReviewerSelection, []models.AssignmentCandidate, error) {
+	tiers, err := escalationTiers(ctx, tx, team)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	sel := &models.ReviewerSelection{Strategy: selectionStrategy, Seed: s.nextSeed(ctx), Count: n, Candidates: [][]string{}}
+	skip := slices.Clone(exclude)
+	decisions := []models.AssignmentCandidate{}
+	var (
+		chosen   []string
+		overflow []teamMember
+		capped   = map[string]bool{}
+	)
+	for i, tier := range append([][]string{{team}}, tiers...) {
+		if len(chosen) >= n {
+			break
+		}
+
+		candidates := []string{}
+		seen := map[string]bool{}
+		for _, t := range tier {
+			members, err := teamMembers(ctx, tx, t)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			for _, m := range members {
+				if seen[m.userID] {
+					continue
+				}
+				seen[m.userID] = true
+
+				d := models.AssignmentCandidate{UserId: m.userID, TeamName: t, Tier: i}
+				switch {
+				case m.userID == authorID:
+					d.Reason = models.AssignmentCandidateReasonAuthor
+				case slices.Contains(skip, m.userID):
+					d.Reason = models.AssignmentCandidateReasonAlreadyAssigned
+				case !m.active:
+					d.Reason = models.AssignmentCandidateReasonInactive
+				case m.atCapacity():
+					d.Reason = models.AssignmentCandidateReasonAtCapacity
+					if !capped[m.userID] {
+						capped[m.userID] = true
+						overflow = append(overflow, m)
+					}
+				default:
+					d.Included = true
+					candidates = append(candidates, m.userID)
+				}
+				decisions = append(decisions, d)
+			}
+		}
+		// Кандидаты ступени, не попавшие в выбор, остаются только если выбор
+		// уже полон, поэтому исключать из следующих ступеней можно всех.
+		skip = append(skip, candidates...)
+		sel.Candidates = append(sel.Candidates, candidates)
+		chosen = ReplaySelection(*sel)
+	}
+
+	var failed bool
+	if len(chosen) < n && len(overflow) > 0 {
+		policy, err := capacityPolicy(ctx, tx, team)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		switch policy {
+		case models.CapacityPolicyFail:
+			failed = true
+			sel.Candidates = [][]string{}
+			chosen = nil
+		case models.CapacityPolicyLeastLoaded:
+			// Сортировка устойчивая: при равной загрузке — по ступени и user_id.
+			slices.SortStableFunc(overflow, func(a, b teamMember) int { return cmp.Compare(a.open, b.open) })
+			for _, m := range overflow[:min(len(overflow), n-len(chosen))] {
+				sel.Overflow = append(sel.Overflow, m.userID)
+			}
+			chosen = ReplaySelection(*sel)
+		}
+	}
+
+	for i := range decisions {
+		switch {
+		case decisions[i].Reason == models.AssignmentCandidateReasonAtCapacity && slices.Contains(sel.Overflow, decisions[i].UserId):
+			decisions[i].Included = true
+			decisions[i].Reason = models.AssignmentCandidateReasonSelected
+		case !decisions[i].Included:
+		case slices.Contains(chosen, decisions[i].UserId):
+			decisions[i].Reason = models.AssignmentCandidateReasonSelected
+		default:
+			decisions[i].Reason = models.AssignmentCandidateReasonEligible
+		}
+	}
+	if failed {
+		return nil, sel, decisions, ErrNoCandidate
+	}
+	return chosen, sel, decisions, nil
+}
+
+// dryRunKey помечает контекст пробного выполнения, результат которого
+// откатывается (dry run синхронизации, предпросмотр назначения).
+type dryRunKey struct{}
+
+// withDryRun помечает ctx как пробное выполнение: зёрна выбора в нём берутся
+// из отдельного генератора, иначе каждый пробный выбор менял бы зёрна всех
+// последующих реальных назначений при заданном WithSeed.
+func withDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// nextSeed выдаёт зерно для очередного решения из источника сервиса.
+func (s *Service) nextSeed(ctx context.Context) int64 {
+	s.rngMu.Lock()
+	defer s.rngMu.Unlock()
+	if ctx.Value(dryRunKey{}) != nil {
+		return s.dryRunRng.Int63()
+	}
+	return s.rng.Int63()
+}
+
+// ReplaySelection повторяет записанный выбор ревьюверов: перемешивает
+// кандидатов каждой ступени генератором с зерном решения и берёт первых,
+// пока не наберётся Count, затем добирает из Overflow. Для неизвестной
+// стратегии возвращает nil.
+func ReplaySelection(sel models.ReviewerSelection) []string {
+	if sel.Strategy != selectionStrategy {
+		return nil
+	}
+	rng := rand.New(rand.NewSource(sel.Seed))
+	chosen := []string{}
+	for _, tier := range sel.Candidates {
+		if len(chosen) >= sel.Count {
+			break
+		}
+		candidates := slices.Clone(tier)
+		rng.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+		chosen = append(chosen, candidates[:min(len(candidates), sel.Count-len(chosen))]...)
+	}
+	if len(chosen) < sel.Count {
+		chosen = append(chosen, sel.Overflow[:min(len(sel.Overflow), sel.Count-len(chosen))]...)
+	}
+	return chosen
+}
+
+// escalationTiers возвращает группы команд, из которых по очереди добираются
+// ревьюверы, если в самой команде их не хватило.
+func escalationTiers(ctx context.Context, tx *sql.Tx, team string) ([][]string, error) {
+	var policy string
+	var parent sql.NullString
+	err := tx.QueryRowContext(ctx, `SELECT escalation_policy, parent_team FROM teams WHERE team_name = $1`, team).
+		Scan(&policy, &parent)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if !parent.Valid || models.EscalationPolicy(policy) == models.EscalationPolicyNone {
+		return nil, nil
+	}
+
+	var siblings []string
+	if policy == string(models.EscalationPolicySiblings) || policy == string(models.EscalationPolicySiblingsThenParent) {
+		rows, err := tx.QueryContext(ctx, `SELECT team_name FROM teams WHERE parent_team = $1 AND team_name != $2 ORDER BY team_name`,
+			parent.String, team)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var t string
+			if err := rows.Scan(&t); err != nil {
+				return nil, err
+			}
+			siblings = append(siblings, t)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	switch models.EscalationPolicy(policy) {
+	case models.EscalationPolicySiblings:
+		return [][]string{siblings}, nil
+	case models.EscalationPolicyParent:
+		return [][]string{{parent.String}}, nil
+	case models.EscalationPolicySiblingsThenParent:
+		return [][]string{siblings, {parent.String}}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// teamMember — участник команды с признаком активности и загрузкой.
+type teamMember struct {
+	userID string
+	active bool
+	open   int           // открытых ревью
+	limit  sql.NullInt64 // лимит пользователя или его основной команды
+}
+
+func (m teamMember) atCapacity() bool {
+	return m.limit.Valid && int64(m.open) >= m.limit.Int64
+}
+
+// teamMembers возвращает участников команды team в порядке user_id.
+func teamMembers(ctx context.Context, tx *sql.Tx, team string) ([]teamMember, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT u.user_id, u.is_active, COALESCE(u.max_open_reviews, pt.default_max_open_reviews),
+			(SELECT COUNT(*) FROM pr_reviewers r JOIN pull_requests p ON p.pull_request_id = r.pull_request_id
+				WHERE r.reviewer_id = u.user_id AND p.status = 'OPEN')
+		FROM team_members tm
+		JOIN users u ON u.user_id = tm.user_id
+		LEFT JOIN teams pt ON pt.team_name = u.team_name
+		WHERE tm.team_name = $1 AND u.deleted_at IS NULL ORDER BY u.user_id`, team)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []teamMember
+	for rows.Next() {
+		var m teamMember
+		if err := rows.Scan(&m.userID, &m.active, &m.limit, &m.open); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// capacityPolicy возвращает политику команды team для слотов, которые можно
+// заполнить только сверх лимита открытых ревью.
+func capacityPolicy(ctx context.Context, tx *sql.Tx, team string) (models.CapacityPolicy, error) {
+	var policy models.CapacityPolicy
+	err := tx.QueryRowContext(ctx, `SELECT capacity_policy FROM teams WHERE team_name = $1`, team).Scan(&policy)
+	if err == sql.ErrNoRows {
+		return models.CapacityPolicyLeastLoaded, nil
+	}
+	return policy, err
+}
+
+// pickReplacement выбирает замену ревьюверу oldUserID на PR prID из команды team
+// (с учётом эскалации). Автор PR, уже назначенные ревьюверы и сам oldUserID
+// исключаются. Возвращает замену и запись решения; если кандидатов нет (в том
+// числе при capacity_policy=fail) — пустую строку. queued сообщает, что замены
+// нет только из-за лимита и по capacity_policy=queue слот остаётся в очереди.
+func (s *Service) pickReplacement(ctx context.Context, tx *sql.Tx, prID, team, oldUserID string) (newRev string, sel *models.ReviewerSelection, queued bool, err error) {
+	var authorID string
+	err = tx.QueryRowContext(ctx, `SELECT author_id FROM pull_requests WHERE pull_request_id = $1`, prID).Scan(&authorID)
+	if err == sql.ErrNoRows {
+		return "", nil, false, ErrNotFound
+	} else if err != nil {
+		return "", nil, false, err
+	}
+
+	exclude, err := prReviewerIDs(ctx, tx, prID)
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	if err := lockCandidates(ctx, tx, team, authorID); err != nil {
+		return "", nil, false, err
+	}
+	chosen, sel, decisions, err := s.planReviewers(ctx, tx, team, authorID, append(exclude, oldUserID), 1)
+	if errors.Is(err, ErrNoCandidate) {
+		return "", nil, false, nil
+	}
+	if err != nil {
+		return "", nil, false, err
+	}
+	if len(chosen) > 0 {
+		return chosen[0], sel, false, nil
+	}
+
+	capped := slices.ContainsFunc(decisions, func(d models.AssignmentCandidate) bool {
+		return d.Reason == models.AssignmentCandidateReasonAtCapacity
+	})
+	if !capped {
+		return "", nil, false, nil
+	}
+	policy, err := capacityPolicy(ctx, tx, team)
+	if err != nil {
+		return "", nil, false, err
+	}
+	return "", nil, policy == models.CapacityPolicyQueue, nil
+}
+
+func prReviewerIDs(ctx context.Context, tx *sql.Tx, prID string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT reviewer_id FROM pr_reviewers WHERE pull_request_id = $1`, prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var uid string
+		if err := rows.Scan(&uid); err != nil {
+			return nil, err
+		}
+		ids = append(ids, uid)
+	}
+	return ids, rows.Err()
+}
@@ -3,18 +3,65 @@ package service
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"math/rand"
+	"sync"
 	"time"
 
+	"pull-request-api.com/internal/database"
 	"pull-request-api.com/internal/models"
 )
 
 type Service struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect database.Dialect
+	now     func() time.Time
+
+	rngMu sync.Mutex
+	rng   *rand.Rand
+	// dryRunRng — зёрна для пробных выборов (см. withDryRun), чтобы они не
+	// сдвигали последовательность rng.
+	dryRunRng *rand.Rand
 }
 
-func NewService(db *sql.DB) *Service {
-	return &Service{db: db}
+// Option настраивает Service.
+type Option func(*Service)
+
+// WithRandSource задаёт источник случайности для выбора ревьюверов. Из него
+// берётся зерно каждого решения, которое записывается вместе с назначением
+// (см. ReplaySelection).
+func WithRandSource(src rand.Source) Option {
+	return func(s *Service) {
+		s.rng = rand.New(src)
+	}
+}
+
+// WithSeed делает выбор ревьюверов воспроизводимым: при тех же данных и том же
+// порядке запросов назначения повторяются от запуска к запуску.
+func WithSeed(seed int64) Option {
+	return WithRandSource(rand.NewSource(seed))
+}
+
+// WithClock задаёт часы, по которым проставляется время событий.
+func WithClock(now func() time.Time) Option {
+	return func(s *Service) {
+		s.now = now
+	}
+}
+
+func NewService(db *sql.DB, opts ...Option) *Service {
+	s := &Service{
+		db:      db,
+		dialect: database.DialectOf(db),
+		now:     time.Now,
+		rng:     rand.New(rand.NewSource(time.Now().UnixNano())),
+
+		dryRunRng: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *Service) CreatePullRequest(ctx context.Context, req models.PostPullRequestCreateJSONRequestBody) (*models.PullRequest, error) {
@@ -24,60 +71,90 @@ func (s *Service) CreatePullRequest(ctx context.Context, req models.PostPullRequ
 	}
 	defer tx.Rollback()
 
-	var exists bool
-	err = tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)`, req.PullRequestId).Scan(&exists)
-	if err != nil {
+	if err := s.createPullRequest(ctx, tx, req); err != nil {
 		return nil, err
 	}
-	if exists {
-		return nil, ErrConflict
-	}
-	var authorTeam string
-	err = tx.QueryRowContext(ctx, `SELECT team_name FROM users WHERE user_id = $1 FOR UPDATE`, req.AuthorId).Scan(&authorTeam)
-	if err == sql.ErrNoRows {
-		return nil, ErrNotFound
-	} else if err != nil {
+	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
 
-	_, err = tx.ExecContext(ctx, `INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status) 
-		VALUES ($1, $2, $3, $4)`,
-		req.PullRequestId, req.PullRequestName, req.AuthorId, models.PullRequestStatusOPEN)
+	return s.getPullRequest(ctx, req.PullRequestId)
+}
+
+// createPullRequest создаёт PR и назначает ревьюверов в рамках транзакции tx.
+func (s *Service) createPullRequest(ctx context.Context, tx *sql.Tx, req models.PostPullRequestCreateJSONRequestBody) error {
+	var exists bool
+	err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)`, req.PullRequestId).Scan(&exists)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if exists {
+		return ErrConflict
 	}
-	rows, err := tx.QueryContext(ctx, `SELECT user_id FROM users 
-		WHERE team_name = $1 AND is_active = TRUE AND user_id != $2`, authorTeam, req.AuthorId)
+	prTeam, err := authorPRTeam(ctx, tx, req.AuthorId, req.TeamName)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
 
-	var candidates []string
-	for rows.Next() {
-		var uid string
-		if err := rows.Scan(&uid); err != nil {
-			return nil, err
-		}
-		candidates = append(candidates, uid)
+	// Выбор (и блокировка автора с кандидатами) — до вставки PR: проверка
+	// внешнего ключа на автора заняла бы его строку раньше общего упорядоченного
+	// захвата в selectReviewers.
+	candidates, sel, err := s.selectReviewers(ctx, tx, prTeam, req.AuthorId, nil, reviewersPerPR)
+	if err != nil {
+		return err
 	}
-	rand.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
-	if len(candidates) > 2 {
-		candidates = candidates[:2]
+	err = s.emit(ctx, tx, aggregatePR, req.PullRequestId, eventPRCreated, prCreatedData{
+		PullRequestName: req.PullRequestName,
+		AuthorID:        req.AuthorId,
+		TeamName:        &prTeam,
+	})
+	if err != nil {
+		return err
 	}
 
 	for _, rev := range candidates {
-		_, err := tx.ExecContext(ctx, `INSERT INTO pr_reviewers (pull_request_id, reviewer_id) VALUES ($1, $2)`, req.PullRequestId, rev)
+		err := s.emit(ctx, tx, aggregatePR, req.PullRequestId, eventReviewerAssigned, reviewerData{ReviewerID: rev, Selection: sel})
 		if err != nil {
-			return nil, err
+			return err
 		}
 	}
+	if missing := reviewersPerPR - len(candidates); missing > 0 {
+		return s.emit(ctx, tx, aggregatePR, req.PullRequestId, eventReviewersPending, pendingData{PendingReviewers: missing})
+	}
+	return nil
+}
 
-	if err := tx.Commit(); err != nil {
-		return nil, err
+// authorPRTeam определяет команду PR автора authorID: teamName, если он задан,
+// иначе основную команду автора. Автор должен состоять в выбранной команде.
+// Строка автора не блокируется: при создании PR её вместе с кандидатами
+// блокирует selectReviewers, а предпросмотр ничего не блокирует.
+func authorPRTeam(ctx context.Context, tx *sql.Tx, authorID string, teamName *string) (string, error) {
+	var authorTeam string
+	err := tx.QueryRowContext(ctx, `SELECT COALESCE(team_name, '') FROM users WHERE user_id = $1 AND deleted_at IS NULL`, authorID).Scan(&authorTeam)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	} else if err != nil {
+		return "", err
 	}
 
-	return s.getPullRequest(ctx, req.PullRequestId)
+	if authorTeam == "" && teamName == nil {
+		// Пользователь выведен из оргструктуры (см. SyncOrg).
+		return "", fmt.Errorf("%w: author has no team", ErrInvalidInput)
+	}
+	prTeam := authorTeam
+	if teamName != nil && *teamName != authorTeam {
+		prTeam = *teamName
+		var member bool
+		err = tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM team_members WHERE team_name = $1 AND user_id = $2)`,
+			prTeam, authorID).Scan(&member)
+		if err != nil {
+			return "", err
+		}
+		if !member {
+			return "", fmt.Errorf("%w: author is not a member of team %q", ErrInvalidInput, prTeam)
+		}
+	}
+	return prTeam, nil
 }
 
 func (s *Service) MergePullRequest(ctx context.Context, prID string) (*models.PullRequest, error) {
@@ -87,28 +164,32 @@ func (s *Service) MergePullRequest(ctx context.Context, prID string) (*models.Pu
 	}
 	defer tx.Rollback()
 
-	var status string
-	err = tx.QueryRowContext(ctx, `SELECT status FROM pull_requests WHERE pull_request_id = $1`, prID).Scan(&status)
-	if err != nil {
+	if err := s.mergePullRequest(ctx, tx, prID); err != nil {
 		return nil, err
 	}
-
-	if status == string(models.PullRequestStatusMERGED) {
-		//идемптоичнсть
-		return s.getPullRequest(ctx, prID)
+	if err := tx.Commit(); err != nil {
+		return nil, err
 	}
 
-	_, err = tx.ExecContext(ctx, `UPDATE pull_requests SET status = $1, merged_at = CURRENT_TIMESTAMP 
-		WHERE pull_request_id = $2`, models.PullRequestShortStatusMERGED, prID)
-	if err != nil {
-		return nil, err
+	return s.getPullRequest(ctx, prID)
+}
+
+// mergePullRequest помечает PR как MERGED в рамках транзакции tx.
+func (s *Service) mergePullRequest(ctx context.Context, tx *sql.Tx, prID string) error {
+	var status string
+	err := tx.QueryRowContext(ctx, `SELECT status FROM pull_requests WHERE pull_request_id = $1 FOR UPDATE`, prID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	} else if err != nil {
+		return err
 	}
 
-	if err := tx.Commit(); err != nil {
-		return nil, err
+	if status == string(models.PullRequestStatusMERGED) {
+		//идемптоичнсть
+		return nil
 	}
 
-	return s.getPullRequest(ctx, prID)
+	return s.emit(ctx, tx, aggregatePR, prID, eventPRMerged, nil)
 }
 
 func (s *Service) ReassignReviewer(ctx context.Context, req models.PostPullRequestReassignJSONRequestBody) (*models.PullRequest, error) {
@@ -118,73 +199,67 @@ func (s *Service) ReassignReviewer(ctx context.Context, req models.PostPullReque
 	}
 	defer tx.Rollback()
 
+	if err := s.reassignReviewer(ctx, tx, req); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.getPullRequest(ctx, req.PullRequestId)
+}
+
+// reassignReviewer заменяет ревьювера PR в рамках транзакции tx.
+func (s *Service) reassignReviewer(ctx context.Context, tx *sql.Tx, req models.PostPullRequestReassignJSONRequestBody) error {
 	var status string
-	err = tx.QueryRowContext(ctx, `SELECT status FROM pull_requests WHERE pull_request_id = $1`, req.PullRequestId).Scan(&status)
+	err := tx.QueryRowContext(ctx, `SELECT status FROM pull_requests WHERE pull_request_id = $1 FOR UPDATE`, req.PullRequestId).Scan(&status)
 	if err == sql.ErrNoRows {
-		return nil, ErrNotFound
+		return ErrNotFound
 	} else if err != nil {
-		return nil, err
+		return err
 	}
 	if status == string(models.PullRequestStatusMERGED) {
-		return nil, ErrPrecondition
+		return ErrPrecondition
 	}
 	var assigned bool
 	err = tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM pr_reviewers WHERE pull_request_id = $1 AND reviewer_id = $2)`, req.PullRequestId, req.OldUserId).Scan(&assigned)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if !assigned {
-		return nil, ErrInvalidInput //нет юзера
+		return ErrInvalidInput //нет юзера
 	}
 
+	// Замена берётся из команды PR; для PR без команды — из основной команды ревьювера.
 	var oldTeam string
-	err = tx.QueryRowContext(ctx, `SELECT team_name FROM users WHERE user_id = $1`, req.OldUserId).Scan(&oldTeam)
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(pr.team_name, u.team_name, '') FROM users u, pull_requests pr
+		WHERE u.user_id = $1 AND pr.pull_request_id = $2
+	`, req.OldUserId, req.PullRequestId).Scan(&oldTeam)
 	if err == sql.ErrNoRows {
-		return nil, ErrNotFound
+		return ErrNotFound
 	} else if err != nil {
-		return nil, err
+		return err
 	}
 
-	rows, err := tx.QueryContext(ctx, `
-		SELECT u.user_id FROM users u
-		WHERE u.team_name = $1 AND u.is_active = TRUE AND u.user_id != $2 AND u.user_id NOT IN 
-		(SELECT reviewer_id FROM pr_reviewers WHERE pull_request_id = $3)
-	`, oldTeam, req.OldUserId, req.PullRequestId)
+	newRev, sel, queued, err := s.pickReplacement(ctx, tx, req.PullRequestId, oldTeam, req.OldUserId)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var candidates []string
-	for rows.Next() {
-		var uid string
-		if err := rows.Scan(&uid); err != nil {
-			return nil, err
-		}
-		candidates = append(candidates, uid)
+		return err
 	}
-
-	if len(candidates) == 0 {
-		return nil, ErrConflict
+	if newRev == "" && !queued {
+		return ErrConflict
 	}
 
-	newRev := candidates[rand.Intn(len(candidates))]
-
-	_, err = tx.ExecContext(ctx, `DELETE FROM pr_reviewers WHERE pull_request_id = $1 AND reviewer_id = $2`, req.PullRequestId, req.OldUserId)
+	err = s.emit(ctx, tx, aggregatePR, req.PullRequestId, eventReviewerRemoved, reviewerData{ReviewerID: req.OldUserId})
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	_, err = tx.ExecContext(ctx, `INSERT INTO pr_reviewers (pull_request_id, reviewer_id) VALUES ($1, $2)`, req.PullRequestId, newRev)
-	if err != nil {
-		return nil, err
+	// Как и при создании PR, по capacity_policy=queue слот сверх лимита не
+	// заполняется, а ждёт освободившегося ревьювера.
+	if queued {
+		return s.addPendingReviewers(ctx, tx, req.PullRequestId, 1)
 	}
-
-	if err := tx.Commit(); err != nil {
-		return nil, err
-	}
-
-	return s.getPullRequest(ctx, req.PullRequestId)
+	return s.emit(ctx, tx, aggregatePR, req.PullRequestId, eventReviewerAssigned, reviewerData{ReviewerID: newRev, Selection: sel})
 }
 
 func (s *Service) AddTeam(ctx context.Context, team models.Team) error {
@@ -194,27 +269,116 @@ func (s *Service) AddTeam(ctx context.Context, team models.Team) error {
 	}
 	defer tx.Rollback()
 
-	_, err = tx.ExecContext(ctx, "INSERT INTO teams (team_name) VALUES ($1) ON CONFLICT (team_name) DO NOTHING", team.TeamName)
+	if err := s.addTeam(ctx, tx, team); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// addTeam создаёт команду (или дополняет существующую) и заводит/обновляет
+// участников в рамках транзакции tx.
+func (s *Service) addTeam(ctx context.Context, tx *sql.Tx, team models.Team) error {
+	_, err := tx.ExecContext(ctx, "INSERT INTO teams (team_name) VALUES ($1) ON CONFLICT (team_name) DO NOTHING", team.TeamName)
 	if err != nil {
 		return err
 	}
+	if team.ParentTeam != nil {
+		if err := setParent(ctx, tx, team.TeamName, team.ParentTeam); err != nil {
+			return err
+		}
+	}
+	if team.EscalationPolicy != nil {
+		_, err = tx.ExecContext(ctx, `UPDATE teams SET escalation_policy = $1 WHERE team_name = $2`,
+			string(*team.EscalationPolicy), team.TeamName)
+		if err != nil {
+			return err
+		}
+	}
+	if team.DefaultMaxOpenReviews != nil {
+		if *team.DefaultMaxOpenReviews < 0 {
+			return fmt.Errorf("%w: default_max_open_reviews must not be negative", ErrInvalidInput)
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE teams SET default_max_open_reviews = $1 WHERE team_name = $2`,
+			*team.DefaultMaxOpenReviews, team.TeamName)
+		if err != nil {
+			return err
+		}
+	}
+	if team.CapacityPolicy != nil {
+		if !validCapacityPolicy(*team.CapacityPolicy) {
+			return fmt.Errorf("%w: unknown capacity policy %q", ErrInvalidInput, *team.CapacityPolicy)
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE teams SET capacity_policy = $1 WHERE team_name = $2`,
+			string(*team.CapacityPolicy), team.TeamName)
+		if err != nil {
+			return err
+		}
+	}
 
+	membership := models.TeamMembershipPrimary
+	if team.Membership != nil {
+		membership = *team.Membership
+	}
+	if membership != models.TeamMembershipPrimary && membership != models.TeamMembershipAdditional {
+		return fmt.Errorf("%w: unknown membership %q", ErrInvalidInput, membership)
+	}
+
+	// Для новых пользователей команда всегда основная. Существующих при
+	// membership=primary переводим из прежней основной команды (так /team/add
+	// работал до дополнительных команд), при additional — только добавляем.
 	for _, m := range team.Members {
-		_, err := tx.ExecContext(ctx, `
+		var (
+			wasActive sql.NullBool
+			oldTeam   sql.NullString
+		)
+		err := tx.QueryRowContext(ctx, `SELECT is_active, team_name FROM users WHERE user_id = $1 FOR UPDATE`, m.UserId).
+			Scan(&wasActive, &oldTeam)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+
+		// Обезличенного пользователя (см. DeleteUser) повторно не заводим.
+		var uid string
+		err = tx.QueryRowContext(ctx, `
 			INSERT INTO users (user_id, username, team_name, is_active)
 			VALUES ($1, $2, $3, $4)
-			ON CONFLICT (user_id) DO UPDATE SET username = $2, team_name = $3, is_active = $4
-		`, m.UserId, m.Username, team.TeamName, m.IsActive)
+			ON CONFLICT (user_id) DO UPDATE SET username = $2, is_active = $4 WHERE users.deleted_at IS NULL
+			RETURNING user_id
+		`, m.UserId, m.Username, team.TeamName, m.IsActive).Scan(&uid)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: user %q is deleted", ErrInvalidInput, m.UserId)
+		} else if err != nil {
+			return err
+		}
+		if wasActive.Valid && membership == models.TeamMembershipPrimary && oldTeam.String != team.TeamName {
+			_, err = tx.ExecContext(ctx, `UPDATE users SET team_name = $1 WHERE user_id = $2`, team.TeamName, m.UserId)
+			if err != nil {
+				return err
+			}
+			if oldTeam.Valid {
+				_, err = tx.ExecContext(ctx, `DELETE FROM team_members WHERE team_name = $1 AND user_id = $2`, oldTeam.String, m.UserId)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		// Новый пользователь считается созданным активным.
+		if err := s.recordUserActivity(ctx, tx, m.UserId, !wasActive.Valid || wasActive.Bool, m.IsActive); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `INSERT INTO team_members (team_name, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			team.TeamName, m.UserId)
 		if err != nil {
 			return err
 		}
 	}
-
-	return tx.Commit()
+	return nil
 }
 
 func (s *Service) GetTeam(ctx context.Context, teamName string) (*models.Team, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT user_id, username, is_active FROM users WHERE team_name = $1`, teamName)
+	rows, err := s.db.QueryContext(ctx, `SELECT u.user_id, u.username, u.is_active FROM team_members tm
+		JOIN users u ON u.user_id = tm.user_id WHERE tm.team_name = $1`, teamName)
 	if err != nil {
 		return nil, err
 	}
@@ -232,14 +396,36 @@ func (s *Service) GetTeam(ctx context.Context, teamName string) (*models.Team, e
 		return nil, err
 	}
 
-	if len(members) == 0 {
+	// Команда может существовать и без участников (например, после их перевода).
+	var (
+		parent   sql.NullString
+		policy   models.EscalationPolicy
+		limit    sql.NullInt64
+		capacity models.CapacityPolicy
+	)
+	err = s.db.QueryRowContext(ctx, `SELECT parent_team, escalation_policy, default_max_open_reviews, capacity_policy
+		FROM teams WHERE team_name = $1`, teamName).
+		Scan(&parent, &policy, &limit, &capacity)
+	if err == sql.ErrNoRows {
 		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if members == nil {
+		members = []models.TeamMember{}
 	}
 
-	return &models.Team{
-		TeamName: teamName,
-		Members:  members,
-	}, nil
+	team := &models.Team{
+		TeamName:              teamName,
+		Members:               members,
+		EscalationPolicy:      &policy,
+		CapacityPolicy:        &capacity,
+		DefaultMaxOpenReviews: nullableInt(limit),
+	}
+	if parent.Valid {
+		team.ParentTeam = &parent.String
+	}
+	return team, nil
 }
 
 func (s *Service) GetUsersReviews(ctx context.Context, userID string) ([]models.PullRequestShort, error) {
@@ -274,28 +460,106 @@ func (s *Service) GetUsersReviews(ctx context.Context, userID string) ([]models.
 }
 
 func (s *Service) SetUserActive(ctx context.Context, req models.PostUsersSetIsActiveJSONRequestBody) (*models.User, error) {
-	res, err := s.db.ExecContext(ctx, `UPDATE users SET is_active = $1 WHERE user_id = $2`, req.IsActive, req.UserId)
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback()
+
+	if err := s.setUserActive(ctx, tx, req); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.getUser(ctx, req.UserId)
+}
+
+// setUserActive меняет флаг активности пользователя в рамках транзакции tx.
+func (s *Service) setUserActive(ctx context.Context, tx *sql.Tx, req models.PostUsersSetIsActiveJSONRequestBody) error {
+	var wasActive bool
+	err := tx.QueryRowContext(ctx, `SELECT is_active FROM users WHERE user_id = $1 AND deleted_at IS NULL FOR UPDATE`, req.UserId).Scan(&wasActive)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
 
-	rowsAffected, _ := res.RowsAffected()
-	if rowsAffected == 0 {
+	_, err = tx.ExecContext(ctx, `UPDATE users SET is_active = $1 WHERE user_id = $2`, req.IsActive, req.UserId)
+	if err != nil {
+		return err
+	}
+	return s.recordUserActivity(ctx, tx, req.UserId, wasActive, req.IsActive)
+}
+
+func (s *Service) getUser(ctx context.Context, userID string) (*models.User, error) {
+	return loadUser(ctx, s.db, userID)
+}
+
+// loadUser читает пользователя через q — базу или транзакцию.
+func loadUser(ctx context.Context, q querier, userID string) (*models.User, error) {
+	var (
+		user      models.User
+		team      sql.NullString
+		deletedAt sql.NullTime
+		limit     sql.NullInt64
+	)
+	err := q.QueryRowContext(ctx, `SELECT user_id, username, team_name, is_active, deleted_at, max_open_reviews FROM users WHERE user_id = $1`, userID).
+		Scan(&user.UserId, &user.Username, &team, &user.IsActive, &deletedAt, &limit)
+	if err == sql.ErrNoRows {
 		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	// У обезличенного пользователя нет команды.
+	user.TeamName = team.String
+	if deletedAt.Valid {
+		user.DeletedAt = &deletedAt.Time
 	}
+	user.MaxOpenReviews = nullableInt(limit)
 
-	var user models.User
-	err = s.db.QueryRowContext(ctx, `SELECT user_id, username, team_name, is_active FROM users WHERE user_id = $1`, req.UserId).
-		Scan(&user.UserId, &user.Username, &user.TeamName, &user.IsActive)
+	rows, err := q.QueryContext(ctx, `SELECT team_name FROM team_members WHERE user_id = $1 ORDER BY team_name`, userID)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
+	user.Teams = []string{}
+	for rows.Next() {
+		var team string
+		if err := rows.Scan(&team); err != nil {
+			return nil, err
+		}
+		user.Teams = append(user.Teams, team)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
 	return &user, nil
 }
 
-func (s *Service) GetAssignmentStats(ctx context.Context) ([]models.AssignmentStats, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT reviewer_id, COUNT(*) FROM pr_reviewers GROUP BY reviewer_id`)
+// GetAssignmentStats считает назначения по ревьюверам. С teamName в статистику
+// попадают все участники команды (включая дополнительных, с нулём назначений)
+// и только PR этой команды; с includeSubteams — участники и PR всего поддерева.
+func (s *Service) GetAssignmentStats(ctx context.Context, teamName *string, includeSubteams bool) ([]models.AssignmentStats, error) {
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	if teamName == nil {
+		rows, err = s.db.QueryContext(ctx, `SELECT reviewer_id, COUNT(*) FROM pr_reviewers GROUP BY reviewer_id`)
+	} else {
+		rows, err = s.db.QueryContext(ctx, subtreeCTE+`,
+			members AS (SELECT DISTINCT user_id FROM team_members WHERE team_name IN (SELECT team_name FROM subtree))
+			SELECT m.user_id, COUNT(pr.pull_request_id) FROM members m
+			LEFT JOIN pr_reviewers prr ON prr.reviewer_id = m.user_id
+			LEFT JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+				AND pr.team_name IN (SELECT team_name FROM subtree)
+			GROUP BY m.user_id ORDER BY m.user_id
+		`, *teamName, includeSubteams)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -312,19 +576,28 @@ func (s *Service) GetAssignmentStats(ctx context.Context) ([]models.AssignmentSt
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	if stats == nil {
+		stats = []models.AssignmentStats{}
+	}
 
 	return stats, nil
 }
 
 func (s *Service) getPullRequest(ctx context.Context, prID string) (*models.PullRequest, error) {
+	return loadPullRequest(ctx, s.db, prID)
+}
+
+// loadPullRequest читает PR через q — базу или транзакцию.
+func loadPullRequest(ctx context.Context, q querier, prID string) (*models.PullRequest, error) {
 	var pr models.PullRequest
 	var statusStr string
 
 	var createdAt time.Time
 	var mergedAt sql.NullTime
+	var teamName sql.NullString
 
-	err := s.db.QueryRowContext(ctx, `
-        SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at 
+	err := q.QueryRowContext(ctx, `
+        SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at, team_name, pending_reviewers
         FROM pull_requests WHERE pull_request_id = $1
     `, prID).Scan(
 		&pr.PullRequestId,
@@ -333,9 +606,13 @@ func (s *Service) getPullRequest(ctx context.Context, prID string) (*models.Pull
 		&statusStr,
 		&createdAt,
 		&mergedAt,
+		&teamName,
+		&pr.PendingReviewers,
 	)
 
-	if err != nil {
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
 		return nil, err
 	}
 
@@ -349,7 +626,11 @@ func (s *Service) getPullRequest(ctx context.Context, prID string) (*models.Pull
 		pr.MergedAt = nil
 	}
 
-	rows, err := s.db.QueryContext(ctx, `SELECT reviewer_id FROM pr_reviewers WHERE pull_request_id = $1`, prID)
+	if teamName.Valid {
+		pr.TeamName = &teamName.String
+	}
+
+	rows, err := q.QueryContext(ctx, `SELECT reviewer_id FROM pr_reviewers WHERE pull_request_id = $1`, prID)
 	if err != nil {
 		return nil, err
 	}
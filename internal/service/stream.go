@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"pull-request-api.com/internal/models"
+)
+
+// streamGapRetention — сколько курсор помнит пропущенный номер события. Номер
+// выдаётся при вставке, а событие становится видно после коммита, поэтому
+// событие с меньшим номером может появиться позже следующих. Курсор не ждёт
+// его: следующие события отдаются сразу, а пропущенные номера перечитываются
+// при каждом чтении, пока событие не появится или не истечёт срок (номер
+// остался от отменённой транзакции). Срок считается по монотонным часам
+// процесса с момента, когда курсор заметил пропуск.
+const streamGapRetention = 10 * time.Minute
+
+// maxStreamGaps ограничивает число запоминаемых пропусков; при переполнении
+// забываются самые старые номера.
+const maxStreamGaps = inChunkSize
+
+const streamBatchSize = 500
+
+// EventFilter отбирает события потока. Пустое поле не фильтрует.
+type EventFilter struct {
+	// TeamName — события PR команды и активность её участников.
+	TeamName string
+	// UserID — события PR, где пользователь автор или ревьювер, и его активность.
+	UserID string
+}
+
+// EventCursor читает журнал событий для потока по возрастанию номеров;
+// события, закоммиченные позже следующих за ними, отдаются, как только
+// становятся видны.
+type EventCursor struct {
+	s      *Service
+	filter EventFilter
+	last   int64
+	// gaps — пропущенные номера до last и время, когда пропуск замечен.
+	gaps map[int64]time.Time
+}
+
+// NewEventCursor проверяет фильтр и открывает курсор после события after;
+// без after поток начинается с новых событий.
+func (s *Service) NewEventCursor(ctx context.Context, filter EventFilter, after *int64) (*EventCursor, error) {
+	if filter.TeamName != "" {
+		var exists bool
+		err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)`, filter.TeamName).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrNotFound
+		}
+	}
+	if filter.UserID != "" {
+		var exists bool
+		err := s.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)`, filter.UserID).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrNotFound
+		}
+	}
+
+	c := &EventCursor{s: s, filter: filter, gaps: map[int64]time.Time{}}
+	if after != nil {
+		c.last = *after
+		return c, nil
+	}
+	if err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM events`).Scan(&c.last); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Last — наибольший номер прочитанного события.
+func (c *EventCursor) Last() int64 {
+	return c.last
+}
+
+// Next возвращает подходящие под фильтр события после курсора и события из
+// пропусков, которые стали видны, и сдвигает курсор. Курсор сдвигается и по
+// неподходящим событиям.
+func (c *EventCursor) Next(ctx context.Context) ([]models.Event, error) {
+	events := []models.Event{}
+	if err := c.fillGaps(ctx, &events); err != nil {
+		return nil, err
+	}
+	for {
+		n, err := c.next(ctx, &events)
+		if err != nil {
+			return nil, err
+		}
+		if n < streamBatchSize {
+			return events, nil
+		}
+	}
+}
+
+// streamQuery выбирает события с данными для фильтра; where задаёт отбор по
+// номеру и использует параметры начиная с $4.
+func streamQuery(where string) string {
+	return `
+		SELECT e.id, e.aggregate_type, e.aggregate_id, e.event_type, e.data, e.occurred_at,
+			pr.team_name, COALESCE(pr.author_id, ''),
+			EXISTS(SELECT 1 FROM team_members tm WHERE tm.user_id = e.aggregate_id AND tm.team_name = $1),
+			EXISTS(SELECT 1 FROM pr_reviewers prr WHERE prr.pull_request_id = e.aggregate_id AND prr.reviewer_id = $2)
+		FROM events e
+		LEFT JOIN pull_requests pr ON e.aggregate_type = $3 AND pr.pull_request_id = e.aggregate_id
+		WHERE ` + where
+}
+
+func (c *EventCursor) next(ctx context.Context, events *[]models.Event) (int, error) {
+	rows, err := c.s.db.QueryContext(ctx, streamQuery(`e.id > $4 ORDER BY e.id LIMIT $5`),
+		c.filter.TeamName, c.filter.UserID, aggregatePR, c.last, streamBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	return c.read(rows, events, func(id int64) {
+		// До первого события журнала пропусков нет: номера могут начинаться не с 1.
+		if c.last > 0 {
+			c.addGaps(c.last+1, id)
+		}
+		c.last = id
+	})
+}
+
+// fillGaps дочитывает события из запомненных пропусков и забывает пропуски
+// старше streamGapRetention.
+func (c *EventCursor) fillGaps(ctx context.Context, events *[]models.Event) error {
+	for id, seen := range c.gaps {
+		if time.Since(seen) >= streamGapRetention {
+			delete(c.gaps, id)
+		}
+	}
+	if len(c.gaps) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(c.gaps))
+	for id := range c.gaps {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	args := []any{c.filter.TeamName, c.filter.UserID, aggregatePR}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	rows, err := c.s.db.QueryContext(ctx, streamQuery(`e.id IN (`+inList(4, len(ids))+`) ORDER BY e.id`), args...)
+	if err != nil {
+		return err
+	}
+	_, err = c.read(rows, events, func(id int64) { delete(c.gaps, id) })
+	return err
+}
+
+// addGaps запоминает номера [from, to) как пропущенные.
+func (c *EventCursor) addGaps(from, to int64) {
+	now := time.Now()
+	for id := max(from, to-maxStreamGaps); id < to; id++ {
+		c.gaps[id] = now
+	}
+	if len(c.gaps) <= maxStreamGaps {
+		return
+	}
+	ids := make([]int64, 0, len(c.gaps))
+	for id := range c.gaps {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	for _, id := range ids[:len(ids)-maxStreamGaps] {
+		delete(c.gaps, id)
+	}
+}
+
+// read переводит строки streamQuery в события потока; seen вызывается для
+// каждой строки до фильтрации.
+func (c *EventCursor) read(rows *sql.Rows, events *[]models.Event, seen func(id int64)) (int, error) {
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		var (
+			id                 int64
+			ev                 event
+			data               string
+			prTeam             *string
+			author             string
+			teamMember, review bool
+		)
+		err := rows.Scan(&id, &ev.aggregateType, &ev.aggregateID, &ev.eventType, &data, &ev.occurredAt,
+			&prTeam, &author, &teamMember, &review)
+		if err != nil {
+			return 0, err
+		}
+		n++
+		seen(id)
+
+		ev.data = []byte(data)
+		out, ok, err := c.streamEvent(id, ev, prTeam, author, teamMember, review)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			*events = append(*events, out)
+		}
+	}
+	return n, rows.Err()
+}
+
+// streamEvent переводит событие журнала в событие потока; ok=false, если оно
+// не входит в поток или не проходит фильтр.
+func (c *EventCursor) streamEvent(id int64, ev event, prTeam *string, author string, teamMember, review bool) (models.Event, bool, error) {
+	out := models.Event{Id: id, Type: models.EventType(ev.eventType), OccurredAt: ev.occurredAt}
+	switch ev.aggregateType {
+	case aggregatePR:
+		out.PullRequestId = &ev.aggregateID
+		out.TeamName = prTeam
+		if ev.eventType == eventReviewerAssigned || ev.eventType == eventReviewerRemoved {
+			var d reviewerData
+			if err := json.Unmarshal(ev.data, &d); err != nil {
+				return out, false, err
+			}
+			out.UserId = &d.ReviewerID
+			out.Selection = d.Selection
+		}
+		if c.filter.TeamName != "" && (prTeam == nil || *prTeam != c.filter.TeamName) {
+			return out, false, nil
+		}
+		if c.filter.UserID != "" && author != c.filter.UserID && !review && (out.UserId == nil || *out.UserId != c.filter.UserID) {
+			return out, false, nil
+		}
+	case aggregateUser:
+		out.UserId = &ev.aggregateID
+		if ev.eventType == eventUserMoved {
+			var d userMovedData
+			if err := json.Unmarshal(ev.data, &d); err != nil {
+				return out, false, err
+			}
+			out.TeamName = &d.ToTeam
+		}
+		if c.filter.TeamName != "" && !teamMember {
+			return out, false, nil
+		}
+		if c.filter.UserID != "" && ev.aggregateID != c.filter.UserID {
+			return out, false, nil
+		}
+	default:
+		return out, false, nil
+	}
+	return out, true, nil
+}
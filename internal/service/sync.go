@@ -0,0 +1,490 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"pull-request-api.com/internal/database"
+	"pull-request-api.com/internal/models"
+)
+
+// orgState — текущая оргструктура в базе (без удалённых пользователей).
+type orgState struct {
+	teams   map[string]teamState
+	users   map[string]userState
+	members map[membership]bool
+	deleted map[string]bool
+}
+
+type teamState struct {
+	parent sql.NullString
+	policy string
+}
+
+type userState struct {
+	username string
+	active   bool
+	team     sql.NullString
+}
+
+type membership struct {
+	team string
+	user string
+}
+
+// desiredUser — пользователь из файла; team — основная команда (первая, где он указан).
+type desiredUser struct {
+	member models.TeamMember
+	team   string
+}
+
+// SyncOrg приводит оргструктуру к описанному в файле состоянию. В отличие от
+// ImportTeams, команды и членства, которых нет в файле, удаляются, а
+// отсутствующие в файле пользователи деактивируются (absent_users=deactivate)
+// или удаляются (delete; с историей — анонимизируются, как в DeleteUser).
+// Открытые ревью тех, кто покидает команду, переназначаются внутри неё.
+// С dryRun изменения выполняются в транзакции, которая затем откатывается:
+// план показывает и переназначения ревью, но замены в нём — пример, при
+// применении они выбираются заново. Применение атомарно.
+func (s *Service) SyncOrg(ctx context.Context, teams []models.Team, absentUsers models.PostOrgSyncParamsAbsentUsers, dryRun bool) (*models.OrgPlan, error) {
+	switch absentUsers {
+	case models.PostOrgSyncParamsAbsentUsersDeactivate, models.PostOrgSyncParamsAbsentUsersDelete:
+	default:
+		return nil, fmt.Errorf("%w: unknown absent_users %q", ErrInvalidInput, absentUsers)
+	}
+	if len(teams) == 0 {
+		// Пустой файл удалил бы всю оргструктуру — скорее всего это ошибка.
+		return nil, fmt.Errorf("%w: desired state has no teams", ErrInvalidInput)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if !dryRun && s.dialect == database.Postgres {
+		// План строится по снимку всей оргструктуры — параллельные изменения
+		// команд и пользователей до конца транзакции не допускаются. В SQLite
+		// пишущая транзакция и так блокирует всю базу.
+		if _, err := tx.ExecContext(ctx, `LOCK TABLE teams, users, team_members IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+			return nil, err
+		}
+	}
+
+	state, err := loadOrgState(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	inFile := map[string]bool{}
+	for _, t := range teams {
+		inFile[t.TeamName] = true
+	}
+	var userOrder []string
+	users := map[string]desiredUser{}
+	desired := map[membership]bool{}
+	for _, t := range teams {
+		if t.ParentTeam != nil && !inFile[*t.ParentTeam] {
+			return nil, fmt.Errorf("%w: parent_team %q of team %q is not in the file", ErrInvalidInput, *t.ParentTeam, t.TeamName)
+		}
+		for _, m := range t.Members {
+			if state.deleted[m.UserId] {
+				return nil, fmt.Errorf("%w: user %q is deleted", ErrInvalidInput, m.UserId)
+			}
+			if _, ok := users[m.UserId]; !ok {
+				users[m.UserId] = desiredUser{member: m, team: t.TeamName}
+				userOrder = append(userOrder, m.UserId)
+			}
+			desired[membership{t.TeamName, m.UserId}] = true
+		}
+	}
+
+	var absent []string
+	for uid := range state.users {
+		if _, ok := users[uid]; !ok {
+			absent = append(absent, uid)
+		}
+	}
+	sort.Strings(absent)
+
+	plan := &models.OrgPlan{Changes: planSync(teams, userOrder, users, desired, absent, state, absentUsers)}
+	if dryRun {
+		ctx = withDryRun(ctx)
+	}
+
+	if err := applySyncTeams(ctx, tx, teams, state); err != nil {
+		return nil, err
+	}
+
+	for _, uid := range userOrder {
+		u := users[uid]
+		_, err := tx.ExecContext(ctx, `INSERT INTO users (user_id, username, team_name, is_active) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id) DO UPDATE SET username = EXCLUDED.username, team_name = EXCLUDED.team_name,
+				is_active = EXCLUDED.is_active`, uid, u.member.Username, u.team, u.member.IsActive)
+		if err != nil {
+			return nil, err
+		}
+		wasActive := true
+		if prev, ok := state.users[uid]; ok {
+			wasActive = prev.active
+		}
+		if err := s.recordUserActivity(ctx, tx, uid, wasActive, u.member.IsActive); err != nil {
+			return nil, err
+		}
+	}
+
+	// Членства, которых нет в файле. Ревью в командах, которые сами удаляются,
+	// не переназначаем — заменять в них некем.
+	var released []membership
+	for _, m := range sortedMemberships(state.members) {
+		if desired[m] {
+			continue
+		}
+		if _, ok := users[m.user]; !ok {
+			continue
+		}
+		_, err := tx.ExecContext(ctx, `DELETE FROM team_members WHERE team_name = $1 AND user_id = $2`, m.team, m.user)
+		if err != nil {
+			return nil, err
+		}
+		if inFile[m.team] {
+			released = append(released, m)
+		}
+	}
+	for _, m := range sortedMemberships(desired) {
+		_, err := tx.ExecContext(ctx, `INSERT INTO team_members (team_name, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			m.team, m.user)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Отсутствующие в файле выводятся из всех команд до переназначения ревью,
+	// чтобы не стать заменой.
+	for _, uid := range absent {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM team_members WHERE user_id = $1`, uid); err != nil {
+			return nil, err
+		}
+		_, err := tx.ExecContext(ctx, `UPDATE users SET team_name = NULL, is_active = FALSE WHERE user_id = $1`, uid)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.recordUserActivity(ctx, tx, uid, state.users[uid].active, false); err != nil {
+			return nil, err
+		}
+	}
+
+	reviews := []models.ReviewReassignment{}
+	for _, m := range released {
+		r, err := s.releaseReviews(ctx, tx, m.user, m.team, m.team, models.ReviewPolicyReassign)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, r...)
+	}
+	for _, uid := range absent {
+		r, err := s.releaseReviews(ctx, tx, uid, "", "", models.ReviewPolicyReassign)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, r...)
+	}
+	plan.Reviews = &reviews
+	if dryRun {
+		return plan, nil
+	}
+
+	if absentUsers == models.PostOrgSyncParamsAbsentUsersDelete {
+		for _, uid := range absent {
+			if err := s.removeUser(ctx, tx, uid); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, name := range sortedTeams(state.teams) {
+		if inFile[name] {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM teams WHERE team_name = $1`, name); err != nil {
+			return nil, err
+		}
+		if err := s.emit(ctx, tx, aggregateTeam, name, eventTeamDeleted, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	names := make([]string, 0, len(teams))
+	for _, t := range teams {
+		names = append(names, t.TeamName)
+	}
+	err = audit(ctx, tx, "org.sync", "org", map[string]any{
+		"teams":        names,
+		"absent_users": absentUsers,
+		"changes":      len(plan.Changes),
+		"reviews":      reviews,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	plan.Applied = true
+	return plan, nil
+}
+
+func loadOrgState(ctx context.Context, tx *sql.Tx) (*orgState, error) {
+	state := &orgState{
+		teams:   map[string]teamState{},
+		users:   map[string]userState{},
+		members: map[membership]bool{},
+		deleted: map[string]bool{},
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT team_name, parent_team, escalation_policy FROM teams`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var (
+			name string
+			t    teamState
+		)
+		if err := rows.Scan(&name, &t.parent, &t.policy); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		state.teams[name] = t
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.QueryContext(ctx, `SELECT user_id, username, is_active, team_name, deleted_at IS NOT NULL FROM users`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var (
+			uid     string
+			u       userState
+			deleted bool
+		)
+		if err := rows.Scan(&uid, &u.username, &u.active, &u.team, &deleted); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if deleted {
+			state.deleted[uid] = true
+		} else {
+			state.users[uid] = u
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = tx.QueryContext(ctx, `SELECT team_name, user_id FROM team_members`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m membership
+		if err := rows.Scan(&m.team, &m.user); err != nil {
+			return nil, err
+		}
+		state.members[m] = true
+	}
+	return state, rows.Err()
+}
+
+func planSync(teams []models.Team, userOrder []string, users map[string]desiredUser, desired map[membership]bool,
+	absent []string, state *orgState, absentUsers models.PostOrgSyncParamsAbsentUsers) []models.OrgChange {
+	changes := []models.OrgChange{}
+
+	inFile := map[string]bool{}
+	for _, t := range teams {
+		inFile[t.TeamName] = true
+
+		cur, exists := state.teams[t.TeamName]
+		if !exists {
+			changes = append(changes, models.OrgChange{Action: models.OrgChangeActionCreateTeam, TeamName: t.TeamName})
+		}
+		// Файл описывает состояние целиком: без parent_team команда корневая,
+		// без escalation_policy — none.
+		if t.ParentTeam != nil && (!cur.parent.Valid || cur.parent.String != *t.ParentTeam) {
+			changes = append(changes, fieldChange(models.OrgChangeActionUpdateTeam, t.TeamName, nil, "parent_team",
+				nullable(cur.parent), *t.ParentTeam))
+		} else if t.ParentTeam == nil && cur.parent.Valid {
+			field := "parent_team"
+			changes = append(changes, models.OrgChange{Action: models.OrgChangeActionUpdateTeam, TeamName: t.TeamName,
+				Field: &field, Old: nullable(cur.parent)})
+		}
+		policy := desiredPolicy(t)
+		if !exists && policy != models.EscalationPolicyNone {
+			changes = append(changes, fieldChange(models.OrgChangeActionUpdateTeam, t.TeamName, nil, "escalation_policy",
+				nil, string(policy)))
+		} else if exists && cur.policy != string(policy) {
+			old := cur.policy
+			changes = append(changes, fieldChange(models.OrgChangeActionUpdateTeam, t.TeamName, nil, "escalation_policy",
+				&old, string(policy)))
+		}
+	}
+
+	for _, uid := range userOrder {
+		u := users[uid]
+		cur, exists := state.users[uid]
+		if !exists {
+			changes = append(changes, models.OrgChange{Action: models.OrgChangeActionCreateUser, TeamName: u.team, UserId: &uid})
+			continue
+		}
+		if !cur.team.Valid || cur.team.String != u.team {
+			changes = append(changes, fieldChange(models.OrgChangeActionMoveUser, u.team, &uid, "team_name",
+				nullable(cur.team), u.team))
+		}
+		if cur.username != u.member.Username {
+			old := cur.username
+			changes = append(changes, fieldChange(models.OrgChangeActionUpdateUser, u.team, &uid, "username", &old, u.member.Username))
+		}
+		if cur.active != u.member.IsActive {
+			old := strconv.FormatBool(cur.active)
+			changes = append(changes, fieldChange(models.OrgChangeActionUpdateUser, u.team, &uid, "is_active",
+				&old, strconv.FormatBool(u.member.IsActive)))
+		}
+	}
+
+	for _, m := range sortedMemberships(desired) {
+		uid := m.user
+		if state.members[m] {
+			continue
+		}
+		if _, exists := state.users[uid]; !exists && users[uid].team == m.team {
+			// Членство в основной команде входит в create_user.
+			continue
+		}
+		changes = append(changes, models.OrgChange{Action: models.OrgChangeActionAddMember, TeamName: m.team, UserId: &uid})
+	}
+	for _, m := range sortedMemberships(state.members) {
+		uid := m.user
+		if _, ok := users[uid]; !ok || desired[m] {
+			continue
+		}
+		changes = append(changes, models.OrgChange{Action: models.OrgChangeActionRemoveMember, TeamName: m.team, UserId: &uid})
+	}
+
+	for _, uid := range absent {
+		cur := state.users[uid]
+		action := models.OrgChangeActionDeleteUser
+		if absentUsers == models.PostOrgSyncParamsAbsentUsersDeactivate {
+			if !cur.active && !cur.team.Valid && !hasMembership(state.members, uid) {
+				continue
+			}
+			action = models.OrgChangeActionDeactivateUser
+		}
+		changes = append(changes, models.OrgChange{Action: action, TeamName: cur.team.String, UserId: &uid})
+	}
+
+	for _, name := range sortedTeams(state.teams) {
+		if !inFile[name] {
+			changes = append(changes, models.OrgChange{Action: models.OrgChangeActionDeleteTeam, TeamName: name})
+		}
+	}
+	return changes
+}
+
+func applySyncTeams(ctx context.Context, tx *sql.Tx, teams []models.Team, state *orgState) error {
+	for _, t := range teams {
+		if _, ok := state.teams[t.TeamName]; ok {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO teams (team_name) VALUES ($1)`, t.TeamName); err != nil {
+			return err
+		}
+	}
+	// Сначала отвязываем все команды файла от родителей: иначе промежуточное
+	// состояние при перестановке ветвей может оказаться циклом. Итоговая
+	// иерархия проверена на циклы при разборе файла.
+	for _, t := range teams {
+		if _, err := tx.ExecContext(ctx, `UPDATE teams SET parent_team = NULL WHERE team_name = $1`, t.TeamName); err != nil {
+			return err
+		}
+	}
+	for _, t := range teams {
+		_, err := tx.ExecContext(ctx, `UPDATE teams SET parent_team = $1, escalation_policy = $2 WHERE team_name = $3`,
+			t.ParentTeam, desiredPolicy(t), t.TeamName)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeUser удаляет пользователя без истории и анонимизирует остальных.
+// Членства и открытые ревью к этому моменту уже сняты.
+func (s *Service) removeUser(ctx context.Context, tx *sql.Tx, userID string) error {
+	var hasHistory bool
+	err := tx.QueryRowContext(ctx, `SELECT
+		EXISTS(SELECT 1 FROM pull_requests WHERE author_id = $1) OR
+		EXISTS(SELECT 1 FROM pr_reviewers WHERE reviewer_id = $1)`, userID).Scan(&hasHistory)
+	if err != nil {
+		return err
+	}
+	if !hasHistory {
+		_, err = tx.ExecContext(ctx, `DELETE FROM users WHERE user_id = $1`, userID)
+	} else {
+		_, err = tx.ExecContext(ctx, `UPDATE users SET username = $1, team_name = NULL, is_active = FALSE,
+			deleted_at = CURRENT_TIMESTAMP WHERE user_id = $2`, deletedUsername, userID)
+	}
+	if err != nil {
+		return err
+	}
+	return s.emit(ctx, tx, aggregateUser, userID, eventUserDeleted, nil)
+}
+
+func desiredPolicy(t models.Team) models.EscalationPolicy {
+	if t.EscalationPolicy == nil {
+		return models.EscalationPolicyNone
+	}
+	return *t.EscalationPolicy
+}
+
+func hasMembership(members map[membership]bool, userID string) bool {
+	for m := range members {
+		if m.user == userID {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedMemberships(set map[membership]bool) []membership {
+	list := make([]membership, 0, len(set))
+	for m := range set {
+		list = append(list, m)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].team != list[j].team {
+			return list[i].team < list[j].team
+		}
+		return list[i].user < list[j].user
+	})
+	return list
+}
+
+func sortedTeams(teams map[string]teamState) []string {
+	names := make([]string, 0, len(teams))
+	for name := range teams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,392 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"pull-request-api.com/internal/models"
+)
+
+// RenameTeam переименовывает команду; users.team_name обновляется каскадно.
+func (s *Service) RenameTeam(ctx context.Context, req models.PostTeamRenameJSONRequestBody) (*models.Team, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockTeam(ctx, tx, req.TeamName); err != nil {
+		return nil, err
+	}
+	if req.NewTeamName == req.TeamName {
+		return nil, fmt.Errorf("%w: new_team_name equals team_name", ErrInvalidInput)
+	}
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)`, req.NewTeamName).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrConflict
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE teams SET team_name = $1 WHERE team_name = $2`, req.NewTeamName, req.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	err = s.emit(ctx, tx, aggregateTeam, req.TeamName, eventTeamRenamed, teamRenamedData{NewTeamName: req.NewTeamName})
+	if err != nil {
+		return nil, err
+	}
+
+	err = audit(ctx, tx, "team.rename", "team:"+req.NewTeamName, map[string]string{
+		"from": req.TeamName,
+		"to":   req.NewTeamName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.GetTeam(ctx, req.NewTeamName)
+}
+
+// DeleteTeam удаляет команду. По умолчанию (member_policy=reject) команда с
+// участниками не удаляется; при member_policy=move участники переносятся в
+// target_team. Открытые ревью участников на PR удаляемой команды остаются за
+// ними (review_policy=keep), передаются участнику target_team (reassign) или
+// снимаются (unassign). Подкоманды переходят к родителю удаляемой команды.
+func (s *Service) DeleteTeam(ctx context.Context, req models.PostTeamDeleteJSONRequestBody) (*models.TeamDeleteResult, error) {
+	memberPolicy := models.TeamMemberPolicyReject
+	if req.MemberPolicy != nil {
+		memberPolicy = *req.MemberPolicy
+	}
+	reviewPolicy := models.ReviewPolicyKeep
+	if req.ReviewPolicy != nil {
+		reviewPolicy = *req.ReviewPolicy
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockTeam(ctx, tx, req.TeamName); err != nil {
+		return nil, err
+	}
+
+	members, err := teamMemberIDs(ctx, tx, req.TeamName)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.TeamDeleteResult{
+		TeamName:     req.TeamName,
+		MovedMembers: []string{},
+		Reviews:      []models.ReviewReassignment{},
+	}
+
+	if len(members) > 0 {
+		if memberPolicy != models.TeamMemberPolicyMove {
+			return nil, ErrPrecondition
+		}
+		if req.TargetTeam == nil || *req.TargetTeam == req.TeamName {
+			return nil, fmt.Errorf("%w: target_team is required and must differ from team_name", ErrInvalidInput)
+		}
+		if err := lockTeam(ctx, tx, *req.TargetTeam); err != nil {
+			return nil, err
+		}
+
+		for _, uid := range members {
+			// Затрагиваются только PR удаляемой команды: ревью участников на PR
+			// их дополнительных команд остаются за ними.
+			reviews, err := s.releaseReviews(ctx, tx, uid, *req.TargetTeam, req.TeamName, reviewPolicy)
+			if err != nil {
+				return nil, err
+			}
+			result.Reviews = append(result.Reviews, reviews...)
+		}
+
+		_, err = tx.ExecContext(ctx, `INSERT INTO team_members (team_name, user_id)
+			SELECT $1, user_id FROM users WHERE team_name = $2 ON CONFLICT DO NOTHING`, *req.TargetTeam, req.TeamName)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE users SET team_name = $1 WHERE team_name = $2`, *req.TargetTeam, req.TeamName)
+		if err != nil {
+			return nil, err
+		}
+		result.MovedMembers = members
+	}
+
+	// Подкоманды не остаются без департамента: они переходят к родителю удаляемой команды.
+	_, err = tx.ExecContext(ctx, `UPDATE teams SET parent_team = (SELECT parent_team FROM teams WHERE team_name = $1)
+		WHERE parent_team = $1`, req.TeamName)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM teams WHERE team_name = $1`, req.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.emit(ctx, tx, aggregateTeam, req.TeamName, eventTeamDeleted, nil); err != nil {
+		return nil, err
+	}
+
+	err = audit(ctx, tx, "team.delete", "team:"+req.TeamName, map[string]any{
+		"member_policy": memberPolicy,
+		"review_policy": reviewPolicy,
+		"target_team":   req.TargetTeam,
+		"moved_members": result.MovedMembers,
+		"reviews":       result.Reviews,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// MoveUser переводит пользователя в другую команду. Его открытые ревью на PR
+// прежней команды остаются за ним (keep), передаются другому её участнику
+// (reassign) или снимаются (unassign).
+func (s *Service) MoveUser(ctx context.Context, req models.PostUsersMoveTeamJSONRequestBody) (*models.UserMoveResult, error) {
+	reviewPolicy := models.ReviewPolicyKeep
+	if req.ReviewPolicy != nil {
+		reviewPolicy = *req.ReviewPolicy
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var oldTeam string
+	err = tx.QueryRowContext(ctx, `SELECT COALESCE(team_name, '') FROM users WHERE user_id = $1 AND deleted_at IS NULL FOR UPDATE`, req.UserId).Scan(&oldTeam)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if err := lockTeam(ctx, tx, req.TeamName); err != nil {
+		return nil, err
+	}
+
+	reviews := []models.ReviewReassignment{}
+	if oldTeam != req.TeamName {
+		// Пользователь покидает только прежнюю основную команду: ревью на PR
+		// его дополнительных команд остаются за ним.
+		if oldTeam != "" {
+			reviews, err = s.releaseReviews(ctx, tx, req.UserId, oldTeam, oldTeam, reviewPolicy)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE users SET team_name = $1 WHERE user_id = $2`, req.TeamName, req.UserId)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM team_members WHERE team_name = $1 AND user_id = $2`, oldTeam, req.UserId)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO team_members (team_name, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			req.TeamName, req.UserId)
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.emit(ctx, tx, aggregateUser, req.UserId, eventUserMoved, userMovedData{FromTeam: oldTeam, ToTeam: req.TeamName})
+		if err != nil {
+			return nil, err
+		}
+
+		err = audit(ctx, tx, "user.move", "user:"+req.UserId, map[string]any{
+			"from":          oldTeam,
+			"to":            req.TeamName,
+			"review_policy": reviewPolicy,
+			"reviews":       reviews,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	user, err := s.getUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UserMoveResult{User: *user, Reviews: reviews}, nil
+}
+
+// RemoveTeamMember исключает пользователя из дополнительной команды. Основную
+// команду так убрать нельзя — для этого есть MoveUser. Ревью пользователя на PR
+// этой команды обрабатываются по review_policy.
+func (s *Service) RemoveTeamMember(ctx context.Context, req models.PostTeamRemoveMemberJSONRequestBody) (*models.User, error) {
+	reviewPolicy := models.ReviewPolicyKeep
+	if req.ReviewPolicy != nil {
+		reviewPolicy = *req.ReviewPolicy
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var primary string
+	err = tx.QueryRowContext(ctx, `SELECT COALESCE(team_name, '') FROM users WHERE user_id = $1 AND deleted_at IS NULL FOR UPDATE`, req.UserId).Scan(&primary)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if primary == req.TeamName {
+		return nil, fmt.Errorf("%w: cannot remove user from primary team, use /users/moveTeam", ErrInvalidInput)
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM team_members WHERE team_name = $1 AND user_id = $2`, req.TeamName, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrNotFound
+	}
+
+	reviews, err := s.releaseReviews(ctx, tx, req.UserId, req.TeamName, req.TeamName, reviewPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	err = audit(ctx, tx, "team.remove_member", "team:"+req.TeamName, map[string]any{
+		"user_id":       req.UserId,
+		"review_policy": reviewPolicy,
+		"reviews":       reviews,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.getUser(ctx, req.UserId)
+}
+
+// lockTeam блокирует строку команды до конца транзакции, чтобы параллельные
+// переименование/удаление не пересеклись.
+func lockTeam(ctx context.Context, tx *sql.Tx, teamName string) error {
+	var name string
+	err := tx.QueryRowContext(ctx, `SELECT team_name FROM teams WHERE team_name = $1 FOR UPDATE`, teamName).Scan(&name)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	return err
+}
+
+func teamMemberIDs(ctx context.Context, tx *sql.Tx, teamName string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT user_id FROM users WHERE team_name = $1 ORDER BY user_id FOR UPDATE`, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// releaseReviews применяет review_policy к открытым ревью пользователя (только
+// к PR команды prTeam, если она задана). При reassign замена ищется в team
+// (пустая team — в команде каждого PR); если кандидата нет, ревьювер
+// снимается без замены, а слот PR становится ожидающим (см. FillPendingReviewers).
+func (s *Service) releaseReviews(ctx context.Context, tx *sql.Tx, userID, team, prTeam string, policy models.ReviewPolicy) ([]models.ReviewReassignment, error) {
+	reviews := []models.ReviewReassignment{}
+	switch policy {
+	case models.ReviewPolicyKeep:
+		return reviews, nil
+	case models.ReviewPolicyReassign, models.ReviewPolicyUnassign:
+	default:
+		return nil, fmt.Errorf("%w: unknown review_policy %q", ErrInvalidInput, policy)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT pr.pull_request_id, COALESCE(pr.team_name, '') FROM pull_requests pr
+		JOIN pr_reviewers prr ON pr.pull_request_id = prr.pull_request_id
+		WHERE prr.reviewer_id = $1 AND pr.status = $2 AND ($3 = '' OR pr.team_name = $3)
+		ORDER BY pr.pull_request_id
+	`, userID, models.PullRequestStatusOPEN, prTeam)
+	if err != nil {
+		return nil, err
+	}
+	var prIDs, prTeams []string
+	for rows.Next() {
+		var id, t string
+		if err := rows.Scan(&id, &t); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		prIDs = append(prIDs, id)
+		prTeams = append(prTeams, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, prID := range prIDs {
+		r := models.ReviewReassignment{PullRequestId: prID, OldUserId: userID}
+
+		var sel *models.ReviewerSelection
+		if policy == models.ReviewPolicyReassign {
+			replTeam := team
+			if replTeam == "" {
+				replTeam = prTeams[i]
+			}
+			var newRev string
+			newRev, sel, _, err = s.pickReplacement(ctx, tx, prID, replTeam, userID)
+			if err != nil {
+				return nil, err
+			}
+			if newRev != "" {
+				r.NewUserId = &newRev
+			}
+		}
+
+		err = s.emit(ctx, tx, aggregatePR, prID, eventReviewerRemoved, reviewerData{ReviewerID: userID})
+		if err != nil {
+			return nil, err
+		}
+		if r.NewUserId != nil {
+			err = s.emit(ctx, tx, aggregatePR, prID, eventReviewerAssigned, reviewerData{ReviewerID: *r.NewUserId, Selection: sel})
+		} else if policy == models.ReviewPolicyReassign {
+			err = s.addPendingReviewers(ctx, tx, prID, 1)
+		}
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, r)
+	}
+	return reviews, nil
+}
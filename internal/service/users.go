@@ -0,0 +1,355 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"pull-request-api.com/internal/models"
+)
+
+const (
+	defaultUsersPageSize = 50
+	maxUsersPageSize     = 200
+
+	// deletedUsername подставляется вместо имени обезличенного пользователя.
+	deletedUsername = "deleted user"
+)
+
+// GetUser возвращает пользователя, в том числе обезличенного (с deleted_at).
+func (s *Service) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	return s.getUser(ctx, userID)
+}
+
+// ListUsers возвращает страницу пользователей, упорядоченных по user_id.
+// Обезличенные пользователи в список не попадают.
+func (s *Service) ListUsers(ctx context.Context, params models.GetUsersListParams) (*models.UserList, error) {
+	limit := defaultUsersPageSize
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	if limit < 1 || limit > maxUsersPageSize {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidInput, maxUsersPageSize)
+	}
+
+	var (
+		team, prefix sql.NullString
+		active       sql.NullBool
+		cursor       string
+	)
+	if params.TeamName != nil {
+		team = sql.NullString{String: *params.TeamName, Valid: true}
+	}
+	if params.NamePrefix != nil {
+		prefix = sql.NullString{String: likePrefix(strings.ToLower(*params.NamePrefix)), Valid: true}
+	}
+	if params.IsActive != nil {
+		active = sql.NullBool{Bool: *params.IsActive, Valid: true}
+	}
+	if params.Cursor != nil {
+		cursor = *params.Cursor
+	}
+
+	// Берём на одну запись больше, чтобы понять, есть ли следующая страница.
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT u.user_id, u.username, u.team_name, u.is_active, u.max_open_reviews, tm.team_name
+		FROM (
+			SELECT user_id, username, team_name, is_active, max_open_reviews FROM users
+			WHERE deleted_at IS NULL
+				AND (CAST($1 AS TEXT) IS NULL OR user_id IN (SELECT user_id FROM team_members WHERE team_name = $1))
+				AND (CAST($2 AS BOOLEAN) IS NULL OR is_active = $2)
+				AND (CAST($3 AS TEXT) IS NULL OR lower(username) LIKE $3 ESCAPE '\')
+				AND user_id > $4
+			ORDER BY user_id LIMIT $5
+		) u
+		LEFT JOIN team_members tm ON tm.user_id = u.user_id
+		ORDER BY u.user_id, tm.team_name
+	`, team, active, prefix, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	users, err := scanUserRows(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &models.UserList{Users: users}
+	if len(users) > limit {
+		list.Users = users[:limit]
+		next := users[limit-1].UserId
+		list.NextCursor = &next
+	}
+	return list, nil
+}
+
+// ListUsersRange возвращает до limit пользователей, начиная с позиции offset
+// в порядке user_id, и общее число пользователей. Нужна SCIM, где страницы
+// задаются смещением (startIndex), а не курсором. Обезличенные пользователи не
+// учитываются.
+func (s *Service) ListUsersRange(ctx context.Context, offset, limit int) ([]models.User, int, error) {
+	if offset < 0 || limit < 0 || limit > maxUsersPageSize {
+		return nil, 0, fmt.Errorf("%w: limit must be between 0 and %d", ErrInvalidInput, maxUsersPageSize)
+	}
+
+	var total int
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE deleted_at IS NULL`).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+	if limit == 0 || offset >= total {
+		return []models.User{}, total, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT u.user_id, u.username, u.team_name, u.is_active, u.max_open_reviews, tm.team_name
+		FROM (
+			SELECT user_id, username, team_name, is_active, max_open_reviews FROM users
+			WHERE deleted_at IS NULL
+			ORDER BY user_id LIMIT $1 OFFSET $2
+		) u
+		LEFT JOIN team_members tm ON tm.user_id = u.user_id
+		ORDER BY u.user_id, tm.team_name
+	`, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	users, err := scanUserRows(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// scanUserRows собирает пользователей из строк (пользователь, команда
+// участия), упорядоченных по user_id, и закрывает rows.
+func scanUserRows(rows *sql.Rows) ([]models.User, error) {
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var (
+			u          models.User
+			primary    sql.NullString
+			limit      sql.NullInt64
+			memberTeam sql.NullString
+		)
+		if err := rows.Scan(&u.UserId, &u.Username, &primary, &u.IsActive, &limit, &memberTeam); err != nil {
+			return nil, err
+		}
+		if n := len(users); n == 0 || users[n-1].UserId != u.UserId {
+			u.TeamName = primary.String
+			u.MaxOpenReviews = nullableInt(limit)
+			u.Teams = []string{}
+			users = append(users, u)
+		}
+		if memberTeam.Valid {
+			last := &users[len(users)-1]
+			last.Teams = append(last.Teams, memberTeam.String)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// likePrefix экранирует спецсимволы LIKE и добавляет '%'.
+func likePrefix(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s) + "%"
+}
+
+// UpdateUsername меняет имя пользователя.
+func (s *Service) UpdateUsername(ctx context.Context, req models.PatchUsersUpdateJSONRequestBody) (*models.User, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := updateUsername(ctx, tx, req); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.getUser(ctx, req.UserId)
+}
+
+// PatchUser меняет имя и флаг активности пользователя одной транзакцией:
+// либо применяются оба изменения, либо ни одного. nil — поле не меняется.
+func (s *Service) PatchUser(ctx context.Context, userID string, username *string, isActive *bool) (*models.User, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if username != nil {
+		err := updateUsername(ctx, tx, models.PatchUsersUpdateJSONRequestBody{UserId: userID, Username: *username})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if isActive != nil {
+		err := s.setUserActive(ctx, tx, models.PostUsersSetIsActiveJSONRequestBody{UserId: userID, IsActive: *isActive})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.getUser(ctx, userID)
+}
+
+// updateUsername меняет имя пользователя в рамках транзакции tx.
+func updateUsername(ctx context.Context, tx *sql.Tx, req models.PatchUsersUpdateJSONRequestBody) error {
+	var old string
+	err := tx.QueryRowContext(ctx, `SELECT username FROM users WHERE user_id = $1 AND deleted_at IS NULL FOR UPDATE`, req.UserId).
+		Scan(&old)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+	if old == req.Username {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE users SET username = $1 WHERE user_id = $2`, req.Username, req.UserId)
+	if err != nil {
+		return err
+	}
+	return audit(ctx, tx, "user.rename", "user:"+req.UserId, map[string]string{
+		"from": old,
+		"to":   req.Username,
+	})
+}
+
+// DeleteUser удаляет пользователя. Пользователь без PR и ревью удаляется
+// полностью. Если история есть, по умолчанию (mode=block) удаление отклоняется
+// с ErrPrecondition; при mode=anonymize пользователь обезличивается: имя
+// заменяется, он деактивируется и исключается из всех команд, а его открытые
+// ревью передаются другим участникам (reassign, по умолчанию) или снимаются.
+// PR и история назначений при этом сохраняются.
+func (s *Service) DeleteUser(ctx context.Context, req models.PostUsersDeleteJSONRequestBody) (*models.UserDeleteResult, error) {
+	mode := models.UserDeleteModeBlock
+	if req.Mode != nil {
+		mode = *req.Mode
+	}
+	reviewPolicy := models.ReviewPolicyReassign
+	if req.ReviewPolicy != nil {
+		reviewPolicy = *req.ReviewPolicy
+	}
+	if reviewPolicy == models.ReviewPolicyKeep {
+		return nil, fmt.Errorf("%w: review_policy=keep is not supported for user deletion", ErrInvalidInput)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var primary string
+	err = tx.QueryRowContext(ctx, `SELECT COALESCE(team_name, '') FROM users WHERE user_id = $1 AND deleted_at IS NULL FOR UPDATE`, req.UserId).
+		Scan(&primary)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	var hasHistory bool
+	err = tx.QueryRowContext(ctx, `SELECT
+		EXISTS(SELECT 1 FROM pull_requests WHERE author_id = $1) OR
+		EXISTS(SELECT 1 FROM pr_reviewers WHERE reviewer_id = $1)`, req.UserId).Scan(&hasHistory)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.UserDeleteResult{
+		UserId:  req.UserId,
+		Result:  models.UserDeleteResultResultDeleted,
+		Reviews: []models.ReviewReassignment{},
+	}
+
+	switch {
+	case !hasHistory:
+		if _, err := tx.ExecContext(ctx, `DELETE FROM team_members WHERE user_id = $1`, req.UserId); err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE user_id = $1`, req.UserId); err != nil {
+			return nil, err
+		}
+	case mode == models.UserDeleteModeAnonymize:
+		result.Result = models.UserDeleteResultResultAnonymized
+
+		teams, err := userTeams(ctx, tx, req.UserId)
+		if err != nil {
+			return nil, err
+		}
+		// Замену на PR команды ищем в самой команде, на прочих PR — в основной.
+		for _, t := range teams {
+			reviews, err := s.releaseReviews(ctx, tx, req.UserId, t, t, reviewPolicy)
+			if err != nil {
+				return nil, err
+			}
+			result.Reviews = append(result.Reviews, reviews...)
+		}
+		reviews, err := s.releaseReviews(ctx, tx, req.UserId, primary, "", reviewPolicy)
+		if err != nil {
+			return nil, err
+		}
+		result.Reviews = append(result.Reviews, reviews...)
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM team_members WHERE user_id = $1`, req.UserId); err != nil {
+			return nil, err
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE users SET username = $1, team_name = NULL, is_active = FALSE,
+			deleted_at = CURRENT_TIMESTAMP WHERE user_id = $2`, deletedUsername, req.UserId)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrPrecondition
+	}
+	if err := s.emit(ctx, tx, aggregateUser, req.UserId, eventUserDeleted, nil); err != nil {
+		return nil, err
+	}
+
+	err = audit(ctx, tx, "user.delete", "user:"+req.UserId, map[string]any{
+		"result":        result.Result,
+		"team_name":     primary,
+		"review_policy": reviewPolicy,
+		"reviews":       result.Reviews,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func userTeams(ctx context.Context, tx *sql.Tx, userID string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT team_name FROM team_members WHERE user_id = $1 ORDER BY team_name`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var teams []string
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		teams = append(teams, t)
+	}
+	return teams, rows.Err()
+}
@@ -0,0 +1,18 @@
+-- Переименование команды каскадно обновляет users.team_name, а удаление команды
+-- больше не удаляет её участников (и вместе с ними их PR): участников сначала
+-- нужно переместить или открепить.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_team_name_fkey;
+ALTER TABLE users ADD CONSTRAINT users_team_name_fkey
+    FOREIGN KEY (team_name) REFERENCES teams(team_name) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+-- Журнал административных изменений.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor TEXT,
+    action TEXT NOT NULL,
+    entity TEXT NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_log_entity ON audit_log(entity, created_at);
@@ -0,0 +1,19 @@
+-- Пользователь может состоять в нескольких командах. users.team_name остаётся
+-- основной командой и всегда присутствует в team_members.
+CREATE TABLE IF NOT EXISTS team_members (
+    team_name TEXT NOT NULL REFERENCES teams(team_name) ON UPDATE CASCADE ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    PRIMARY KEY (team_name, user_id)
+);
+
+CREATE INDEX idx_team_members_user ON team_members(user_id);
+
+INSERT INTO team_members (team_name, user_id)
+SELECT team_name, user_id FROM users WHERE team_name IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- Команда, из которой назначаются ревьюверы PR.
+ALTER TABLE pull_requests ADD COLUMN team_name TEXT REFERENCES teams(team_name) ON UPDATE CASCADE ON DELETE SET NULL;
+
+UPDATE pull_requests pr SET team_name = u.team_name
+FROM users u WHERE u.user_id = pr.author_id;
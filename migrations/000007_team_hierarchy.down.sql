@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_pull_requests_team_status;
+DROP INDEX IF EXISTS idx_teams_parent;
+ALTER TABLE teams DROP COLUMN IF EXISTS escalation_policy;
+ALTER TABLE teams DROP COLUMN IF EXISTS parent_team;
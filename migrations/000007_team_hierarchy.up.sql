@@ -0,0 +1,9 @@
+-- Иерархия команд (департамент -> команды) и политика эскалации выбора ревьюверов.
+ALTER TABLE teams ADD COLUMN parent_team TEXT REFERENCES teams(team_name) ON UPDATE CASCADE ON DELETE SET NULL;
+ALTER TABLE teams ADD COLUMN escalation_policy TEXT NOT NULL DEFAULT 'none';
+
+CREATE INDEX idx_teams_parent ON teams(parent_team);
+
+-- Списки и статистика по поддереву команд:
+-- SELECT ... FROM pull_requests WHERE team_name IN (...) AND status = $1
+CREATE INDEX idx_pull_requests_team_status ON pull_requests(team_name, status);
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_users_username_prefix;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
+
+ALTER TABLE pr_reviewers DROP CONSTRAINT IF EXISTS pr_reviewers_reviewer_id_fkey;
+ALTER TABLE pr_reviewers ADD CONSTRAINT pr_reviewers_reviewer_id_fkey
+    FOREIGN KEY (reviewer_id) REFERENCES users(user_id) ON DELETE CASCADE;
+
+ALTER TABLE pull_requests DROP CONSTRAINT IF EXISTS pull_requests_author_id_fkey;
+ALTER TABLE pull_requests ADD CONSTRAINT pull_requests_author_id_fkey
+    FOREIGN KEY (author_id) REFERENCES users(user_id) ON DELETE CASCADE;
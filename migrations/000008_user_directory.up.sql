@@ -0,0 +1,14 @@
+-- Удаление пользователя больше не удаляет каскадно его PR и назначения:
+-- пользователь с историей либо не удаляется, либо обезличивается (deleted_at).
+ALTER TABLE pull_requests DROP CONSTRAINT IF EXISTS pull_requests_author_id_fkey;
+ALTER TABLE pull_requests ADD CONSTRAINT pull_requests_author_id_fkey
+    FOREIGN KEY (author_id) REFERENCES users(user_id) ON DELETE RESTRICT;
+
+ALTER TABLE pr_reviewers DROP CONSTRAINT IF EXISTS pr_reviewers_reviewer_id_fkey;
+ALTER TABLE pr_reviewers ADD CONSTRAINT pr_reviewers_reviewer_id_fkey
+    FOREIGN KEY (reviewer_id) REFERENCES users(user_id) ON DELETE RESTRICT;
+
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- Поиск по префиксу имени: WHERE lower(username) LIKE 'ali%'
+CREATE INDEX idx_users_username_prefix ON users (lower(username) text_pattern_ops);
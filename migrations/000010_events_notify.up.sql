@@ -0,0 +1,11 @@
+-- Реплики узнают о новых событиях (GET /events/stream) по NOTIFY: уведомления
+-- одной транзакции сливаются в одно и доставляются после коммита.
+CREATE OR REPLACE FUNCTION events_notify() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('events', '');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER events_notify AFTER INSERT ON events
+    FOR EACH STATEMENT EXECUTE FUNCTION events_notify();
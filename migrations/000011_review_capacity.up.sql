@@ -0,0 +1,6 @@
+-- Лимит одновременно открытых ревью: у пользователя или по умолчанию у его
+-- основной команды. capacity_policy команды PR решает, что делать, когда все
+-- кандидаты достигли лимита.
+ALTER TABLE users ADD COLUMN max_open_reviews INTEGER CHECK (max_open_reviews >= 0);
+ALTER TABLE teams ADD COLUMN default_max_open_reviews INTEGER CHECK (default_max_open_reviews >= 0);
+ALTER TABLE teams ADD COLUMN capacity_policy TEXT NOT NULL DEFAULT 'least_loaded';
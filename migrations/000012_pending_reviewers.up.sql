@@ -0,0 +1,5 @@
+-- Незаполненные слоты ревьюверов открытого PR (проекция события
+-- ReviewersPending). Фоновый процесс заполняет их, когда появляются кандидаты.
+ALTER TABLE pull_requests ADD COLUMN pending_reviewers INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_pull_requests_pending ON pull_requests(created_at) WHERE pending_reviewers > 0 AND status = 'OPEN';
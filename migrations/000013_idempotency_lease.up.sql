@@ -0,0 +1,4 @@
+-- Срок, до которого незавершённый запрос удерживает ключ. Если обработчик
+-- упал или процесс завершился, после истечения срока ключ перехватывает повтор.
+-- NULL у старых незавершённых записей считается истёкшим сроком.
+ALTER TABLE idempotency_keys ADD COLUMN locked_until TIMESTAMPTZ;
@@ -0,0 +1,74 @@
+-- Схема для SQLite: соответствует миграциям PostgreSQL 000001–000008.
+-- Новые изменения схемы добавляются в оба набора.
+CREATE TABLE IF NOT EXISTS teams (
+    team_name TEXT PRIMARY KEY,
+    parent_team TEXT REFERENCES teams(team_name) ON UPDATE CASCADE ON DELETE SET NULL,
+    escalation_policy TEXT NOT NULL DEFAULT 'none'
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    user_id TEXT PRIMARY KEY,
+    username TEXT NOT NULL,
+    team_name TEXT REFERENCES teams(team_name) ON UPDATE CASCADE ON DELETE RESTRICT,
+    is_active BOOLEAN DEFAULT TRUE,
+    deleted_at TIMESTAMP
+);
+
+-- created_at с миллисекундами: по нему упорядочиваются списки PR.
+CREATE TABLE IF NOT EXISTS pull_requests (
+    pull_request_id TEXT PRIMARY KEY,
+    pull_request_name TEXT NOT NULL,
+    author_id TEXT REFERENCES users(user_id) ON DELETE RESTRICT,
+    status TEXT NOT NULL DEFAULT 'OPEN',
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    merged_at TIMESTAMP,
+    team_name TEXT REFERENCES teams(team_name) ON UPDATE CASCADE ON DELETE SET NULL
+);
+
+CREATE TABLE IF NOT EXISTS pr_reviewers (
+    pull_request_id TEXT REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    reviewer_id TEXT REFERENCES users(user_id) ON DELETE RESTRICT,
+    PRIMARY KEY (pull_request_id, reviewer_id)
+);
+
+CREATE TABLE IF NOT EXISTS team_members (
+    team_name TEXT NOT NULL REFERENCES teams(team_name) ON UPDATE CASCADE ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    PRIMARY KEY (team_name, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    bucket_key TEXT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    scope TEXT NOT NULL,
+    idempotency_key TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    status_code INT,
+    content_type TEXT,
+    response_body BLOB,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (scope, idempotency_key)
+);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor TEXT,
+    action TEXT NOT NULL,
+    entity TEXT NOT NULL,
+    details TEXT NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_users_team_active ON users(team_name, is_active);
+CREATE INDEX idx_pull_requests_author ON pull_requests(author_id);
+CREATE INDEX idx_pr_reviewers_reviewer ON pr_reviewers(reviewer_id);
+CREATE INDEX idx_idempotency_keys_expires ON idempotency_keys(expires_at);
+CREATE INDEX idx_audit_log_entity ON audit_log(entity, created_at);
+CREATE INDEX idx_team_members_user ON team_members(user_id);
+CREATE INDEX idx_teams_parent ON teams(parent_team);
+CREATE INDEX idx_pull_requests_team_status ON pull_requests(team_name, status);
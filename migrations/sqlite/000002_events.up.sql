@@ -0,0 +1,32 @@
+-- Журнал доменных событий. pull_requests и pr_reviewers — его проекции:
+-- их можно пересобрать по журналу (`pr-api rebuild`), а состояние PR —
+-- восстановить на любой момент (GET /pullRequest/getAsOf).
+CREATE TABLE IF NOT EXISTS events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    aggregate_type TEXT NOT NULL,
+    aggregate_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    data TEXT NOT NULL DEFAULT '{}',
+    actor TEXT,
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_events_aggregate ON events(aggregate_type, aggregate_id, id);
+
+-- Существующие PR переносятся в журнал. Когда были назначены текущие
+-- ревьюверы, неизвестно — считаем, что при создании PR.
+INSERT INTO events (aggregate_type, aggregate_id, event_type, data, occurred_at)
+SELECT 'pull_request', pull_request_id, 'PRCreated',
+    json_object('pull_request_name', pull_request_name, 'author_id', author_id, 'team_name', team_name),
+    created_at
+FROM pull_requests ORDER BY created_at, pull_request_id;
+
+INSERT INTO events (aggregate_type, aggregate_id, event_type, data, occurred_at)
+SELECT 'pull_request', pr.pull_request_id, 'ReviewerAssigned',
+    json_object('reviewer_id', prr.reviewer_id), pr.created_at
+FROM pr_reviewers prr JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+ORDER BY pr.created_at, pr.pull_request_id, prr.reviewer_id;
+
+INSERT INTO events (aggregate_type, aggregate_id, event_type, occurred_at)
+SELECT 'pull_request', pull_request_id, 'PRMerged', COALESCE(merged_at, created_at)
+FROM pull_requests WHERE status = 'MERGED' ORDER BY COALESCE(merged_at, created_at), pull_request_id;
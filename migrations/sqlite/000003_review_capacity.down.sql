@@ -0,0 +1,3 @@
+ALTER TABLE teams DROP COLUMN capacity_policy;
+ALTER TABLE teams DROP COLUMN default_max_open_reviews;
+ALTER TABLE users DROP COLUMN max_open_reviews;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_pull_requests_pending;
+ALTER TABLE pull_requests DROP COLUMN pending_reviewers;
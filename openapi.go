@@ -0,0 +1,8 @@
+// Package openapi встраивает спецификацию API в бинарник, чтобы сервер мог
+// валидировать запросы по ней.
+package openapi
+
+import _ "embed"
+
+//go:embed openapi.yml
+var Spec []byte
@@ -0,0 +1,1447 @@
+// gRPC API сервиса назначения ревьюверов. Повторяет REST API (openapi.yml):
+// команды, пользователи, PR, списки ревью и статистика. Коды ошибок:
+// NOT_FOUND — нет ресурса, ALREADY_EXISTS — ресурс уже есть,
+// INVALID_ARGUMENT — некорректный запрос, FAILED_PRECONDITION — операция
+// невозможна в текущем состоянии (PR смёржен, ревьювер не назначен, нет кандидата).
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v6.31.1
+// source: reviewer/v1/reviewer.proto
+
+package reviewerv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type PullRequestStatus int32
+
+const (
+	PullRequestStatus_PULL_REQUEST_STATUS_UNSPECIFIED PullRequestStatus = 0
+	PullRequestStatus_PULL_REQUEST_STATUS_OPEN        PullRequestStatus = 1
+	PullRequestStatus_PULL_REQUEST_STATUS_MERGED      PullRequestStatus = 2
+)
+
+// Enum value maps for PullRequestStatus.
+var (
+	PullRequestStatus_name = map[int32]string{
+		0: "PULL_REQUEST_STATUS_UNSPECIFIED",
+		1: "PULL_REQUEST_STATUS_OPEN",
+		2: "PULL_REQUEST_STATUS_MERGED",
+	}
+	PullRequestStatus_value = map[string]int32{
+		"PULL_REQUEST_STATUS_UNSPECIFIED": 0,
+		"PULL_REQUEST_STATUS_OPEN":        1,
+		"PULL_REQUEST_STATUS_MERGED":      2,
+	}
+)
+
+func (x PullRequestStatus) Enum() *PullRequestStatus {
+	p := new(PullRequestStatus)
+	*p = x
+	return p
+}
+
+func (x PullRequestStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PullRequestStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_reviewer_v1_reviewer_proto_enumTypes[0].Descriptor()
+}
+
+func (PullRequestStatus) Type() protoreflect.EnumType {
+	return &file_reviewer_v1_reviewer_proto_enumTypes[0]
+}
+
+func (x PullRequestStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PullRequestStatus.Descriptor instead.
+func (PullRequestStatus) EnumDescriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{0}
+}
+
+type TeamMember struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Username      string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	IsActive      bool                   `protobuf:"varint,3,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TeamMember) Reset() {
+	*x = TeamMember{}
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TeamMember) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TeamMember) ProtoMessage() {}
+
+func (x *TeamMember) ProtoReflect() protoreflect.Message {
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TeamMember.ProtoReflect.Descriptor instead.
+func (*TeamMember) Descriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TeamMember) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *TeamMember) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *TeamMember) GetIsActive() bool {
+	if x != nil {
+		return x.IsActive
+	}
+	return false
+}
+
+type Team struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	TeamName string                 `protobuf:"bytes,1,opt,name=team_name,json=teamName,proto3" json:"team_name,omitempty"`
+	Members  []*TeamMember          `protobuf:"bytes,2,rep,name=members,proto3" json:"members,omitempty"`
+	// Родительская команда (департамент); пусто — корневая.
+	ParentTeam    string `protobuf:"bytes,3,opt,name=parent_team,json=parentTeam,proto3" json:"parent_team,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Team) Reset() {
+	*x = Team{}
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Team) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Team) ProtoMessage() {}
+
+func (x *Team) ProtoReflect() protoreflect.Message {
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Team.ProtoReflect.Descriptor instead.
+func (*Team) Descriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Team) GetTeamName() string {
+	if x != nil {
+		return x.TeamName
+	}
+	return ""
+}
+
+func (x *Team) GetMembers() []*TeamMember {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+func (x *Team) GetParentTeam() string {
+	if x != nil {
+		return x.ParentTeam
+	}
+	return ""
+}
+
+type User struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	UserId   string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Username string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	// Основная команда.
+	TeamName string `protobuf:"bytes,3,opt,name=team_name,json=teamName,proto3" json:"team_name,omitempty"`
+	IsActive bool   `protobuf:"varint,4,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	// Все команды пользователя, включая основную.
+	Teams         []string `protobuf:"bytes,5,rep,name=teams,proto3" json:"teams,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *User) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *User) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *User) GetTeamName() string {
+	if x != nil {
+		return x.TeamName
+	}
+	return ""
+}
+
+func (x *User) GetIsActive() bool {
+	if x != nil {
+		return x.IsActive
+	}
+	return false
+}
+
+func (x *User) GetTeams() []string {
+	if x != nil {
+		return x.Teams
+	}
+	return nil
+}
+
+type PullRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	PullRequestId     string                 `protobuf:"bytes,1,opt,name=pull_request_id,json=pullRequestId,proto3" json:"pull_request_id,omitempty"`
+	PullRequestName   string                 `protobuf:"bytes,2,opt,name=pull_request_name,json=pullRequestName,proto3" json:"pull_request_name,omitempty"`
+	AuthorId          string                 `protobuf:"bytes,3,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Status            PullRequestStatus      `protobuf:"varint,4,opt,name=status,proto3,enum=reviewer.v1.PullRequestStatus" json:"status,omitempty"`
+	AssignedReviewers []string               `protobuf:"bytes,5,rep,name=assigned_reviewers,json=assignedReviewers,proto3" json:"assigned_reviewers,omitempty"`
+	// Команда, из которой назначаются ревьюверы.
+	TeamName      string                 `protobuf:"bytes,6,opt,name=team_name,json=teamName,proto3" json:"team_name,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	MergedAt      *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=merged_at,json=mergedAt,proto3" json:"merged_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PullRequest) Reset() {
+	*x = PullRequest{}
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PullRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PullRequest) ProtoMessage() {}
+
+func (x *PullRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PullRequest.ProtoReflect.Descriptor instead.
+func (*PullRequest) Descriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PullRequest) GetPullRequestId() string {
+	if x != nil {
+		return x.PullRequestId
+	}
+	return ""
+}
+
+func (x *PullRequest) GetPullRequestName() string {
+	if x != nil {
+		return x.PullRequestName
+	}
+	return ""
+}
+
+func (x *PullRequest) GetAuthorId() string {
+	if x != nil {
+		return x.AuthorId
+	}
+	return ""
+}
+
+func (x *PullRequest) GetStatus() PullRequestStatus {
+	if x != nil {
+		return x.Status
+	}
+	return PullRequestStatus_PULL_REQUEST_STATUS_UNSPECIFIED
+}
+
+func (x *PullRequest) GetAssignedReviewers() []string {
+	if x != nil {
+		return x.AssignedReviewers
+	}
+	return nil
+}
+
+func (x *PullRequest) GetTeamName() string {
+	if x != nil {
+		return x.TeamName
+	}
+	return ""
+}
+
+func (x *PullRequest) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *PullRequest) GetMergedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.MergedAt
+	}
+	return nil
+}
+
+type PullRequestShort struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PullRequestId   string                 `protobuf:"bytes,1,opt,name=pull_request_id,json=pullRequestId,proto3" json:"pull_request_id,omitempty"`
+	PullRequestName string                 `protobuf:"bytes,2,opt,name=pull_request_name,json=pullRequestName,proto3" json:"pull_request_name,omitempty"`
+	AuthorId        string                 `protobuf:"bytes,3,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Status          PullRequestStatus      `protobuf:"varint,4,opt,name=status,proto3,enum=reviewer.v1.PullRequestStatus" json:"status,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PullRequestShort) Reset() {
+	*x = PullRequestShort{}
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PullRequestShort) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PullRequestShort) ProtoMessage() {}
+
+func (x *PullRequestShort) ProtoReflect() protoreflect.Message {
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PullRequestShort.ProtoReflect.Descriptor instead.
+func (*PullRequestShort) Descriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PullRequestShort) GetPullRequestId() string {
+	if x != nil {
+		return x.PullRequestId
+	}
+	return ""
+}
+
+func (x *PullRequestShort) GetPullRequestName() string {
+	if x != nil {
+		return x.PullRequestName
+	}
+	return ""
+}
+
+func (x *PullRequestShort) GetAuthorId() string {
+	if x != nil {
+		return x.AuthorId
+	}
+	return ""
+}
+
+func (x *PullRequestShort) GetStatus() PullRequestStatus {
+	if x != nil {
+		return x.Status
+	}
+	return PullRequestStatus_PULL_REQUEST_STATUS_UNSPECIFIED
+}
+
+type AssignmentStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Count         int64                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AssignmentStats) Reset() {
+	*x = AssignmentStats{}
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AssignmentStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AssignmentStats) ProtoMessage() {}
+
+func (x *AssignmentStats) ProtoReflect() protoreflect.Message {
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AssignmentStats.ProtoReflect.Descriptor instead.
+func (*AssignmentStats) Descriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AssignmentStats) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AssignmentStats) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type AddTeamRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Team          *Team                  `protobuf:"bytes,1,opt,name=team,proto3" json:"team,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddTeamRequest) Reset() {
+	*x = AddTeamRequest{}
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddTeamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddTeamRequest) ProtoMessage() {}
+
+func (x *AddTeamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddTeamRequest.ProtoReflect.Descriptor instead.
+func (*AddTeamRequest) Descriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AddTeamRequest) GetTeam() *Team {
+	if x != nil {
+		return x.Team
+	}
+	return nil
+}
+
+type GetTeamRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TeamName      string                 `protobuf:"bytes,1,opt,name=team_name,json=teamName,proto3" json:"team_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTeamRequest) Reset() {
+	*x = GetTeamRequest{}
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTeamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTeamRequest) ProtoMessage() {}
+
+func (x *GetTeamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTeamRequest.ProtoReflect.Descriptor instead.
+func (*GetTeamRequest) Descriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetTeamRequest) GetTeamName() string {
+	if x != nil {
+		return x.TeamName
+	}
+	return ""
+}
+
+type RenameTeamRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TeamName      string                 `protobuf:"bytes,1,opt,name=team_name,json=teamName,proto3" json:"team_name,omitempty"`
+	NewTeamName   string                 `protobuf:"bytes,2,opt,name=new_team_name,json=newTeamName,proto3" json:"new_team_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenameTeamRequest) Reset() {
+	*x = RenameTeamRequest{}
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameTeamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameTeamRequest) ProtoMessage() {}
+
+func (x *RenameTeamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameTeamRequest.ProtoReflect.Descriptor instead.
+func (*RenameTeamRequest) Descriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RenameTeamRequest) GetTeamName() string {
+	if x != nil {
+		return x.TeamName
+	}
+	return ""
+}
+
+func (x *RenameTeamRequest) GetNewTeamName() string {
+	if x != nil {
+		return x.NewTeamName
+	}
+	return ""
+}
+
+type GetUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserRequest) Reset() {
+	*x = GetUserRequest{}
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserRequest) ProtoMessage() {}
+
+func (x *GetUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserRequest.ProtoReflect.Descriptor instead.
+func (*GetUserRequest) Descriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetUserRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type SetUserActiveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	IsActive      bool                   `protobuf:"varint,2,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetUserActiveRequest) Reset() {
+	*x = SetUserActiveRequest{}
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetUserActiveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetUserActiveRequest) ProtoMessage() {}
+
+func (x *SetUserActiveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetUserActiveRequest.ProtoReflect.Descriptor instead.
+func (*SetUserActiveRequest) Descriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SetUserActiveRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *SetUserActiveRequest) GetIsActive() bool {
+	if x != nil {
+		return x.IsActive
+	}
+	return false
+}
+
+type CreatePullRequestRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PullRequestId   string                 `protobuf:"bytes,1,opt,name=pull_request_id,json=pullRequestId,proto3" json:"pull_request_id,omitempty"`
+	PullRequestName string                 `protobuf:"bytes,2,opt,name=pull_request_name,json=pullRequestName,proto3" json:"pull_request_name,omitempty"`
+	AuthorId        string                 `protobuf:"bytes,3,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	// Команда PR; пусто — основная команда автора.
+	TeamName      string `protobuf:"bytes,4,opt,name=team_name,json=teamName,proto3" json:"team_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreatePullRequestRequest) Reset() {
+	*x = CreatePullRequestRequest{}
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatePullRequestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePullRequestRequest) ProtoMessage() {}
+
+func (x *CreatePullRequestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePullRequestRequest.ProtoReflect.Descriptor instead.
+func (*CreatePullRequestRequest) Descriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CreatePullRequestRequest) GetPullRequestId() string {
+	if x != nil {
+		return x.PullRequestId
+	}
+	return ""
+}
+
+func (x *CreatePullRequestRequest) GetPullRequestName() string {
+	if x != nil {
+		return x.PullRequestName
+	}
+	return ""
+}
+
+func (x *CreatePullRequestRequest) GetAuthorId() string {
+	if x != nil {
+		return x.AuthorId
+	}
+	return ""
+}
+
+func (x *CreatePullRequestRequest) GetTeamName() string {
+	if x != nil {
+		return x.TeamName
+	}
+	return ""
+}
+
+type GetPullRequestRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PullRequestId string                 `protobuf:"bytes,1,opt,name=pull_request_id,json=pullRequestId,proto3" json:"pull_request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPullRequestRequest) Reset() {
+	*x = GetPullRequestRequest{}
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPullRequestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPullRequestRequest) ProtoMessage() {}
+
+func (x *GetPullRequestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPullRequestRequest.ProtoReflect.Descriptor instead.
+func (*GetPullRequestRequest) Descriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetPullRequestRequest) GetPullRequestId() string {
+	if x != nil {
+		return x.PullRequestId
+	}
+	return ""
+}
+
+type MergePullRequestRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PullRequestId string                 `protobuf:"bytes,1,opt,name=pull_request_id,json=pullRequestId,proto3" json:"pull_request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MergePullRequestRequest) Reset() {
+	*x = MergePullRequestRequest{}
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MergePullRequestRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MergePullRequestRequest) ProtoMessage() {}
+
+func (x *MergePullRequestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MergePullRequestRequest.ProtoReflect.Descriptor instead.
+func (*MergePullRequestRequest) Descriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *MergePullRequestRequest) GetPullRequestId() string {
+	if x != nil {
+		return x.PullRequestId
+	}
+	return ""
+}
+
+type ReassignReviewerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PullRequestId string                 `protobuf:"bytes,1,opt,name=pull_request_id,json=pullRequestId,proto3" json:"pull_request_id,omitempty"`
+	OldUserId     string                 `protobuf:"bytes,2,opt,name=old_user_id,json=oldUserId,proto3" json:"old_user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReassignReviewerRequest) Reset() {
+	*x = ReassignReviewerRequest{}
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReassignReviewerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReassignReviewerRequest) ProtoMessage() {}
+
+func (x *ReassignReviewerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReassignReviewerRequest.ProtoReflect.Descriptor instead.
+func (*ReassignReviewerRequest) Descriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ReassignReviewerRequest) GetPullRequestId() string {
+	if x != nil {
+		return x.PullRequestId
+	}
+	return ""
+}
+
+func (x *ReassignReviewerRequest) GetOldUserId() string {
+	if x != nil {
+		return x.OldUserId
+	}
+	return ""
+}
+
+type GetUserReviewsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserReviewsRequest) Reset() {
+	*x = GetUserReviewsRequest{}
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserReviewsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserReviewsRequest) ProtoMessage() {}
+
+func (x *GetUserReviewsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserReviewsRequest.ProtoReflect.Descriptor instead.
+func (*GetUserReviewsRequest) Descriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetUserReviewsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type GetUserReviewsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	PullRequests  []*PullRequestShort    `protobuf:"bytes,2,rep,name=pull_requests,json=pullRequests,proto3" json:"pull_requests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserReviewsResponse) Reset() {
+	*x = GetUserReviewsResponse{}
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserReviewsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserReviewsResponse) ProtoMessage() {}
+
+func (x *GetUserReviewsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserReviewsResponse.ProtoReflect.Descriptor instead.
+func (*GetUserReviewsResponse) Descriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetUserReviewsResponse) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetUserReviewsResponse) GetPullRequests() []*PullRequestShort {
+	if x != nil {
+		return x.PullRequests
+	}
+	return nil
+}
+
+type StreamUserReviewsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Продолжать поток изменениями очереди после текущих PR.
+	Watch         bool `protobuf:"varint,2,opt,name=watch,proto3" json:"watch,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamUserReviewsRequest) Reset() {
+	*x = StreamUserReviewsRequest{}
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamUserReviewsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamUserReviewsRequest) ProtoMessage() {}
+
+func (x *StreamUserReviewsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamUserReviewsRequest.ProtoReflect.Descriptor instead.
+func (*StreamUserReviewsRequest) Descriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *StreamUserReviewsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *StreamUserReviewsRequest) GetWatch() bool {
+	if x != nil {
+		return x.Watch
+	}
+	return false
+}
+
+// Изменение очереди ревью. Сообщения применяются по pull_request_id:
+// PR добавляется или обновляется, а с removed — убирается из очереди.
+type ReviewQueueEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PullRequest   *PullRequestShort      `protobuf:"bytes,1,opt,name=pull_request,json=pullRequest,proto3" json:"pull_request,omitempty"`
+	Removed       bool                   `protobuf:"varint,2,opt,name=removed,proto3" json:"removed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReviewQueueEntry) Reset() {
+	*x = ReviewQueueEntry{}
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReviewQueueEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReviewQueueEntry) ProtoMessage() {}
+
+func (x *ReviewQueueEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReviewQueueEntry.ProtoReflect.Descriptor instead.
+func (*ReviewQueueEntry) Descriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ReviewQueueEntry) GetPullRequest() *PullRequestShort {
+	if x != nil {
+		return x.PullRequest
+	}
+	return nil
+}
+
+func (x *ReviewQueueEntry) GetRemoved() bool {
+	if x != nil {
+		return x.Removed
+	}
+	return false
+}
+
+type GetAssignmentStatsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Считать только PR этой команды и только её участников.
+	TeamName string `protobuf:"bytes,1,opt,name=team_name,json=teamName,proto3" json:"team_name,omitempty"`
+	// Учитывать также все подкоманды team_name.
+	IncludeSubteams bool `protobuf:"varint,2,opt,name=include_subteams,json=includeSubteams,proto3" json:"include_subteams,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GetAssignmentStatsRequest) Reset() {
+	*x = GetAssignmentStatsRequest{}
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAssignmentStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAssignmentStatsRequest) ProtoMessage() {}
+
+func (x *GetAssignmentStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAssignmentStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetAssignmentStatsRequest) Descriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetAssignmentStatsRequest) GetTeamName() string {
+	if x != nil {
+		return x.TeamName
+	}
+	return ""
+}
+
+func (x *GetAssignmentStatsRequest) GetIncludeSubteams() bool {
+	if x != nil {
+		return x.IncludeSubteams
+	}
+	return false
+}
+
+type GetAssignmentStatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stats         []*AssignmentStats     `protobuf:"bytes,1,rep,name=stats,proto3" json:"stats,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAssignmentStatsResponse) Reset() {
+	*x = GetAssignmentStatsResponse{}
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAssignmentStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAssignmentStatsResponse) ProtoMessage() {}
+
+func (x *GetAssignmentStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_reviewer_v1_reviewer_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAssignmentStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetAssignmentStatsResponse) Descriptor() ([]byte, []int) {
+	return file_reviewer_v1_reviewer_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetAssignmentStatsResponse) GetStats() []*AssignmentStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+var File_reviewer_v1_reviewer_proto protoreflect.FileDescriptor
+
+const file_reviewer_v1_reviewer_proto_rawDesc = "" +
+	"\n" +
+	"\x1areviewer/v1/reviewer.proto\x12\vreviewer.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"^\n" +
+	"\n" +
+	"TeamMember\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x1b\n" +
+	"\tis_active\x18\x03 \x01(\bR\bisActive\"w\n" +
+	"\x04Team\x12\x1b\n" +
+	"\tteam_name\x18\x01 \x01(\tR\bteamName\x121\n" +
+	"\amembers\x18\x02 \x03(\v2\x17.reviewer.v1.TeamMemberR\amembers\x12\x1f\n" +
+	"\vparent_team\x18\x03 \x01(\tR\n" +
+	"parentTeam\"\x8b\x01\n" +
+	"\x04User\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x1b\n" +
+	"\tteam_name\x18\x03 \x01(\tR\bteamName\x12\x1b\n" +
+	"\tis_active\x18\x04 \x01(\bR\bisActive\x12\x14\n" +
+	"\x05teams\x18\x05 \x03(\tR\x05teams\"\xf6\x02\n" +
+	"\vPullRequest\x12&\n" +
+	"\x0fpull_request_id\x18\x01 \x01(\tR\rpullRequestId\x12*\n" +
+	"\x11pull_request_name\x18\x02 \x01(\tR\x0fpullRequestName\x12\x1b\n" +
+	"\tauthor_id\x18\x03 \x01(\tR\bauthorId\x126\n" +
+	"\x06status\x18\x04 \x01(\x0e2\x1e.reviewer.v1.PullRequestStatusR\x06status\x12-\n" +
+	"\x12assigned_reviewers\x18\x05 \x03(\tR\x11assignedReviewers\x12\x1b\n" +
+	"\tteam_name\x18\x06 \x01(\tR\bteamName\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x127\n" +
+	"\tmerged_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\bmergedAt\"\xbb\x01\n" +
+	"\x10PullRequestShort\x12&\n" +
+	"\x0fpull_request_id\x18\x01 \x01(\tR\rpullRequestId\x12*\n" +
+	"\x11pull_request_name\x18\x02 \x01(\tR\x0fpullRequestName\x12\x1b\n" +
+	"\tauthor_id\x18\x03 \x01(\tR\bauthorId\x126\n" +
+	"\x06status\x18\x04 \x01(\x0e2\x1e.reviewer.v1.PullRequestStatusR\x06status\"@\n" +
+	"\x0fAssignmentStats\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\"7\n" +
+	"\x0eAddTeamRequest\x12%\n" +
+	"\x04team\x18\x01 \x01(\v2\x11.reviewer.v1.TeamR\x04team\"-\n" +
+	"\x0eGetTeamRequest\x12\x1b\n" +
+	"\tteam_name\x18\x01 \x01(\tR\bteamName\"T\n" +
+	"\x11RenameTeamRequest\x12\x1b\n" +
+	"\tteam_name\x18\x01 \x01(\tR\bteamName\x12\"\n" +
+	"\rnew_team_name\x18\x02 \x01(\tR\vnewTeamName\")\n" +
+	"\x0eGetUserRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"L\n" +
+	"\x14SetUserActiveRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tis_active\x18\x02 \x01(\bR\bisActive\"\xa8\x01\n" +
+	"\x18CreatePullRequestRequest\x12&\n" +
+	"\x0fpull_request_id\x18\x01 \x01(\tR\rpullRequestId\x12*\n" +
+	"\x11pull_request_name\x18\x02 \x01(\tR\x0fpullRequestName\x12\x1b\n" +
+	"\tauthor_id\x18\x03 \x01(\tR\bauthorId\x12\x1b\n" +
+	"\tteam_name\x18\x04 \x01(\tR\bteamName\"?\n" +
+	"\x15GetPullRequestRequest\x12&\n" +
+	"\x0fpull_request_id\x18\x01 \x01(\tR\rpullRequestId\"A\n" +
+	"\x17MergePullRequestRequest\x12&\n" +
+	"\x0fpull_request_id\x18\x01 \x01(\tR\rpullRequestId\"a\n" +
+	"\x17ReassignReviewerRequest\x12&\n" +
+	"\x0fpull_request_id\x18\x01 \x01(\tR\rpullRequestId\x12\x1e\n" +
+	"\vold_user_id\x18\x02 \x01(\tR\toldUserId\"0\n" +
+	"\x15GetUserReviewsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"u\n" +
+	"\x16GetUserReviewsResponse\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12B\n" +
+	"\rpull_requests\x18\x02 \x03(\v2\x1d.reviewer.v1.PullRequestShortR\fpullRequests\"I\n" +
+	"\x18StreamUserReviewsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05watch\x18\x02 \x01(\bR\x05watch\"n\n" +
+	"\x10ReviewQueueEntry\x12@\n" +
+	"\fpull_request\x18\x01 \x01(\v2\x1d.reviewer.v1.PullRequestShortR\vpullRequest\x12\x18\n" +
+	"\aremoved\x18\x02 \x01(\bR\aremoved\"c\n" +
+	"\x19GetAssignmentStatsRequest\x12\x1b\n" +
+	"\tteam_name\x18\x01 \x01(\tR\bteamName\x12)\n" +
+	"\x10include_subteams\x18\x02 \x01(\bR\x0fincludeSubteams\"P\n" +
+	"\x1aGetAssignmentStatsResponse\x122\n" +
+	"\x05stats\x18\x01 \x03(\v2\x1c.reviewer.v1.AssignmentStatsR\x05stats*v\n" +
+	"\x11PullRequestStatus\x12#\n" +
+	"\x1fPULL_REQUEST_STATUS_UNSPECIFIED\x10\x00\x12\x1c\n" +
+	"\x18PULL_REQUEST_STATUS_OPEN\x10\x01\x12\x1e\n" +
+	"\x1aPULL_REQUEST_STATUS_MERGED\x10\x022\xb7\a\n" +
+	"\x0fReviewerService\x129\n" +
+	"\aAddTeam\x12\x1b.reviewer.v1.AddTeamRequest\x1a\x11.reviewer.v1.Team\x129\n" +
+	"\aGetTeam\x12\x1b.reviewer.v1.GetTeamRequest\x1a\x11.reviewer.v1.Team\x12?\n" +
+	"\n" +
+	"RenameTeam\x12\x1e.reviewer.v1.RenameTeamRequest\x1a\x11.reviewer.v1.Team\x129\n" +
+	"\aGetUser\x12\x1b.reviewer.v1.GetUserRequest\x1a\x11.reviewer.v1.User\x12E\n" +
+	"\rSetUserActive\x12!.reviewer.v1.SetUserActiveRequest\x1a\x11.reviewer.v1.User\x12T\n" +
+	"\x11CreatePullRequest\x12%.reviewer.v1.CreatePullRequestRequest\x1a\x18.reviewer.v1.PullRequest\x12N\n" +
+	"\x0eGetPullRequest\x12\".reviewer.v1.GetPullRequestRequest\x1a\x18.reviewer.v1.PullRequest\x12R\n" +
+	"\x10MergePullRequest\x12$.reviewer.v1.MergePullRequestRequest\x1a\x18.reviewer.v1.PullRequest\x12R\n" +
+	"\x10ReassignReviewer\x12$.reviewer.v1.ReassignReviewerRequest\x1a\x18.reviewer.v1.PullRequest\x12Y\n" +
+	"\x0eGetUserReviews\x12\".reviewer.v1.GetUserReviewsRequest\x1a#.reviewer.v1.GetUserReviewsResponse\x12[\n" +
+	"\x11StreamUserReviews\x12%.reviewer.v1.StreamUserReviewsRequest\x1a\x1d.reviewer.v1.ReviewQueueEntry0\x01\x12e\n" +
+	"\x12GetAssignmentStats\x12&.reviewer.v1.GetAssignmentStatsRequest\x1a'.reviewer.v1.GetAssignmentStatsResponseB3Z1pull-request-api.com/proto/reviewer/v1;reviewerv1b\x06proto3"
+
+var (
+	file_reviewer_v1_reviewer_proto_rawDescOnce sync.Once
+	file_reviewer_v1_reviewer_proto_rawDescData []byte
+)
+
+func file_reviewer_v1_reviewer_proto_rawDescGZIP() []byte {
+	file_reviewer_v1_reviewer_proto_rawDescOnce.Do(func() {
+		file_reviewer_v1_reviewer_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_reviewer_v1_reviewer_proto_rawDesc), len(file_reviewer_v1_reviewer_proto_rawDesc)))
+	})
+	return file_reviewer_v1_reviewer_proto_rawDescData
+}
+
+var file_reviewer_v1_reviewer_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_reviewer_v1_reviewer_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_reviewer_v1_reviewer_proto_goTypes = []any{
+	(PullRequestStatus)(0),             // 0: reviewer.v1.PullRequestStatus
+	(*TeamMember)(nil),                 // 1: reviewer.v1.TeamMember
+	(*Team)(nil),                       // 2: reviewer.v1.Team
+	(*User)(nil),                       // 3: reviewer.v1.User
+	(*PullRequest)(nil),                // 4: reviewer.v1.PullRequest
+	(*PullRequestShort)(nil),           // 5: reviewer.v1.PullRequestShort
+	(*AssignmentStats)(nil),            // 6: reviewer.v1.AssignmentStats
+	(*AddTeamRequest)(nil),             // 7: reviewer.v1.AddTeamRequest
+	(*GetTeamRequest)(nil),             // 8: reviewer.v1.GetTeamRequest
+	(*RenameTeamRequest)(nil),          // 9: reviewer.v1.RenameTeamRequest
+	(*GetUserRequest)(nil),             // 10: reviewer.v1.GetUserRequest
+	(*SetUserActiveRequest)(nil),       // 11: reviewer.v1.SetUserActiveRequest
+	(*CreatePullRequestRequest)(nil),   // 12: reviewer.v1.CreatePullRequestRequest
+	(*GetPullRequestRequest)(nil),      // 13: reviewer.v1.GetPullRequestRequest
+	(*MergePullRequestRequest)(nil),    // 14: reviewer.v1.MergePullRequestRequest
+	(*ReassignReviewerRequest)(nil),    // 15: reviewer.v1.ReassignReviewerRequest
+	(*GetUserReviewsRequest)(nil),      // 16: reviewer.v1.GetUserReviewsRequest
+	(*GetUserReviewsResponse)(nil),     // 17: reviewer.v1.GetUserReviewsResponse
+	(*StreamUserReviewsRequest)(nil),   // 18: reviewer.v1.StreamUserReviewsRequest
+	(*ReviewQueueEntry)(nil),           // 19: reviewer.v1.ReviewQueueEntry
+	(*GetAssignmentStatsRequest)(nil),  // 20: reviewer.v1.GetAssignmentStatsRequest
+	(*GetAssignmentStatsResponse)(nil), // 21: reviewer.v1.GetAssignmentStatsResponse
+	(*timestamppb.Timestamp)(nil),      // 22: google.protobuf.Timestamp
+}
+var file_reviewer_v1_reviewer_proto_depIdxs = []int32{
+	1,  // 0: reviewer.v1.Team.members:type_name -> reviewer.v1.TeamMember
+	0,  // 1: reviewer.v1.PullRequest.status:type_name -> reviewer.v1.PullRequestStatus
+	22, // 2: reviewer.v1.PullRequest.created_at:type_name -> google.protobuf.Timestamp
+	22, // 3: reviewer.v1.PullRequest.merged_at:type_name -> google.protobuf.Timestamp
+	0,  // 4: reviewer.v1.PullRequestShort.status:type_name -> reviewer.v1.PullRequestStatus
+	2,  // 5: reviewer.v1.AddTeamRequest.team:type_name -> reviewer.v1.Team
+	5,  // 6: reviewer.v1.GetUserReviewsResponse.pull_requests:type_name -> reviewer.v1.PullRequestShort
+	5,  // 7: reviewer.v1.ReviewQueueEntry.pull_request:type_name -> reviewer.v1.PullRequestShort
+	6,  // 8: reviewer.v1.GetAssignmentStatsResponse.stats:type_name -> reviewer.v1.AssignmentStats
+	7,  // 9: reviewer.v1.ReviewerService.AddTeam:input_type -> reviewer.v1.AddTeamRequest
+	8,  // 10: reviewer.v1.ReviewerService.GetTeam:input_type -> reviewer.v1.GetTeamRequest
+	9,  // 11: reviewer.v1.ReviewerService.RenameTeam:input_type -> reviewer.v1.RenameTeamRequest
+	10, // 12: reviewer.v1.ReviewerService.GetUser:input_type -> reviewer.v1.GetUserRequest
+	11, // 13: reviewer.v1.ReviewerService.SetUserActive:input_type -> reviewer.v1.SetUserActiveRequest
+	12, // 14: reviewer.v1.ReviewerService.CreatePullRequest:input_type -> reviewer.v1.CreatePullRequestRequest
+	13, // 15: reviewer.v1.ReviewerService.GetPullRequest:input_type -> reviewer.v1.GetPullRequestRequest
+	14, // 16: reviewer.v1.ReviewerService.MergePullRequest:input_type -> reviewer.v1.MergePullRequestRequest
+	15, // 17: reviewer.v1.ReviewerService.ReassignReviewer:input_type -> reviewer.v1.ReassignReviewerRequest
+	16, // 18: reviewer.v1.ReviewerService.GetUserReviews:input_type -> reviewer.v1.GetUserReviewsRequest
+	18, // 19: reviewer.v1.ReviewerService.StreamUserReviews:input_type -> reviewer.v1.StreamUserReviewsRequest
+	20, // 20: reviewer.v1.ReviewerService.GetAssignmentStats:input_type -> reviewer.v1.GetAssignmentStatsRequest
+	2,  // 21: reviewer.v1.ReviewerService.AddTeam:output_type -> reviewer.v1.Team
+	2,  // 22: reviewer.v1.ReviewerService.GetTeam:output_type -> reviewer.v1.Team
+	2,  // 23: reviewer.v1.ReviewerService.RenameTeam:output_type -> reviewer.v1.Team
+	3,  // 24: reviewer.v1.ReviewerService.GetUser:output_type -> reviewer.v1.User
+	3,  // 25: reviewer.v1.ReviewerService.SetUserActive:output_type -> reviewer.v1.User
+	4,  // 26: reviewer.v1.ReviewerService.CreatePullRequest:output_type -> reviewer.v1.PullRequest
+	4,  // 27: reviewer.v1.ReviewerService.GetPullRequest:output_type -> reviewer.v1.PullRequest
+	4,  // 28: reviewer.v1.ReviewerService.MergePullRequest:output_type -> reviewer.v1.PullRequest
+	4,  // 29: reviewer.v1.ReviewerService.ReassignReviewer:output_type -> reviewer.v1.PullRequest
+	17, // 30: reviewer.v1.ReviewerService.GetUserReviews:output_type -> reviewer.v1.GetUserReviewsResponse
+	19, // 31: reviewer.v1.ReviewerService.StreamUserReviews:output_type -> reviewer.v1.ReviewQueueEntry
+	21, // 32: reviewer.v1.ReviewerService.GetAssignmentStats:output_type -> reviewer.v1.GetAssignmentStatsResponse
+	21, // [21:33] is the sub-list for method output_type
+	9,  // [9:21] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_reviewer_v1_reviewer_proto_init() }
+func file_reviewer_v1_reviewer_proto_init() {
+	if File_reviewer_v1_reviewer_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_reviewer_v1_reviewer_proto_rawDesc), len(file_reviewer_v1_reviewer_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   21,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_reviewer_v1_reviewer_proto_goTypes,
+		DependencyIndexes: file_reviewer_v1_reviewer_proto_depIdxs,
+		EnumInfos:         file_reviewer_v1_reviewer_proto_enumTypes,
+		MessageInfos:      file_reviewer_v1_reviewer_proto_msgTypes,
+	}.Build()
+	File_reviewer_v1_reviewer_proto = out.File
+	file_reviewer_v1_reviewer_proto_goTypes = nil
+	file_reviewer_v1_reviewer_proto_depIdxs = nil
+}
@@ -0,0 +1,575 @@
+// gRPC API сервиса назначения ревьюверов. Повторяет REST API (openapi.yml):
+// команды, пользователи, PR, списки ревью и статистика. Коды ошибок:
+// NOT_FOUND — нет ресурса, ALREADY_EXISTS — ресурс уже есть,
+// INVALID_ARGUMENT — некорректный запрос, FAILED_PRECONDITION — операция
+// невозможна в текущем состоянии (PR смёржен, ревьювер не назначен, нет кандидата).
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v6.31.1
+// source: reviewer/v1/reviewer.proto
+
+package reviewerv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ReviewerService_AddTeam_FullMethodName            = "/reviewer.v1.ReviewerService/AddTeam"
+	ReviewerService_GetTeam_FullMethodName            = "/reviewer.v1.ReviewerService/GetTeam"
+	ReviewerService_RenameTeam_FullMethodName         = "/reviewer.v1.ReviewerService/RenameTeam"
+	ReviewerService_GetUser_FullMethodName            = "/reviewer.v1.ReviewerService/GetUser"
+	ReviewerService_SetUserActive_FullMethodName      = "/reviewer.v1.ReviewerService/SetUserActive"
+	ReviewerService_CreatePullRequest_FullMethodName  = "/reviewer.v1.ReviewerService/CreatePullRequest"
+	ReviewerService_GetPullRequest_FullMethodName     = "/reviewer.v1.ReviewerService/GetPullRequest"
+	ReviewerService_MergePullRequest_FullMethodName   = "/reviewer.v1.ReviewerService/MergePullRequest"
+	ReviewerService_ReassignReviewer_FullMethodName   = "/reviewer.v1.ReviewerService/ReassignReviewer"
+	ReviewerService_GetUserReviews_FullMethodName     = "/reviewer.v1.ReviewerService/GetUserReviews"
+	ReviewerService_StreamUserReviews_FullMethodName  = "/reviewer.v1.ReviewerService/StreamUserReviews"
+	ReviewerService_GetAssignmentStats_FullMethodName = "/reviewer.v1.ReviewerService/GetAssignmentStats"
+)
+
+// ReviewerServiceClient is the client API for ReviewerService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ReviewerServiceClient interface {
+	// Создать команду с участниками (создаёт/обновляет пользователей).
+	AddTeam(ctx context.Context, in *AddTeamRequest, opts ...grpc.CallOption) (*Team, error)
+	// Получить команду с участниками.
+	GetTeam(ctx context.Context, in *GetTeamRequest, opts ...grpc.CallOption) (*Team, error)
+	// Переименовать команду.
+	RenameTeam(ctx context.Context, in *RenameTeamRequest, opts ...grpc.CallOption) (*Team, error)
+	// Получить пользователя.
+	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error)
+	// Включить или выключить пользователя.
+	SetUserActive(ctx context.Context, in *SetUserActiveRequest, opts ...grpc.CallOption) (*User, error)
+	// Создать PR и назначить до двух ревьюверов из команды автора.
+	CreatePullRequest(ctx context.Context, in *CreatePullRequestRequest, opts ...grpc.CallOption) (*PullRequest, error)
+	// Получить PR.
+	GetPullRequest(ctx context.Context, in *GetPullRequestRequest, opts ...grpc.CallOption) (*PullRequest, error)
+	// Пометить PR как MERGED (идемпотентно).
+	MergePullRequest(ctx context.Context, in *MergePullRequestRequest, opts ...grpc.CallOption) (*PullRequest, error)
+	// Заменить ревьювера другим участником его команды.
+	ReassignReviewer(ctx context.Context, in *ReassignReviewerRequest, opts ...grpc.CallOption) (*PullRequest, error)
+	// PR, где пользователь назначен ревьювером.
+	GetUserReviews(ctx context.Context, in *GetUserReviewsRequest, opts ...grpc.CallOption) (*GetUserReviewsResponse, error)
+	// Очередь ревью пользователя потоком: сначала текущие PR, затем, если
+	// watch, изменения очереди до отмены вызова.
+	StreamUserReviews(ctx context.Context, in *StreamUserReviewsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReviewQueueEntry], error)
+	// Число назначений по ревьюверам.
+	GetAssignmentStats(ctx context.Context, in *GetAssignmentStatsRequest, opts ...grpc.CallOption) (*GetAssignmentStatsResponse, error)
+}
+
+type reviewerServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReviewerServiceClient(cc grpc.ClientConnInterface) ReviewerServiceClient {
+	return &reviewerServiceClient{cc}
+}
+
+func (c *reviewerServiceClient) AddTeam(ctx context.Context, in *AddTeamRequest, opts ...grpc.CallOption) (*Team, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Team)
+	err := c.cc.Invoke(ctx, ReviewerService_AddTeam_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reviewerServiceClient) GetTeam(ctx context.Context, in *GetTeamRequest, opts ...grpc.CallOption) (*Team, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Team)
+	err := c.cc.Invoke(ctx, ReviewerService_GetTeam_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reviewerServiceClient) RenameTeam(ctx context.Context, in *RenameTeamRequest, opts ...grpc.CallOption) (*Team, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Team)
+	err := c.cc.Invoke(ctx, ReviewerService_RenameTeam_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reviewerServiceClient) GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(User)
+	err := c.cc.Invoke(ctx, ReviewerService_GetUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reviewerServiceClient) SetUserActive(ctx context.Context, in *SetUserActiveRequest, opts ...grpc.CallOption) (*User, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(User)
+	err := c.cc.Invoke(ctx, ReviewerService_SetUserActive_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reviewerServiceClient) CreatePullRequest(ctx context.Context, in *CreatePullRequestRequest, opts ...grpc.CallOption) (*PullRequest, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PullRequest)
+	err := c.cc.Invoke(ctx, ReviewerService_CreatePullRequest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reviewerServiceClient) GetPullRequest(ctx context.Context, in *GetPullRequestRequest, opts ...grpc.CallOption) (*PullRequest, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PullRequest)
+	err := c.cc.Invoke(ctx, ReviewerService_GetPullRequest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reviewerServiceClient) MergePullRequest(ctx context.Context, in *MergePullRequestRequest, opts ...grpc.CallOption) (*PullRequest, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PullRequest)
+	err := c.cc.Invoke(ctx, ReviewerService_MergePullRequest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reviewerServiceClient) ReassignReviewer(ctx context.Context, in *ReassignReviewerRequest, opts ...grpc.CallOption) (*PullRequest, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PullRequest)
+	err := c.cc.Invoke(ctx, ReviewerService_ReassignReviewer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reviewerServiceClient) GetUserReviews(ctx context.Context, in *GetUserReviewsRequest, opts ...grpc.CallOption) (*GetUserReviewsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUserReviewsResponse)
+	err := c.cc.Invoke(ctx, ReviewerService_GetUserReviews_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reviewerServiceClient) StreamUserReviews(ctx context.Context, in *StreamUserReviewsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReviewQueueEntry], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ReviewerService_ServiceDesc.Streams[0], ReviewerService_StreamUserReviews_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamUserReviewsRequest, ReviewQueueEntry]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ReviewerService_StreamUserReviewsClient = grpc.ServerStreamingClient[ReviewQueueEntry]
+
+func (c *reviewerServiceClient) GetAssignmentStats(ctx context.Context, in *GetAssignmentStatsRequest, opts ...grpc.CallOption) (*GetAssignmentStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAssignmentStatsResponse)
+	err := c.cc.Invoke(ctx, ReviewerService_GetAssignmentStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReviewerServiceServer is the server API for ReviewerService service.
+// All implementations must embed UnimplementedReviewerServiceServer
+// for forward compatibility.
+type ReviewerServiceServer interface {
+	// Создать команду с участниками (создаёт/обновляет пользователей).
+	AddTeam(context.Context, *AddTeamRequest) (*Team, error)
+	// Получить команду с участниками.
+	GetTeam(context.Context, *GetTeamRequest) (*Team, error)
+	// Переименовать команду.
+	RenameTeam(context.Context, *RenameTeamRequest) (*Team, error)
+	// Получить пользователя.
+	GetUser(context.Context, *GetUserRequest) (*User, error)
+	// Включить или выключить пользователя.
+	SetUserActive(context.Context, *SetUserActiveRequest) (*User, error)
+	// Создать PR и назначить до двух ревьюверов из команды автора.
+	CreatePullRequest(context.Context, *CreatePullRequestRequest) (*PullRequest, error)
+	// Получить PR.
+	GetPullRequest(context.Context, *GetPullRequestRequest) (*PullRequest, error)
+	// Пометить PR как MERGED (идемпотентно).
+	MergePullRequest(context.Context, *MergePullRequestRequest) (*PullRequest, error)
+	// Заменить ревьювера другим участником его команды.
+	ReassignReviewer(context.Context, *ReassignReviewerRequest) (*PullRequest, error)
+	// PR, где пользователь назначен ревьювером.
+	GetUserReviews(context.Context, *GetUserReviewsRequest) (*GetUserReviewsResponse, error)
+	// Очередь ревью пользователя потоком: сначала текущие PR, затем, если
+	// watch, изменения очереди до отмены вызова.
+	StreamUserReviews(*StreamUserReviewsRequest, grpc.ServerStreamingServer[ReviewQueueEntry]) error
+	// Число назначений по ревьюверам.
+	GetAssignmentStats(context.Context, *GetAssignmentStatsRequest) (*GetAssignmentStatsResponse, error)
+	mustEmbedUnimplementedReviewerServiceServer()
+}
+
+// UnimplementedReviewerServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedReviewerServiceServer struct{}
+
+func (UnimplementedReviewerServiceServer) AddTeam(context.Context, *AddTeamRequest) (*Team, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddTeam not implemented")
+}
+func (UnimplementedReviewerServiceServer) GetTeam(context.Context, *GetTeamRequest) (*Team, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTeam not implemented")
+}
+func (UnimplementedReviewerServiceServer) RenameTeam(context.Context, *RenameTeamRequest) (*Team, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenameTeam not implemented")
+}
+func (UnimplementedReviewerServiceServer) GetUser(context.Context, *GetUserRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
+}
+func (UnimplementedReviewerServiceServer) SetUserActive(context.Context, *SetUserActiveRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetUserActive not implemented")
+}
+func (UnimplementedReviewerServiceServer) CreatePullRequest(context.Context, *CreatePullRequestRequest) (*PullRequest, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePullRequest not implemented")
+}
+func (UnimplementedReviewerServiceServer) GetPullRequest(context.Context, *GetPullRequestRequest) (*PullRequest, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPullRequest not implemented")
+}
+func (UnimplementedReviewerServiceServer) MergePullRequest(context.Context, *MergePullRequestRequest) (*PullRequest, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MergePullRequest not implemented")
+}
+func (UnimplementedReviewerServiceServer) ReassignReviewer(context.Context, *ReassignReviewerRequest) (*PullRequest, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReassignReviewer not implemented")
+}
+func (UnimplementedReviewerServiceServer) GetUserReviews(context.Context, *GetUserReviewsRequest) (*GetUserReviewsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserReviews not implemented")
+}
+func (UnimplementedReviewerServiceServer) StreamUserReviews(*StreamUserReviewsRequest, grpc.ServerStreamingServer[ReviewQueueEntry]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamUserReviews not implemented")
+}
+func (UnimplementedReviewerServiceServer) GetAssignmentStats(context.Context, *GetAssignmentStatsRequest) (*GetAssignmentStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAssignmentStats not implemented")
+}
+func (UnimplementedReviewerServiceServer) mustEmbedUnimplementedReviewerServiceServer() {}
+func (UnimplementedReviewerServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeReviewerServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReviewerServiceServer will
+// result in compilation errors.
+type UnsafeReviewerServiceServer interface {
+	mustEmbedUnimplementedReviewerServiceServer()
+}
+
+func RegisterReviewerServiceServer(s grpc.ServiceRegistrar, srv ReviewerServiceServer) {
+	// If the following call pancis, it indicates UnimplementedReviewerServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ReviewerService_ServiceDesc, srv)
+}
+
+func _ReviewerService_AddTeam_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddTeamRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReviewerServiceServer).AddTeam(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReviewerService_AddTeam_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReviewerServiceServer).AddTeam(ctx, req.(*AddTeamRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReviewerService_GetTeam_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTeamRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReviewerServiceServer).GetTeam(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReviewerService_GetTeam_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReviewerServiceServer).GetTeam(ctx, req.(*GetTeamRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReviewerService_RenameTeam_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameTeamRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReviewerServiceServer).RenameTeam(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReviewerService_RenameTeam_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReviewerServiceServer).RenameTeam(ctx, req.(*RenameTeamRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReviewerService_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReviewerServiceServer).GetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReviewerService_GetUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReviewerServiceServer).GetUser(ctx, req.(*GetUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReviewerService_SetUserActive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetUserActiveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReviewerServiceServer).SetUserActive(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReviewerService_SetUserActive_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReviewerServiceServer).SetUserActive(ctx, req.(*SetUserActiveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReviewerService_CreatePullRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePullRequestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReviewerServiceServer).CreatePullRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReviewerService_CreatePullRequest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReviewerServiceServer).CreatePullRequest(ctx, req.(*CreatePullRequestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReviewerService_GetPullRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPullRequestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReviewerServiceServer).GetPullRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReviewerService_GetPullRequest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReviewerServiceServer).GetPullRequest(ctx, req.(*GetPullRequestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReviewerService_MergePullRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MergePullRequestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReviewerServiceServer).MergePullRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReviewerService_MergePullRequest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReviewerServiceServer).MergePullRequest(ctx, req.(*MergePullRequestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReviewerService_ReassignReviewer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReassignReviewerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReviewerServiceServer).ReassignReviewer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReviewerService_ReassignReviewer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReviewerServiceServer).ReassignReviewer(ctx, req.(*ReassignReviewerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReviewerService_GetUserReviews_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserReviewsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReviewerServiceServer).GetUserReviews(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReviewerService_GetUserReviews_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReviewerServiceServer).GetUserReviews(ctx, req.(*GetUserReviewsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReviewerService_StreamUserReviews_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamUserReviewsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ReviewerServiceServer).StreamUserReviews(m, &grpc.GenericServerStream[StreamUserReviewsRequest, ReviewQueueEntry]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ReviewerService_StreamUserReviewsServer = grpc.ServerStreamingServer[ReviewQueueEntry]
+
+func _ReviewerService_GetAssignmentStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAssignmentStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReviewerServiceServer).GetAssignmentStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReviewerService_GetAssignmentStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReviewerServiceServer).GetAssignmentStats(ctx, req.(*GetAssignmentStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ReviewerService_ServiceDesc is the grpc.ServiceDesc for ReviewerService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ReviewerService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "reviewer.v1.ReviewerService",
+	HandlerType: (*ReviewerServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddTeam",
+			Handler:    _ReviewerService_AddTeam_Handler,
+		},
+		{
+			MethodName: "GetTeam",
+			Handler:    _ReviewerService_GetTeam_Handler,
+		},
+		{
+			MethodName: "RenameTeam",
+			Handler:    _ReviewerService_RenameTeam_Handler,
+		},
+		{
+			MethodName: "GetUser",
+			Handler:    _ReviewerService_GetUser_Handler,
+		},
+		{
+			MethodName: "SetUserActive",
+			Handler:    _ReviewerService_SetUserActive_Handler,
+		},
+		{
+			MethodName: "CreatePullRequest",
+			Handler:    _ReviewerService_CreatePullRequest_Handler,
+		},
+		{
+			MethodName: "GetPullRequest",
+			Handler:    _ReviewerService_GetPullRequest_Handler,
+		},
+		{
+			MethodName: "MergePullRequest",
+			Handler:    _ReviewerService_MergePullRequest_Handler,
+		},
+		{
+			MethodName: "ReassignReviewer",
+			Handler:    _ReviewerService_ReassignReviewer_Handler,
+		},
+		{
+			MethodName: "GetUserReviews",
+			Handler:    _ReviewerService_GetUserReviews_Handler,
+		},
+		{
+			MethodName: "GetAssignmentStats",
+			Handler:    _ReviewerService_GetAssignmentStats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamUserReviews",
+			Handler:       _ReviewerService_StreamUserReviews_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "reviewer/v1/reviewer.proto",
+}
@@ -2,13 +2,22 @@ package api_test
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -16,14 +25,31 @@ import (
 	_ "github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 
+	openapi "pull-request-api.com"
+	"pull-request-api.com/client"
 	"pull-request-api.com/internal/api"
 	"pull-request-api.com/internal/database"
+	"pull-request-api.com/internal/graphqlapi"
+	"pull-request-api.com/internal/grpcapi"
+	"pull-request-api.com/internal/idempotency"
 	"pull-request-api.com/internal/models"
+	"pull-request-api.com/internal/notify"
+	"pull-request-api.com/internal/ratelimit"
+	"pull-request-api.com/internal/scim"
 	"pull-request-api.com/internal/service"
+	reviewerv1 "pull-request-api.com/proto/reviewer/v1"
 )
 
-var testDB *sql.DB
+var (
+	testDB            *sql.DB
+	testMigrationsURL = "file://../migrations"
+)
 
 func getEnv(key, fallback string) string {
 	if value, ok := os.LookupEnv(key); ok {
@@ -32,7 +58,12 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// TEST_DB_DRIVER=sqlite прогоняет тесты на временной базе SQLite вместо PostgreSQL.
 func TestMain(m *testing.M) {
+	if getEnv("TEST_DB_DRIVER", "postgres") == "sqlite" {
+		os.Exit(runSQLite(m))
+	}
+
 	dbHost := getEnv("TEST_DB_HOST", "localhost")
 	dbPort := getEnv("TEST_DB_PORT", "55432")
 	dbUser := getEnv("DB_USER", "postgres")
@@ -57,7 +88,7 @@ func TestMain(m *testing.M) {
 
 	teardownDB()
 
-	if err := database.Migrate(testDB, "prdb_test", "file://../migrations"); err != nil {
+	if err := database.Migrate(testDB, "prdb_test", testMigrationsURL); err != nil {
 		log.Fatalf("Migration failed: %v", err)
 	}
 
@@ -68,14 +99,60 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
+func runSQLite(m *testing.M) int {
+	dir, err := os.MkdirTemp("", "prdb_test")
+	if err != nil {
+		log.Fatalf("Cannot create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	testDB, err = database.ConnectSQLite(filepath.Join(dir, "prdb_test.sqlite"))
+	if err != nil {
+		log.Fatalf("Cannot open test DB: %v", err)
+	}
+	defer testDB.Close()
+
+	testMigrationsURL = "file://../migrations/sqlite"
+	if err := database.Migrate(testDB, "prdb_test", testMigrationsURL); err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+	return m.Run()
+}
+
+var testTables = []string{"pr_reviewers", "pull_requests", "team_members", "users", "teams", "rate_limit_buckets", "idempotency_keys", "audit_log", "events"}
+
 func teardownDB() {
-	_, _ = testDB.Exec("TRUNCATE TABLE pr_reviewers, pull_requests, users, teams CASCADE")
+	if database.DialectOf(testDB) == database.SQLite {
+		// В SQLite нет TRUNCATE; таблицы перечислены в порядке внешних ключей.
+		for _, table := range testTables {
+			_, _ = testDB.Exec("DELETE FROM " + table)
+		}
+		return
+	}
+	_, _ = testDB.Exec("TRUNCATE TABLE " + strings.Join(testTables, ", ") + " CASCADE")
 }
 
+// requirePostgres пропускает тесты возможностей, доступных только с PostgreSQL.
+func requirePostgres(t *testing.T) {
+	t.Helper()
+	if database.DialectOf(testDB) != database.Postgres {
+		t.Skip("requires PostgreSQL")
+	}
+}
+
+// testSelectionSeed делает назначения ревьюверов в тестах воспроизводимыми.
+const testSelectionSeed = 1
+
 func setupServer() (*chi.Mux, *service.Service) {
-	svc := service.NewService(testDB)
+	validator, err := api.NewRequestValidator(openapi.Spec)
+	if err != nil {
+		log.Fatalf("OpenAPI spec is invalid: %v", err)
+	}
+
+	svc := service.NewService(testDB, service.WithSeed(testSelectionSeed))
 	srv := api.NewServer(svc)
 	r := chi.NewRouter()
+	r.Use(api.ValidateRequests(validator))
 	api.HandlerFromMux(srv, r)
 	return r, svc
 }
@@ -156,10 +233,18 @@ func TestIntegration_ReassignReviewer(t *testing.T) {
 	}
 	respReassign := postRequest(t, router, "/pullRequest/reassign", reassignBody, http.StatusOK)
 
+	// Единственный оставшийся кандидат — участник, не назначенный при создании.
+	var freeCandidate string
+	for _, id := range []string{"rev1", "rev2", "free_cand"} {
+		if !slices.Contains(pr.AssignedReviewers, id) {
+			freeCandidate = id
+		}
+	}
+
 	var prUpdated models.PullRequest
 	json.Unmarshal(respReassign, &prUpdated)
 	assert.NotContains(t, prUpdated.AssignedReviewers, oldReviewer, "Старый ревьювер должен исчезнуть")
-	assert.Contains(t, prUpdated.AssignedReviewers, "free_cand", "Свободный кандидат должен появиться (т.к. он единственный оставшийся)")
+	assert.Contains(t, prUpdated.AssignedReviewers, freeCandidate, "Свободный кандидат должен появиться (т.к. он единственный оставшийся)")
 }
 
 func TestIntegration_ErrorsAndEdgeCases(t *testing.T) {
@@ -186,10 +271,1851 @@ func TestIntegration_ErrorsAndEdgeCases(t *testing.T) {
 	reassign := models.PostPullRequestReassignJSONRequestBody{PullRequestId: "PR-1", OldUserId: "u2"}
 	respMerged := postRequest(t, router, "/pullRequest/reassign", reassign, http.StatusBadRequest)
 	assert.Contains(t, string(respMerged), string(models.PRMERGED))
+
+	respMissing := postRequest(t, router, "/pullRequest/merge", models.PostPullRequestMergeJSONRequestBody{PullRequestId: "PR-404"}, http.StatusNotFound)
+	assert.Contains(t, string(respMissing), string(models.NOTFOUND))
+
+	respEmpty := postRequest(t, router, "/pullRequest/create", models.PostPullRequestCreateJSONRequestBody{PullRequestId: "PR-2", PullRequestName: "Empty author"}, http.StatusBadRequest)
+	assert.Contains(t, string(respEmpty), string(models.VALIDATIONERROR))
+}
+
+func TestIntegration_RateLimitSharedStore(t *testing.T) {
+	requirePostgres(t)
+	teardownDB()
+	r := chi.NewRouter()
+	groups := []api.RateLimitGroup{{Name: "write", Methods: []string{http.MethodPost}, Limit: ratelimit.Limit{Rate: 0.01, Burst: 2}}}
+	// Две «реплики» с общим хранилищем в Postgres.
+	r.Use(api.RateLimit(ratelimit.NewPostgresStore(testDB), groups))
+	api.HandlerFromMux(api.NewServer(service.NewService(testDB)), r)
+
+	team := models.Team{TeamName: "RL", Members: []models.TeamMember{{UserId: "rl1", Username: "RL1", IsActive: true}}}
+	postRequest(t, r, "/team/add", team, http.StatusOK)
+
+	replica := chi.NewRouter()
+	replica.Use(api.RateLimit(ratelimit.NewPostgresStore(testDB), groups))
+	api.HandlerFromMux(api.NewServer(service.NewService(testDB)), replica)
+	postRequest(t, replica, "/team/add", team, http.StatusOK)
+
+	resp := postRequest(t, r, "/team/add", team, http.StatusTooManyRequests)
+	assert.Contains(t, string(resp), string(models.RATELIMITED))
+}
+
+func TestIntegration_RateLimitIgnoresUnverifiedTokens(t *testing.T) {
+	teardownDB()
+	r := chi.NewRouter()
+	groups := []api.RateLimitGroup{{Name: "write", Methods: []string{http.MethodPost}, Limit: ratelimit.Limit{Rate: 0.01, Burst: 2}}}
+	// Аутентификация выключена: токен никто не проверяет.
+	r.Use(api.RateLimit(ratelimit.NewMemoryStore(), groups))
+	api.HandlerFromMux(api.NewServer(service.NewService(testDB)), r)
+
+	team := models.Team{TeamName: "RLT", Members: []models.TeamMember{{UserId: "rlt1", Username: "RLT1", IsActive: true}}}
+	status := make([]int, 0, 3)
+	for i := range 3 {
+		var buf bytes.Buffer
+		json.NewEncoder(&buf).Encode(team)
+		req := httptest.NewRequest(http.MethodPost, "/team/add", &buf)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer random-%d", i))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		status = append(status, rr.Code)
+	}
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, status)
+}
+
+func TestIntegration_IdempotencyKey(t *testing.T) {
+	teardownDB()
+	r := chi.NewRouter()
+	r.Use(api.Idempotency(idempotency.NewStore(testDB), time.Hour, time.Minute))
+	api.HandlerFromMux(api.NewServer(service.NewService(testDB)), r)
+
+	team := models.Team{
+		TeamName: "Idem",
+		Members: []models.TeamMember{
+			{UserId: "i1", Username: "I1", IsActive: true},
+			{UserId: "i2", Username: "I2", IsActive: true},
+			{UserId: "i3", Username: "I3", IsActive: true},
+			{UserId: "i4", Username: "I4", IsActive: true},
+		},
+	}
+	postRequest(t, r, "/team/add", team, http.StatusOK)
+
+	create := models.PostPullRequestCreateJSONRequestBody{PullRequestId: "PR-I1", PullRequestName: "Idem", AuthorId: "i1"}
+	first := postWithKey(t, r, "/pullRequest/create", "create-1", create, http.StatusOK)
+	retry := postWithKey(t, r, "/pullRequest/create", "create-1", create, http.StatusOK)
+	assert.JSONEq(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "true", retry.Header().Get("Idempotent-Replayed"))
+
+	// Без ключа повтор по-прежнему конфликт.
+	postRequest(t, r, "/pullRequest/create", create, http.StatusConflict)
+
+	other := create
+	other.PullRequestName = "Other"
+	resp := postWithKey(t, r, "/pullRequest/create", "create-1", other, http.StatusUnprocessableEntity)
+	assert.Contains(t, resp.Body.String(), string(models.IDEMPOTENCYKEYREUSED))
+
+	var pr models.PullRequest
+	json.Unmarshal(first.Body.Bytes(), &pr)
+	reassign := models.PostPullRequestReassignJSONRequestBody{PullRequestId: "PR-I1", OldUserId: pr.AssignedReviewers[0]}
+	r1 := postWithKey(t, r, "/pullRequest/reassign", "reassign-1", reassign, http.StatusOK)
+	r2 := postWithKey(t, r, "/pullRequest/reassign", "reassign-1", reassign, http.StatusOK)
+	assert.JSONEq(t, r1.Body.String(), r2.Body.String(), "повтор не должен переназначать ещё раз")
+}
+
+func TestIntegration_IdempotencyLease(t *testing.T) {
+	teardownDB()
+	ctx := context.Background()
+	store := idempotency.NewStore(testDB)
+
+	// Запрос, чей процесс умер: ключ занят, но срок уже истёк.
+	saved, err := store.Begin(ctx, "scope", "k1", "hash", time.Hour, -time.Second)
+	require.NoError(t, err)
+	require.Nil(t, saved)
+
+	_, err = store.Begin(ctx, "scope", "k1", "other", time.Hour, time.Minute)
+	assert.ErrorIs(t, err, idempotency.ErrKeyReused)
+	saved, err = store.Begin(ctx, "scope", "k1", "hash", time.Hour, time.Minute)
+	require.NoError(t, err, "истёкший срок перехватывается повтором")
+	assert.Nil(t, saved)
+	_, err = store.Begin(ctx, "scope", "k1", "hash", time.Hour, time.Minute)
+	assert.ErrorIs(t, err, idempotency.ErrInProgress, "перехваченный ключ снова занят")
+
+	// Паника обработчика освобождает ключ сразу, не дожидаясь срока.
+	calls := 0
+	r := chi.NewRouter()
+	r.Use(api.Idempotency(store, time.Hour, time.Hour))
+	r.Post("/boom", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			panic("boom")
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	assert.Panics(t, func() { postWithKey(t, r, "/boom", "k2", map[string]int{}, http.StatusOK) })
+	postWithKey(t, r, "/boom", "k2", map[string]int{}, http.StatusOK)
+	assert.Equal(t, 2, calls)
+
+	// Тело сверх лимита не обрезается, а отклоняется.
+	big := map[string]string{"pad": strings.Repeat("x", 1<<20)}
+	resp := postWithKey(t, r, "/boom", "k3", big, http.StatusRequestEntityTooLarge)
+	assert.Contains(t, resp.Body.String(), string(models.PAYLOADTOOLARGE))
+	assert.Equal(t, 2, calls)
+}
+
+func TestIntegration_TeamLifecycle(t *testing.T) {
+	teardownDB()
+	router, _ := setupServer()
+
+	postRequest(t, router, "/team/add", models.Team{TeamName: "Old", Members: []models.TeamMember{
+		{UserId: "a", Username: "A", IsActive: true},
+		{UserId: "b", Username: "B", IsActive: true},
+		{UserId: "c", Username: "C", IsActive: true},
+		{UserId: "d", Username: "D", IsActive: true},
+	}}, http.StatusOK)
+	postRequest(t, router, "/team/add", models.Team{TeamName: "Other", Members: []models.TeamMember{}}, http.StatusOK)
+
+	// Переименование каскадно переносит участников.
+	resp := postRequest(t, router, "/team/rename", models.PostTeamRenameJSONRequestBody{TeamName: "Old", NewTeamName: "New"}, http.StatusOK)
+	var team models.Team
+	json.Unmarshal(resp, &team)
+	assert.Equal(t, "New", team.TeamName)
+	assert.Len(t, team.Members, 4)
+	postRequest(t, router, "/team/rename", models.PostTeamRenameJSONRequestBody{TeamName: "New", NewTeamName: "Other"}, http.StatusConflict)
+
+	respPR := postRequest(t, router, "/pullRequest/create", models.PostPullRequestCreateJSONRequestBody{
+		PullRequestId: "PR-L1", PullRequestName: "Lifecycle", AuthorId: "a",
+	}, http.StatusOK)
+	var pr models.PullRequest
+	json.Unmarshal(respPR, &pr)
+	require.Len(t, pr.AssignedReviewers, 2)
+	leaving := pr.AssignedReviewers[0]
+
+	// Перевод с передачей ревью: замену берут из прежней команды.
+	reassign := models.ReviewPolicyReassign
+	resp = postRequest(t, router, "/users/moveTeam", models.PostUsersMoveTeamJSONRequestBody{
+		UserId: leaving, TeamName: "Other", ReviewPolicy: &reassign,
+	}, http.StatusOK)
+	var moved models.UserMoveResult
+	json.Unmarshal(resp, &moved)
+	assert.Equal(t, "Other", moved.User.TeamName)
+	require.Len(t, moved.Reviews, 1)
+	require.NotNil(t, moved.Reviews[0].NewUserId)
+	assert.NotEqual(t, "a", *moved.Reviews[0].NewUserId)
+
+	var movedData string
+	require.NoError(t, testDB.QueryRow(`SELECT data FROM events WHERE event_type = 'UserMoved' AND aggregate_id = $1`, leaving).Scan(&movedData))
+	assert.JSONEq(t, `{"from_team": "New", "to_team": "Other"}`, movedData)
+
+	// Команда с участниками по умолчанию не удаляется.
+	resp = postRequest(t, router, "/team/delete", models.PostTeamDeleteJSONRequestBody{TeamName: "New"}, http.StatusConflict)
+	assert.Contains(t, string(resp), string(models.TEAMNOTEMPTY))
+
+	move := models.TeamMemberPolicyMove
+	target := "Other"
+	resp = postRequest(t, router, "/team/delete", models.PostTeamDeleteJSONRequestBody{
+		TeamName: "New", MemberPolicy: &move, TargetTeam: &target,
+	}, http.StatusOK)
+	var deleted models.TeamDeleteResult
+	json.Unmarshal(resp, &deleted)
+	assert.Len(t, deleted.MovedMembers, 3)
+
+	resp = getRequest(t, router, "/team/get?team_name=Other", http.StatusOK)
+	json.Unmarshal(resp, &team)
+	assert.Len(t, team.Members, 4)
+	getRequest(t, router, "/team/get?team_name=New", http.StatusNotFound)
+
+	var audited int
+	require.NoError(t, testDB.QueryRow(`SELECT COUNT(*) FROM audit_log`).Scan(&audited))
+	assert.Equal(t, 3, audited)
+}
+
+func TestIntegration_DeleteTeamKeepsOtherTeamReviews(t *testing.T) {
+	teardownDB()
+	router, _ := setupServer()
+
+	postRequest(t, router, "/team/add", models.Team{TeamName: "Legacy", Members: []models.TeamMember{
+		{UserId: "l1", Username: "L1", IsActive: true},
+		{UserId: "l2", Username: "L2", IsActive: true},
+	}}, http.StatusOK)
+	postRequest(t, router, "/team/add", models.Team{TeamName: "Squad", Members: []models.TeamMember{
+		{UserId: "s1", Username: "S1", IsActive: true},
+	}}, http.StatusOK)
+	// l2 дополнительно входит в Squad.
+	postRequest(t, router, "/team/add", models.Team{TeamName: "Squad", Membership: ptr(models.TeamMembershipAdditional), Members: []models.TeamMember{
+		{UserId: "l2", Username: "L2", IsActive: true},
+	}}, http.StatusOK)
+	postRequest(t, router, "/team/add", models.Team{TeamName: "Target", Members: []models.TeamMember{
+		{UserId: "t1", Username: "T1", IsActive: true},
+	}}, http.StatusOK)
+
+	create := func(id, author, team string) {
+		var pr models.PullRequest
+		json.Unmarshal(postRequest(t, router, "/pullRequest/create", models.PostPullRequestCreateJSONRequestBody{
+			PullRequestId: id, PullRequestName: "delete", AuthorId: author, TeamName: ptr(team),
+		}, http.StatusOK), &pr)
+		require.Equal(t, []string{"l2"}, pr.AssignedReviewers)
+	}
+	create("PR-LEGACY", "l1", "Legacy")
+	create("PR-SQUAD", "s1", "Squad")
+
+	var deleted models.TeamDeleteResult
+	json.Unmarshal(postRequest(t, router, "/team/delete", models.PostTeamDeleteJSONRequestBody{
+		TeamName: "Legacy", MemberPolicy: ptr(models.TeamMemberPolicyMove), TargetTeam: ptr("Target"),
+		ReviewPolicy: ptr(models.ReviewPolicyReassign),
+	}, http.StatusOK), &deleted)
+	assert.ElementsMatch(t, []string{"l1", "l2"}, deleted.MovedMembers)
+	require.Len(t, deleted.Reviews, 1)
+	assert.Equal(t, "PR-LEGACY", deleted.Reviews[0].PullRequestId)
+	require.NotNil(t, deleted.Reviews[0].NewUserId)
+	assert.Equal(t, "t1", *deleted.Reviews[0].NewUserId)
+
+	var pr models.PullRequest
+	json.Unmarshal(getRequest(t, router, "/pullRequest/get?pull_request_id=PR-LEGACY", http.StatusOK), &pr)
+	assert.Equal(t, []string{"t1"}, pr.AssignedReviewers)
+	json.Unmarshal(getRequest(t, router, "/pullRequest/get?pull_request_id=PR-SQUAD", http.StatusOK), &pr)
+	assert.Equal(t, []string{"l2"}, pr.AssignedReviewers)
+}
+
+func TestIntegration_MultiTeamMembership(t *testing.T) {
+	teardownDB()
+	router, _ := setupServer()
+
+	postRequest(t, router, "/team/add", models.Team{TeamName: "Platform", Members: []models.TeamMember{
+		{UserId: "p1", Username: "P1", IsActive: true},
+		{UserId: "p2", Username: "P2", IsActive: true},
+		{UserId: "p3", Username: "P3", IsActive: true},
+	}}, http.StatusOK)
+	// p1 дополнительно входит в продуктовую команду, основная команда не меняется.
+	postRequest(t, router, "/team/add", models.Team{TeamName: "Product", Membership: ptr(models.TeamMembershipAdditional), Members: []models.TeamMember{
+		{UserId: "p1", Username: "P1", IsActive: true},
+		{UserId: "q1", Username: "Q1", IsActive: true},
+		{UserId: "q2", Username: "Q2", IsActive: true},
+	}}, http.StatusOK)
+
+	var team models.Team
+	json.Unmarshal(getRequest(t, router, "/team/get?team_name=Platform", http.StatusOK), &team)
+	assert.Len(t, team.Members, 3)
+
+	product := "Product"
+	resp := postRequest(t, router, "/pullRequest/create", models.PostPullRequestCreateJSONRequestBody{
+		PullRequestId: "PR-M1", PullRequestName: "Squad feature", AuthorId: "p1", TeamName: &product,
+	}, http.StatusOK)
+	var pr models.PullRequest
+	json.Unmarshal(resp, &pr)
+	assert.ElementsMatch(t, []string{"q1", "q2"}, pr.AssignedReviewers)
+	require.NotNil(t, pr.TeamName)
+	assert.Equal(t, "Product", *pr.TeamName)
+
+	resp = postRequest(t, router, "/pullRequest/create", models.PostPullRequestCreateJSONRequestBody{
+		PullRequestId: "PR-M2", PullRequestName: "Platform fix", AuthorId: "p1",
+	}, http.StatusOK)
+	json.Unmarshal(resp, &pr)
+	assert.ElementsMatch(t, []string{"p2", "p3"}, pr.AssignedReviewers)
+
+	resp = postRequest(t, router, "/pullRequest/create", models.PostPullRequestCreateJSONRequestBody{
+		PullRequestId: "PR-M3", PullRequestName: "Foreign", AuthorId: "q1", TeamName: ptr("Platform"),
+	}, http.StatusBadRequest)
+	assert.Contains(t, string(resp), string(models.VALIDATIONERROR))
+
+	var user models.User
+	json.Unmarshal(postRequest(t, router, "/users/setIsActive", models.PostUsersSetIsActiveJSONRequestBody{UserId: "p1", IsActive: true}, http.StatusOK), &user)
+	assert.Equal(t, "Platform", user.TeamName)
+	assert.Equal(t, []string{"Platform", "Product"}, user.Teams)
+
+	var stats []models.AssignmentStats
+	json.Unmarshal(getRequest(t, router, "/users/getAssignmentStats?team_name=Product", http.StatusOK), &stats)
+	assert.Equal(t, []models.AssignmentStats{{UserId: "p1", Count: 0}, {UserId: "q1", Count: 1}, {UserId: "q2", Count: 1}}, stats)
+
+	postRequest(t, router, "/team/removeMember", models.PostTeamRemoveMemberJSONRequestBody{TeamName: "Platform", UserId: "p1"}, http.StatusBadRequest)
+	json.Unmarshal(postRequest(t, router, "/team/removeMember", models.PostTeamRemoveMemberJSONRequestBody{TeamName: "Product", UserId: "p1"}, http.StatusOK), &user)
+	assert.Equal(t, []string{"Platform"}, user.Teams)
+}
+
+func TestIntegration_MoveUserKeepsOtherTeamReviews(t *testing.T) {
+	teardownDB()
+	router, _ := setupServer()
+
+	postRequest(t, router, "/team/add", models.Team{TeamName: "A", Members: []models.TeamMember{
+		{UserId: "a1", Username: "A1", IsActive: true},
+		{UserId: "a2", Username: "A2", IsActive: true},
+		{UserId: "a3", Username: "A3", IsActive: true},
+	}}, http.StatusOK)
+	postRequest(t, router, "/team/add", models.Team{TeamName: "B", Membership: ptr(models.TeamMembershipAdditional), Members: []models.TeamMember{
+		{UserId: "a1", Username: "A1", IsActive: true},
+		{UserId: "b1", Username: "B1", IsActive: true},
+		{UserId: "b2", Username: "B2", IsActive: true},
+	}}, http.StatusOK)
+
+	var pr models.PullRequest
+	json.Unmarshal(postRequest(t, router, "/pullRequest/create", models.PostPullRequestCreateJSONRequestBody{
+		PullRequestId: "PR-A", PullRequestName: "A", AuthorId: "a2",
+	}, http.StatusOK), &pr)
+	require.ElementsMatch(t, []string{"a1", "a3"}, pr.AssignedReviewers)
+	json.Unmarshal(postRequest(t, router, "/pullRequest/create", models.PostPullRequestCreateJSONRequestBody{
+		PullRequestId: "PR-B", PullRequestName: "B", AuthorId: "b1", TeamName: ptr("B"),
+	}, http.StatusOK), &pr)
+	require.ElementsMatch(t, []string{"a1", "b2"}, pr.AssignedReviewers)
+
+	// a1 уходит из A, но остаётся в B: снимается только ревью на PR команды A.
+	postRequest(t, router, "/team/add", models.Team{TeamName: "C", Members: []models.TeamMember{
+		{UserId: "c1", Username: "C1", IsActive: true},
+	}}, http.StatusOK)
+	var moved models.UserMoveResult
+	json.Unmarshal(postRequest(t, router, "/users/moveTeam", models.PostUsersMoveTeamJSONRequestBody{
+		UserId: "a1", TeamName: "C", ReviewPolicy: ptr(models.ReviewPolicyUnassign),
+	}, http.StatusOK), &moved)
+	require.Len(t, moved.Reviews, 1)
+	assert.Equal(t, "PR-A", moved.Reviews[0].PullRequestId)
+	assert.Equal(t, []string{"B", "C"}, moved.User.Teams)
+
+	// По умолчанию /team/add переводит существующего пользователя, как раньше.
+	postRequest(t, router, "/team/add", models.Team{TeamName: "C", Members: []models.TeamMember{
+		{UserId: "a2", Username: "A2", IsActive: true},
+	}}, http.StatusOK)
+	var user models.User
+	json.Unmarshal(getRequest(t, router, "/users/get?user_id=a2", http.StatusOK), &user)
+	assert.Equal(t, "C", user.TeamName)
+	assert.Equal(t, []string{"C"}, user.Teams)
+}
+
+func TestIntegration_TeamHierarchy(t *testing.T) {
+	teardownDB()
+	router, _ := setupServer()
+
+	postRequest(t, router, "/team/add", models.Team{TeamName: "Payments", Members: []models.TeamMember{
+		{UserId: "lead", Username: "Lead", IsActive: true},
+	}}, http.StatusOK)
+	postRequest(t, router, "/team/add", models.Team{TeamName: "Core", Members: []models.TeamMember{
+		{UserId: "c1", Username: "C1", IsActive: true},
+		{UserId: "c2", Username: "C2", IsActive: true},
+	}}, http.StatusOK)
+	postRequest(t, router, "/team/add", models.Team{TeamName: "Risk", ParentTeam: ptr("Payments"), Members: []models.TeamMember{
+		{UserId: "r1", Username: "R1", IsActive: true},
+	}}, http.StatusOK)
+
+	// Без эскалации в маленькой команде назначается один ревьювер.
+	var pr models.PullRequest
+	json.Unmarshal(postRequest(t, router, "/pullRequest/create", models.PostPullRequestCreateJSONRequestBody{
+		PullRequestId: "PR-H1", PullRequestName: "Small squad", AuthorId: "c1",
+	}, http.StatusOK), &pr)
+	assert.Equal(t, []string{"c2"}, pr.AssignedReviewers)
+
+	postRequest(t, router, "/team/setParent", models.PostTeamSetParentJSONRequestBody{TeamName: "Core", ParentTeam: ptr("Payments")}, http.StatusOK)
+	var team models.Team
+	json.Unmarshal(postRequest(t, router, "/team/setEscalationPolicy", models.PostTeamSetEscalationPolicyJSONRequestBody{
+		TeamName: "Core", EscalationPolicy: models.EscalationPolicySiblingsThenParent,
+	}, http.StatusOK), &team)
+	require.NotNil(t, team.ParentTeam)
+	assert.Equal(t, "Payments", *team.ParentTeam)
+
+	// Цикл в иерархии запрещён.
+	postRequest(t, router, "/team/setParent", models.PostTeamSetParentJSONRequestBody{TeamName: "Payments", ParentTeam: ptr("Core")}, http.StatusBadRequest)
+
+	// Недостающий ревьювер берётся сначала из соседней команды.
+	json.Unmarshal(postRequest(t, router, "/pullRequest/create", models.PostPullRequestCreateJSONRequestBody{
+		PullRequestId: "PR-H2", PullRequestName: "Sibling", AuthorId: "c1",
+	}, http.StatusOK), &pr)
+	assert.ElementsMatch(t, []string{"c2", "r1"}, pr.AssignedReviewers)
+
+	// Когда в команде никого нет, добираем из соседей и затем из родителя.
+	postRequest(t, router, "/users/setIsActive", models.PostUsersSetIsActiveJSONRequestBody{UserId: "c2", IsActive: false}, http.StatusOK)
+	json.Unmarshal(postRequest(t, router, "/pullRequest/create", models.PostPullRequestCreateJSONRequestBody{
+		PullRequestId: "PR-H3", PullRequestName: "Parent", AuthorId: "c1",
+	}, http.StatusOK), &pr)
+	assert.ElementsMatch(t, []string{"r1", "lead"}, pr.AssignedReviewers)
+
+	var tree []models.TeamNode
+	json.Unmarshal(getRequest(t, router, "/team/tree", http.StatusOK), &tree)
+	require.Len(t, tree, 1)
+	assert.Equal(t, "Payments", tree[0].TeamName)
+	require.Len(t, tree[0].Children, 2)
+	assert.Equal(t, "Core", tree[0].Children[0].TeamName)
+	assert.Equal(t, 2, tree[0].Children[0].MembersCount)
+
+	var list models.PullRequestList
+	json.Unmarshal(getRequest(t, router, "/pullRequest/list?team_name=Payments&include_subteams=true&status=OPEN", http.StatusOK), &list)
+	assert.Len(t, list.PullRequests, 3)
+	assert.Nil(t, list.NextCursor)
+
+	// Постраничная выдача в порядке создания.
+	var page models.PullRequestList
+	var paged []string
+	query := "/pullRequest/list?team_name=Payments&include_subteams=true&limit=2"
+	for {
+		page = models.PullRequestList{}
+		json.Unmarshal(getRequest(t, router, query, http.StatusOK), &page)
+		for _, p := range page.PullRequests {
+			paged = append(paged, p.PullRequestId)
+		}
+		if page.NextCursor == nil {
+			break
+		}
+		query = "/pullRequest/list?team_name=Payments&include_subteams=true&limit=2&cursor=" + *page.NextCursor
+	}
+	assert.Equal(t, []string{"PR-H1", "PR-H2", "PR-H3"}, paged)
+	getRequest(t, router, "/pullRequest/list?cursor=missing", http.StatusBadRequest)
+
+	json.Unmarshal(getRequest(t, router, "/pullRequest/list?team_name=Payments", http.StatusOK), &list)
+	assert.Empty(t, list.PullRequests)
+
+	var stats []models.AssignmentStats
+	json.Unmarshal(getRequest(t, router, "/users/getAssignmentStats?team_name=Payments&include_subteams=true", http.StatusOK), &stats)
+	assert.Equal(t, []models.AssignmentStats{
+		{UserId: "c1", Count: 0}, {UserId: "c2", Count: 2}, {UserId: "lead", Count: 1}, {UserId: "r1", Count: 2},
+	}, stats)
+
+	// Подкоманды удалённого департамента становятся корневыми.
+	postRequest(t, router, "/team/delete", models.PostTeamDeleteJSONRequestBody{
+		TeamName: "Payments", MemberPolicy: ptr(models.TeamMemberPolicyMove), TargetTeam: ptr("Risk"),
+	}, http.StatusOK)
+	json.Unmarshal(getRequest(t, router, "/team/tree", http.StatusOK), &tree)
+	assert.Len(t, tree, 2)
+}
+
+func TestIntegration_UserDirectory(t *testing.T) {
+	teardownDB()
+	router, _ := setupServer()
+	setupUser(t, router)
+	postRequest(t, router, "/team/add", models.Team{TeamName: "T2", Membership: ptr(models.TeamMembershipAdditional), Members: []models.TeamMember{
+		{UserId: "u1", Username: "User1", IsActive: true},
+		{UserId: "u4", Username: "Alina", IsActive: false},
+	}}, http.StatusOK)
+
+	var user models.User
+	json.Unmarshal(getRequest(t, router, "/users/get?user_id=u1", http.StatusOK), &user)
+	assert.Equal(t, []string{"T1", "T2"}, user.Teams)
+	getRequest(t, router, "/users/get?user_id=nope", http.StatusNotFound)
+
+	// Постраничная выдача по user_id.
+	var page models.UserList
+	json.Unmarshal(getRequest(t, router, "/users/list?limit=2", http.StatusOK), &page)
+	require.Len(t, page.Users, 2)
+	require.NotNil(t, page.NextCursor)
+	assert.Equal(t, "u2", *page.NextCursor)
+	json.Unmarshal(getRequest(t, router, "/users/list?limit=2&cursor=u2", http.StatusOK), &page)
+	assert.Equal(t, "u3", page.Users[0].UserId)
+	assert.Nil(t, page.NextCursor)
+
+	ids := func(list models.UserList) []string {
+		var res []string
+		for _, u := range list.Users {
+			res = append(res, u.UserId)
+		}
+		return res
+	}
+	json.Unmarshal(getRequest(t, router, "/users/list?team_name=T2", http.StatusOK), &page)
+	assert.Equal(t, []string{"u1", "u4"}, ids(page))
+	json.Unmarshal(getRequest(t, router, "/users/list?name_prefix=AL", http.StatusOK), &page)
+	assert.Equal(t, []string{"u4"}, ids(page))
+	json.Unmarshal(getRequest(t, router, "/users/list?is_active=false", http.StatusOK), &page)
+	assert.Equal(t, []string{"u4"}, ids(page))
+
+	json.Unmarshal(patchRequest(t, router, "/users/update", models.PatchUsersUpdateJSONRequestBody{UserId: "u2", Username: "Bob"}, http.StatusOK), &user)
+	assert.Equal(t, "Bob", user.Username)
+
+	// Пользователь без истории удаляется полностью.
+	var deleted models.UserDeleteResult
+	json.Unmarshal(postRequest(t, router, "/users/delete", models.PostUsersDeleteJSONRequestBody{UserId: "u4"}, http.StatusOK), &deleted)
+	assert.Equal(t, models.UserDeleteResultResultDeleted, deleted.Result)
+	getRequest(t, router, "/users/get?user_id=u4", http.StatusNotFound)
+
+	var pr models.PullRequest
+	json.Unmarshal(postRequest(t, router, "/pullRequest/create", models.PostPullRequestCreateJSONRequestBody{
+		PullRequestId: "PR-D1", PullRequestName: "History", AuthorId: "u1",
+	}, http.StatusOK), &pr)
+	require.ElementsMatch(t, []string{"u2", "u3"}, pr.AssignedReviewers)
+
+	// С историей — по умолчанию отказ, с mode=anonymize — обезличивание без потери PR.
+	resp := postRequest(t, router, "/users/delete", models.PostUsersDeleteJSONRequestBody{UserId: "u2"}, http.StatusConflict)
+	assert.Contains(t, string(resp), string(models.USERHASHISTORY))
+
+	anonymize := models.UserDeleteModeAnonymize
+	json.Unmarshal(postRequest(t, router, "/users/delete", models.PostUsersDeleteJSONRequestBody{UserId: "u2", Mode: &anonymize}, http.StatusOK), &deleted)
+	assert.Equal(t, models.UserDeleteResultResultAnonymized, deleted.Result)
+	require.Len(t, deleted.Reviews, 1)
+	assert.Nil(t, deleted.Reviews[0].NewUserId)
+
+	json.Unmarshal(getRequest(t, router, "/users/get?user_id=u2", http.StatusOK), &user)
+	assert.NotNil(t, user.DeletedAt)
+	assert.False(t, user.IsActive)
+	assert.Empty(t, user.Teams)
+	assert.NotEqual(t, "Bob", user.Username)
+
+	json.Unmarshal(postRequest(t, router, "/pullRequest/merge", models.PostPullRequestMergeJSONRequestBody{PullRequestId: "PR-D1"}, http.StatusOK), &pr)
+	assert.Equal(t, []string{"u3"}, pr.AssignedReviewers)
+
+	json.Unmarshal(getRequest(t, router, "/users/list", http.StatusOK), &page)
+	assert.Equal(t, []string{"u1", "u3"}, ids(page))
+	postRequest(t, router, "/users/setIsActive", models.PostUsersSetIsActiveJSONRequestBody{UserId: "u2", IsActive: true}, http.StatusNotFound)
+}
+
+func TestIntegration_ImportTeams(t *testing.T) {
+	teardownDB()
+	router, _ := setupServer()
+	setupUser(t, router)
+
+	file := "team_name,user_id,username,is_active,parent_team\n" +
+		"Squad,u4,User4,true,T1\n" +
+		"Squad,u1,Alice,,\n"
+
+	// dry run показывает изменения и ничего не меняет.
+	var plan models.OrgPlan
+	json.Unmarshal(postRaw(t, router, "/team/import?dry_run=true", "text/csv", file, http.StatusOK), &plan)
+	assert.False(t, plan.Applied)
+	var actions []models.OrgChangeAction
+	for _, c := range plan.Changes {
+		actions = append(actions, c.Action)
+	}
+	assert.Equal(t, []models.OrgChangeAction{
+		models.OrgChangeActionCreateTeam, models.OrgChangeActionUpdateTeam,
+		models.OrgChangeActionCreateUser, models.OrgChangeActionAddMember, models.OrgChangeActionUpdateUser,
+	}, actions)
+	getRequest(t, router, "/team/get?team_name=Squad", http.StatusNotFound)
+
+	json.Unmarshal(postRaw(t, router, "/team/import", "text/csv", file, http.StatusOK), &plan)
+	assert.True(t, plan.Applied)
+	var team models.Team
+	json.Unmarshal(getRequest(t, router, "/team/get?team_name=Squad", http.StatusOK), &team)
+	assert.Len(t, team.Members, 2)
+	require.NotNil(t, team.ParentTeam)
+	assert.Equal(t, "T1", *team.ParentTeam)
+
+	var user models.User
+	json.Unmarshal(getRequest(t, router, "/users/get?user_id=u1", http.StatusOK), &user)
+	assert.Equal(t, "Alice", user.Username)
+	assert.Equal(t, "T1", user.TeamName)
+
+	// Повторный импорт того же файла ничего не меняет.
+	json.Unmarshal(postRaw(t, router, "/team/import", "text/csv", file, http.StatusOK), &plan)
+	assert.Empty(t, plan.Changes)
+
+	// Ошибка в любой команде отменяет весь импорт.
+	yamlFile := "teams:\n" +
+		"  - team_name: New1\n    members:\n      - {user_id: u9, username: X}\n" +
+		"  - team_name: New2\n    parent_team: Ghost\n"
+	postRaw(t, router, "/team/import", "application/yaml", yamlFile, http.StatusBadRequest)
+	getRequest(t, router, "/team/get?team_name=New1", http.StatusNotFound)
+
+	resp := postRaw(t, router, "/team/import", "application/yaml", "teams:\n  - team_name: A\n    members: [{user_id: u1, username: A}]\n  - team_name: B\n    members: [{user_id: u1, username: A}]\n", http.StatusBadRequest)
+	assert.Contains(t, string(resp), "teams.1.members.0.user_id")
+}
+
+func TestIntegration_SyncOrg(t *testing.T) {
+	teardownDB()
+	router, _ := setupServer()
+	setupUser(t, router)
+	postRequest(t, router, "/team/add", models.Team{
+		TeamName: "T2",
+		Members: []models.TeamMember{
+			{UserId: "u4", Username: "User4", IsActive: true},
+			{UserId: "u5", Username: "User5", IsActive: true},
+		},
+	}, http.StatusOK)
+	postRequest(t, router, "/pullRequest/create", models.PostPullRequestCreateJSONRequestBody{
+		PullRequestId: "PR-S", PullRequestName: "Sync", AuthorId: "u1",
+	}, http.StatusOK)
+
+	// u3 уходит в новую команду T3, u5 переходит в T1, u4 и T2 в файле нет.
+	file := "teams:\n" +
+		"  - team_name: T1\n    members:\n" +
+		"      - {user_id: u1, username: User1}\n" +
+		"      - {user_id: u2, username: User2}\n" +
+		"      - {user_id: u5, username: User5}\n" +
+		"  - team_name: T3\n    parent_team: T1\n    members:\n" +
+		"      - {user_id: u3, username: User3}\n" +
+		"      - {user_id: u1, username: User1}\n"
+
+	var plan models.OrgPlan
+	json.Unmarshal(postRaw(t, router, "/org/sync?dry_run=true", "application/yaml", file, http.StatusOK), &plan)
+	assert.False(t, plan.Applied)
+	var actions []models.OrgChangeAction
+	for _, c := range plan.Changes {
+		actions = append(actions, c.Action)
+	}
+	assert.Equal(t, []models.OrgChangeAction{
+		models.OrgChangeActionCreateTeam, models.OrgChangeActionUpdateTeam,
+		models.OrgChangeActionMoveUser, models.OrgChangeActionMoveUser,
+		models.OrgChangeActionAddMember, models.OrgChangeActionAddMember, models.OrgChangeActionAddMember,
+		models.OrgChangeActionRemoveMember, models.OrgChangeActionRemoveMember,
+		models.OrgChangeActionDeactivateUser, models.OrgChangeActionDeleteTeam,
+	}, actions)
+	// План показывает и ревью, которые будут переназначены.
+	require.NotNil(t, plan.Reviews)
+	require.Len(t, *plan.Reviews, 1)
+	assert.Equal(t, "u3", (*plan.Reviews)[0].OldUserId)
+	getRequest(t, router, "/team/get?team_name=T3", http.StatusNotFound)
+	var pr models.PullRequest
+	json.Unmarshal(getRequest(t, router, "/pullRequest/get?pull_request_id=PR-S", http.StatusOK), &pr)
+	assert.ElementsMatch(t, []string{"u2", "u3"}, pr.AssignedReviewers, "dry run ничего не меняет")
+
+	json.Unmarshal(postRaw(t, router, "/org/sync", "application/yaml", file, http.StatusOK), &plan)
+	assert.True(t, plan.Applied)
+	require.NotNil(t, plan.Reviews)
+	require.Len(t, *plan.Reviews, 1)
+	assert.Equal(t, "u3", (*plan.Reviews)[0].OldUserId)
+	assert.Equal(t, "u5", *(*plan.Reviews)[0].NewUserId)
+
+	getRequest(t, router, "/team/get?team_name=T2", http.StatusNotFound)
+	var user models.User
+	json.Unmarshal(getRequest(t, router, "/users/get?user_id=u4", http.StatusOK), &user)
+	assert.False(t, user.IsActive)
+	json.Unmarshal(getRequest(t, router, "/users/get?user_id=u3", http.StatusOK), &user)
+	assert.Equal(t, "T3", user.TeamName)
+
+	// Повторная синхронизация с тем же файлом ничего не меняет.
+	json.Unmarshal(postRaw(t, router, "/org/sync", "application/yaml", file, http.StatusOK), &plan)
+	assert.Empty(t, plan.Changes)
+
+	// С absent_users=delete пользователь без истории удаляется совсем.
+	postRaw(t, router, "/org/sync?absent_users=delete", "application/yaml", file, http.StatusOK)
+	getRequest(t, router, "/users/get?user_id=u4", http.StatusNotFound)
+
+	postRaw(t, router, "/org/sync", "application/yaml", "teams: []\n", http.StatusBadRequest)
+}
+
+func TestIntegration_SCIM(t *testing.T) {
+	teardownDB()
+	router, svc := setupServer()
+	setupUser(t, router)
+	scimRouter := chi.NewRouter()
+	scimRouter.Mount("/scim/v2", scim.NewHandler(svc, "/scim/v2"))
+
+	postRequest(t, router, "/pullRequest/create", models.PostPullRequestCreateJSONRequestBody{
+		PullRequestId: "PR-SCIM", PullRequestName: "SCIM", AuthorId: "u1",
+	}, http.StatusOK)
+
+	scimRequest(t, scimRouter, http.MethodPost, "/scim/v2/Users", `{"userName":"u4","displayName":"User4"}`, http.StatusCreated)
+	scimRequest(t, scimRouter, http.MethodPost, "/scim/v2/Users", `{"userName":"u4"}`, http.StatusConflict)
+	scimRequest(t, scimRouter, http.MethodPatch, "/scim/v2/Groups/T1",
+		`{"Operations":[{"op":"add","path":"members","value":[{"value":"u4"}]}]}`, http.StatusOK)
+
+	// Исключённый из группы u3 передаёт ревью новому участнику u4 и остаётся без команды.
+	scimRequest(t, scimRouter, http.MethodPatch, "/scim/v2/Groups/T1",
+		`{"Operations":[{"op":"remove","path":"members[value eq \"u3\"]"}]}`, http.StatusOK)
+	var list models.PullRequestList
+	json.Unmarshal(getRequest(t, router, "/pullRequest/list?team_name=T1", http.StatusOK), &list)
+	require.Len(t, list.PullRequests, 1)
+	assert.ElementsMatch(t, []string{"u2", "u4"}, list.PullRequests[0].AssignedReviewers)
+	var user models.User
+	json.Unmarshal(getRequest(t, router, "/users/get?user_id=u3", http.StatusOK), &user)
+	assert.Empty(t, user.TeamName)
+
+	// Деактивация через SCIM — то же, что /users/setIsActive; имя меняется
+	// в той же транзакции.
+	scimRequest(t, scimRouter, http.MethodPatch, "/scim/v2/Users/u2",
+		`{"Operations":[{"op":"replace","value":{"active":false,"displayName":"Renamed"}}]}`, http.StatusOK)
+	json.Unmarshal(getRequest(t, router, "/users/get?user_id=u2", http.StatusOK), &user)
+	assert.False(t, user.IsActive)
+	assert.Equal(t, "Renamed", user.Username)
+
+	resp := scimRequest(t, scimRouter, http.MethodGet, "/scim/v2/Users?filter=userName%20eq%20%22u4%22", "", http.StatusOK)
+	assert.Contains(t, string(resp), `"totalResults":1`)
+
+	var page scim.ListResponse
+	json.Unmarshal(scimRequest(t, scimRouter, http.MethodGet, "/scim/v2/Users?startIndex=2&count=2", "", http.StatusOK), &page)
+	assert.Equal(t, 4, page.TotalResults)
+	assert.Equal(t, 2, page.ItemsPerPage)
+	require.Len(t, page.Resources, 2)
+	assert.Equal(t, "u2", page.Resources[0].(map[string]any)["id"])
+	assert.Equal(t, "u3", page.Resources[1].(map[string]any)["id"])
+
+	scimRequest(t, scimRouter, http.MethodDelete, "/scim/v2/Groups/T1", "", http.StatusNoContent)
+	getRequest(t, router, "/team/get?team_name=T1", http.StatusNotFound)
+	json.Unmarshal(getRequest(t, router, "/users/get?user_id=u1", http.StatusOK), &user)
+	assert.Empty(t, user.Teams)
+}
+
+func TestIntegration_EventCursorGaps(t *testing.T) {
+	teardownDB()
+	ctx := context.Background()
+	svc := service.NewService(testDB)
+
+	var base int64
+	require.NoError(t, testDB.QueryRow(`SELECT COALESCE(MAX(id), 0) + 1000 FROM events`).Scan(&base))
+	insert := func(id int64) {
+		t.Helper()
+		_, err := testDB.Exec(fmt.Sprintf(`INSERT INTO events (id, aggregate_type, aggregate_id, event_type)
+			VALUES (%d, 'user', 'gap-user', 'UserDeactivated')`, id))
+		require.NoError(t, err)
+	}
+	defer testDB.Exec(fmt.Sprintf(`DELETE FROM events WHERE id > %d`, base))
+
+	cursor, err := svc.NewEventCursor(ctx, service.EventFilter{}, &base)
+	require.NoError(t, err)
+
+	// Событие с большим номером отдаётся сразу, не дожидаясь пропущенных.
+	insert(base + 3)
+	events, err := cursor.Next(ctx)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, base+3, events[0].Id)
+
+	// Транзакция с меньшим номером закоммитилась позже — событие не теряется.
+	insert(base + 1)
+	events, err = cursor.Next(ctx)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, base+1, events[0].Id)
+	assert.Equal(t, base+3, cursor.Last())
+
+	events, err = cursor.Next(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, events)
 }
 
 // --- Хэлперы ---
 
+func TestIntegration_Client(t *testing.T) {
+	teardownDB()
+	validator, err := api.NewRequestValidator(openapi.Spec)
+	require.NoError(t, err)
+	r := chi.NewRouter()
+	r.NotFound(api.NotFound)
+	r.Use(api.ValidateRequests(validator))
+	r.Use(api.Idempotency(idempotency.NewStore(testDB), time.Hour, time.Minute))
+	api.HandlerFromMux(api.NewServer(service.NewService(testDB)), r)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := client.New(srv.URL)
+
+	_, err = c.AddTeam(ctx, client.Team{
+		TeamName: "sdk",
+		Members: []client.TeamMember{
+			{UserId: "s1", Username: "S1", IsActive: true},
+			{UserId: "s2", Username: "S2", IsActive: true},
+			{UserId: "s3", Username: "S3", IsActive: true},
+		},
+	})
+	require.NoError(t, err)
+	_, err = c.AddTeam(ctx, client.Team{TeamName: "sdk-child", Members: []client.TeamMember{{UserId: "s4", Username: "S4", IsActive: true}}})
+	require.NoError(t, err)
+
+	_, err = c.GetTeam(ctx, "missing")
+	assert.ErrorIs(t, err, client.ErrNotFound)
+	_, err = c.RenameTeam(ctx, client.RenameTeamRequest{TeamName: "sdk-child", NewTeamName: "sdk"})
+	assert.ErrorIs(t, err, client.ErrTeamExists)
+
+	parent := "sdk"
+	child, err := c.SetTeamParent(ctx, client.SetTeamParentRequest{TeamName: "sdk-child", ParentTeam: &parent})
+	require.NoError(t, err)
+	assert.Equal(t, &parent, child.ParentTeam)
+	_, err = c.SetEscalationPolicy(ctx, client.SetEscalationPolicyRequest{TeamName: "sdk", EscalationPolicy: client.EscalationPolicyNone})
+	require.NoError(t, err)
+	tree, err := c.GetTeamTree(ctx, "sdk")
+	require.NoError(t, err)
+	require.Len(t, tree, 1)
+	assert.Equal(t, "sdk-child", tree[0].Children[0].TeamName)
+
+	pr, err := c.CreatePullRequest(ctx, client.CreatePullRequestRequest{PullRequestId: "PR-SDK", PullRequestName: "SDK", AuthorId: "s1"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"s2", "s3"}, pr.AssignedReviewers)
+	_, err = c.CreatePullRequest(ctx, client.CreatePullRequestRequest{PullRequestId: "PR-SDK", PullRequestName: "SDK", AuthorId: "s1"})
+	assert.ErrorIs(t, err, client.ErrPRExists)
+
+	got, err := c.GetPullRequest(ctx, "PR-SDK")
+	require.NoError(t, err)
+	assert.Equal(t, pr.AssignedReviewers, got.AssignedReviewers)
+	_, err = c.ReassignReviewer(ctx, client.ReassignRequest{PullRequestId: "PR-SDK", OldUserId: "s2"})
+	assert.ErrorIs(t, err, client.ErrNoCandidate)
+	_, err = c.ReassignReviewer(ctx, client.ReassignRequest{PullRequestId: "PR-SDK", OldUserId: "s1"})
+	assert.ErrorIs(t, err, client.ErrNotAssigned)
+
+	reviews, err := c.GetUserReviews(ctx, "s2")
+	require.NoError(t, err)
+	require.Len(t, reviews, 1)
+	assert.Equal(t, "PR-SDK", reviews[0].PullRequestId)
+	team := "sdk"
+	stats, err := c.GetAssignmentStats(ctx, client.AssignmentStatsParams{TeamName: &team})
+	require.NoError(t, err)
+	assert.NotEmpty(t, stats)
+
+	merged, err := c.MergePullRequest(ctx, "PR-SDK")
+	require.NoError(t, err)
+	assert.Equal(t, client.PullRequestStatusMerged, merged.Status)
+	_, err = c.ReassignReviewer(ctx, client.ReassignRequest{PullRequestId: "PR-SDK", OldUserId: "s2"})
+	assert.ErrorIs(t, err, client.ErrPRMerged)
+	status := client.PullRequestStatusMerged
+	prs, err := c.ListPullRequests(ctx, client.ListPullRequestsParams{TeamName: &team, Status: &status})
+	require.NoError(t, err)
+	assert.Len(t, prs.PullRequests, 1)
+
+	user, err := c.SetUserActive(ctx, "s3", false)
+	require.NoError(t, err)
+	assert.False(t, user.IsActive)
+	user, err = c.UpdateUser(ctx, client.UpdateUserRequest{UserId: "s3", Username: "S3 renamed"})
+	require.NoError(t, err)
+	assert.Equal(t, "S3 renamed", user.Username)
+	user, err = c.GetUser(ctx, "s3")
+	require.NoError(t, err)
+	assert.Equal(t, "S3 renamed", user.Username)
+
+	limit := 2
+	page, err := c.ListUsers(ctx, client.ListUsersParams{TeamName: &team, Limit: &limit})
+	require.NoError(t, err)
+	assert.Len(t, page.Users, 2)
+	require.NotNil(t, page.NextCursor)
+	page, err = c.ListUsers(ctx, client.ListUsersParams{TeamName: &team, Limit: &limit, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.Len(t, page.Users, 1)
+
+	moved, err := c.MoveUser(ctx, client.MoveUserRequest{UserId: "s4", TeamName: "sdk"})
+	require.NoError(t, err)
+	assert.Equal(t, "sdk", moved.User.TeamName)
+	_, err = c.RemoveTeamMember(ctx, client.RemoveTeamMemberRequest{UserId: "s1", TeamName: "sdk"})
+	assert.ErrorIs(t, err, client.ErrValidation)
+	_, err = c.DeleteTeam(ctx, client.DeleteTeamRequest{TeamName: "sdk"})
+	assert.ErrorIs(t, err, client.ErrTeamNotEmpty)
+	deletedTeam, err := c.DeleteTeam(ctx, client.DeleteTeamRequest{TeamName: "sdk-child"})
+	require.NoError(t, err)
+	assert.Equal(t, "sdk-child", deletedTeam.TeamName)
+
+	_, err = c.DeleteUser(ctx, client.DeleteUserRequest{UserId: "s1"})
+	assert.ErrorIs(t, err, client.ErrUserHasHistory)
+	deleted, err := c.DeleteUser(ctx, client.DeleteUserRequest{UserId: "s4"})
+	require.NoError(t, err)
+	assert.Equal(t, models.UserDeleteResultResultDeleted, deleted.Result)
+
+	csv := "team_name,user_id,username,is_active\nsdk,s5,S5,true\n"
+	plan, err := c.ImportTeams(ctx, []byte(csv), client.ContentTypeCSV, true)
+	require.NoError(t, err)
+	assert.False(t, plan.Applied)
+	assert.NotEmpty(t, plan.Changes)
+	plan, err = c.SyncOrg(ctx, []byte(csv), client.ContentTypeCSV, client.AbsentUsersDeactivate, true)
+	require.NoError(t, err)
+	assert.False(t, plan.Applied)
+	_, err = c.ImportTeams(ctx, []byte("bogus"), client.ContentTypeCSV, false)
+	assert.ErrorIs(t, err, client.ErrValidation)
+}
+
+func TestIntegration_EventHistory(t *testing.T) {
+	teardownDB()
+	router, svc := setupServer()
+	ctx := context.Background()
+
+	team := models.Team{TeamName: "History", Members: []models.TeamMember{
+		{UserId: "h-author", Username: "Author", IsActive: true},
+		{UserId: "h-r1", Username: "R1", IsActive: true},
+		{UserId: "h-r2", Username: "R2", IsActive: true},
+		{UserId: "h-r3", Username: "R3", IsActive: true},
+	}}
+	postRequest(t, router, "/team/add", team, http.StatusOK)
+
+	beforeCreate := time.Now()
+	var created models.PullRequest
+	require.NoError(t, json.Unmarshal(postRequest(t, router, "/pullRequest/create",
+		models.PostPullRequestCreateJSONRequestBody{PullRequestId: "PR-H1", PullRequestName: "History", AuthorId: "h-author"}, http.StatusOK), &created))
+	require.Len(t, created.AssignedReviewers, 2)
+	afterCreate := time.Now()
+
+	old := created.AssignedReviewers[0]
+	postRequest(t, router, "/pullRequest/reassign", models.PostPullRequestReassignJSONRequestBody{PullRequestId: "PR-H1", OldUserId: old}, http.StatusOK)
+	postRequest(t, router, "/pullRequest/merge", models.PostPullRequestMergeJSONRequestBody{PullRequestId: "PR-H1"}, http.StatusOK)
+	postRequest(t, router, "/team/rename", models.PostTeamRenameJSONRequestBody{TeamName: "History", NewTeamName: "Chronicle"}, http.StatusOK)
+
+	asOf := func(at time.Time, expectedStatus int) models.PullRequest {
+		path := "/pullRequest/getAsOf?pull_request_id=PR-H1&at=" + url.QueryEscape(at.Format(time.RFC3339Nano))
+		var pr models.PullRequest
+		json.Unmarshal(getRequest(t, router, path, expectedStatus), &pr)
+		return pr
+	}
+
+	asOf(beforeCreate, http.StatusNotFound)
+
+	then := asOf(afterCreate, http.StatusOK)
+	assert.ElementsMatch(t, created.AssignedReviewers, then.AssignedReviewers)
+	assert.Equal(t, models.PullRequestStatusOPEN, then.Status)
+	assert.Nil(t, then.MergedAt)
+	require.NotNil(t, then.TeamName)
+	assert.Equal(t, "History", *then.TeamName)
+
+	current, err := svc.GetPullRequest(ctx, "PR-H1")
+	require.NoError(t, err)
+	assert.NotContains(t, current.AssignedReviewers, old)
+	now := asOf(time.Now(), http.StatusOK)
+	assert.ElementsMatch(t, current.AssignedReviewers, now.AssignedReviewers)
+	assert.Equal(t, models.PullRequestStatusMERGED, now.Status)
+	assert.NotNil(t, now.MergedAt)
+	assert.Equal(t, current.TeamName, now.TeamName)
+
+	getRequest(t, router, "/pullRequest/getAsOf?pull_request_id=PR-H1&at=yesterday", http.StatusBadRequest)
+
+	// Проекции пересобираются по журналу.
+	_, err = testDB.Exec("DELETE FROM pull_requests")
+	require.NoError(t, err)
+	stats, err := svc.RebuildProjections(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.PullRequests)
+	rebuilt, err := svc.GetPullRequest(ctx, "PR-H1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, current.AssignedReviewers, rebuilt.AssignedReviewers)
+	assert.Equal(t, current.Status, rebuilt.Status)
+	assert.Equal(t, current.TeamName, rebuilt.TeamName)
+	assert.WithinDuration(t, *current.CreatedAt, *rebuilt.CreatedAt, time.Millisecond)
+	assert.WithinDuration(t, *current.MergedAt, *rebuilt.MergedAt, time.Millisecond)
+
+	var userEvents int
+	require.NoError(t, testDB.QueryRow(`SELECT COUNT(*) FROM events WHERE aggregate_type = 'user'`).Scan(&userEvents))
+	assert.Zero(t, userEvents)
+	postRequest(t, router, "/users/setIsActive", models.PostUsersSetIsActiveJSONRequestBody{UserId: "h-r1", IsActive: false}, http.StatusOK)
+	postRequest(t, router, "/users/setIsActive", models.PostUsersSetIsActiveJSONRequestBody{UserId: "h-r1", IsActive: false}, http.StatusOK)
+	var eventType string
+	require.NoError(t, testDB.QueryRow(`SELECT event_type FROM events WHERE aggregate_type = 'user' AND aggregate_id = 'h-r1'`).Scan(&eventType))
+	assert.Equal(t, "UserDeactivated", eventType)
+}
+
+func TestIntegration_EventStream(t *testing.T) {
+	teardownDB()
+	validator, err := api.NewRequestValidator(openapi.Spec)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hub := notify.NewHub()
+	go hub.Poll(ctx, 20*time.Millisecond)
+
+	r := chi.NewRouter()
+	r.NotFound(api.NotFound)
+	r.Use(api.ValidateRequests(validator))
+	api.HandlerFromMux(api.NewServer(service.NewService(testDB)).WithEventHub(hub), r)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	c := client.New(srv.URL)
+
+	for _, team := range []models.Team{
+		{TeamName: "Stream", Members: []models.TeamMember{
+			{UserId: "st-author", Username: "Author", IsActive: true},
+			{UserId: "st-r1", Username: "R1", IsActive: true},
+			{UserId: "st-r2", Username: "R2", IsActive: true},
+		}},
+		{TeamName: "Quiet", Members: []models.TeamMember{
+			{UserId: "q-author", Username: "Author", IsActive: true},
+			{UserId: "q-r1", Username: "R1", IsActive: true},
+		}},
+	} {
+		_, err := c.AddTeam(ctx, team)
+		require.NoError(t, err)
+	}
+	// Поток начинается после существующего события: номера в журнале
+	// не начинаются с 1 после очистки таблиц другими тестами.
+	_, err = c.SetUserActive(ctx, "q-r1", true)
+	require.NoError(t, err)
+	_, err = c.SetUserActive(ctx, "q-r1", false)
+	require.NoError(t, err)
+	_, err = c.SetUserActive(ctx, "q-r1", true)
+	require.NoError(t, err)
+	var start int64
+	require.NoError(t, testDB.QueryRow(`SELECT MAX(id) FROM events`).Scan(&start))
+
+	// collect читает поток, пока не придёт n событий.
+	collect := func(params client.StreamEventsParams, n int) ([]client.Event, error) {
+		streamCtx, stop := context.WithTimeout(ctx, 10*time.Second)
+		defer stop()
+		var got []client.Event
+		done := errors.New("done")
+		err := c.StreamEvents(streamCtx, params, func(ev client.Event) error {
+			got = append(got, ev)
+			if len(got) == n {
+				return done
+			}
+			return nil
+		})
+		if !errors.Is(err, done) {
+			return got, err
+		}
+		return got, nil
+	}
+
+	team := "Stream"
+	type result struct {
+		events []client.Event
+		err    error
+	}
+	streamed := make(chan result)
+	go func() {
+		events, err := collect(client.StreamEventsParams{TeamName: &team, LastEventID: &start}, 5)
+		streamed <- result{events, err}
+	}()
+
+	_, err = c.CreatePullRequest(ctx, client.CreatePullRequestRequest{PullRequestId: "PR-Q", PullRequestName: "Quiet", AuthorId: "q-author"})
+	require.NoError(t, err)
+	_, err = c.CreatePullRequest(ctx, client.CreatePullRequestRequest{PullRequestId: "PR-S", PullRequestName: "Stream", AuthorId: "st-author"})
+	require.NoError(t, err)
+	_, err = c.MergePullRequest(ctx, "PR-S")
+	require.NoError(t, err)
+	_, err = c.SetUserActive(ctx, "st-r1", false)
+	require.NoError(t, err)
+
+	res := <-streamed
+	require.NoError(t, res.err)
+	got := res.events
+	types := make([]models.EventType, len(got))
+	for i, ev := range got {
+		types[i] = ev.Type
+		if i > 0 {
+			assert.Greater(t, ev.Id, got[i-1].Id)
+		}
+	}
+	assert.Equal(t, []models.EventType{
+		models.EventTypePRCreated, models.EventTypeReviewerAssigned, models.EventTypeReviewerAssigned,
+		models.EventTypePRMerged, models.EventTypeUserDeactivated,
+	}, types)
+	assert.Equal(t, "PR-S", *got[0].PullRequestId)
+	assert.Equal(t, &team, got[0].TeamName)
+	assert.ElementsMatch(t, []string{"st-r1", "st-r2"}, []string{*got[1].UserId, *got[2].UserId})
+	assert.Equal(t, "st-r1", *got[4].UserId)
+
+	// Продолжение после обрыва: с Last-Event-ID приходят только следующие события.
+	resumed, err := collect(client.StreamEventsParams{TeamName: &team, LastEventID: &got[2].Id}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, got[3:], resumed)
+
+	user := "q-author"
+	byUser, err := collect(client.StreamEventsParams{UserID: &user, LastEventID: &start}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, models.EventTypePRCreated, byUser[0].Type)
+	assert.Equal(t, "PR-Q", *byUser[0].PullRequestId)
+	assert.Equal(t, models.EventTypeReviewerAssigned, byUser[1].Type)
+	assert.Equal(t, "q-r1", *byUser[1].UserId)
+
+	missing := "missing"
+	err = c.StreamEvents(ctx, client.StreamEventsParams{TeamName: &missing}, func(client.Event) error { return nil })
+	assert.ErrorIs(t, err, client.ErrNotFound)
+
+	req := httptest.NewRequest(http.MethodGet, "/events/stream", nil)
+	req.Header.Set("Last-Event-ID", "latest")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestIntegration_GRPC(t *testing.T) {
+	teardownDB()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hub := notify.NewHub()
+	go hub.Poll(ctx, 20*time.Millisecond)
+
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	reviewerv1.RegisterReviewerServiceServer(gs, grpcapi.NewServer(service.NewService(testDB)).WithEventHub(hub))
+	go gs.Serve(lis)
+	defer gs.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	c := reviewerv1.NewReviewerServiceClient(conn)
+
+	_, err = c.AddTeam(ctx, &reviewerv1.AddTeamRequest{Team: &reviewerv1.Team{TeamName: "rpc", Members: []*reviewerv1.TeamMember{
+		{UserId: "g1", Username: "G1", IsActive: true},
+		{UserId: "g2", Username: "G2", IsActive: true},
+		{UserId: "g3", Username: "G3", IsActive: true},
+	}}})
+	require.NoError(t, err)
+	team, err := c.GetTeam(ctx, &reviewerv1.GetTeamRequest{TeamName: "rpc"})
+	require.NoError(t, err)
+	assert.Len(t, team.Members, 3)
+	_, err = c.GetTeam(ctx, &reviewerv1.GetTeamRequest{TeamName: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Очередь ревью g2 с watch: сначала пусто, затем назначение и мёрж.
+	watch, err := c.StreamUserReviews(ctx, &reviewerv1.StreamUserReviewsRequest{UserId: "g2", Watch: true})
+	require.NoError(t, err)
+	_, err = c.GetUser(ctx, &reviewerv1.GetUserRequest{UserId: "g1"}) // поток открыт до создания PR
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	pr, err := c.CreatePullRequest(ctx, &reviewerv1.CreatePullRequestRequest{PullRequestId: "PR-G", PullRequestName: "gRPC", AuthorId: "g1"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"g2", "g3"}, pr.AssignedReviewers)
+	assert.Equal(t, reviewerv1.PullRequestStatus_PULL_REQUEST_STATUS_OPEN, pr.Status)
+	assert.Equal(t, "rpc", pr.TeamName)
+	assert.NotNil(t, pr.CreatedAt)
+	_, err = c.CreatePullRequest(ctx, &reviewerv1.CreatePullRequestRequest{PullRequestId: "PR-G", PullRequestName: "gRPC", AuthorId: "g1"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	_, err = c.CreatePullRequest(ctx, &reviewerv1.CreatePullRequestRequest{PullRequestId: "PR-X", PullRequestName: "x", AuthorId: "nobody"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	entry, err := watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "PR-G", entry.PullRequest.PullRequestId)
+	assert.False(t, entry.Removed)
+
+	_, err = c.ReassignReviewer(ctx, &reviewerv1.ReassignReviewerRequest{PullRequestId: "PR-G", OldUserId: "g1"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	merged, err := c.MergePullRequest(ctx, &reviewerv1.MergePullRequestRequest{PullRequestId: "PR-G"})
+	require.NoError(t, err)
+	assert.Equal(t, reviewerv1.PullRequestStatus_PULL_REQUEST_STATUS_MERGED, merged.Status)
+	_, err = c.ReassignReviewer(ctx, &reviewerv1.ReassignReviewerRequest{PullRequestId: "PR-G", OldUserId: "g2"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	entry, err = watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, reviewerv1.PullRequestStatus_PULL_REQUEST_STATUS_MERGED, entry.PullRequest.Status)
+
+	// Без watch поток завершается после текущей очереди.
+	snapshot, err := c.StreamUserReviews(ctx, &reviewerv1.StreamUserReviewsRequest{UserId: "g3"})
+	require.NoError(t, err)
+	entry, err = snapshot.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "PR-G", entry.PullRequest.PullRequestId)
+	_, err = snapshot.Recv()
+	assert.ErrorIs(t, err, io.EOF)
+
+	reviews, err := c.GetUserReviews(ctx, &reviewerv1.GetUserReviewsRequest{UserId: "g2"})
+	require.NoError(t, err)
+	require.Len(t, reviews.PullRequests, 1)
+	stats, err := c.GetAssignmentStats(ctx, &reviewerv1.GetAssignmentStatsRequest{TeamName: "rpc"})
+	require.NoError(t, err)
+	assert.Len(t, stats.Stats, 3)
+
+	user, err := c.SetUserActive(ctx, &reviewerv1.SetUserActiveRequest{UserId: "g3", IsActive: false})
+	require.NoError(t, err)
+	assert.False(t, user.IsActive)
+	assert.Equal(t, "rpc", user.TeamName)
+}
+
+func TestIntegration_GraphQL(t *testing.T) {
+	teardownDB()
+	h, err := graphqlapi.NewHandler(service.NewService(testDB))
+	require.NoError(t, err)
+
+	type gqlError struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	}
+	query := func(q string, vars map[string]any) (json.RawMessage, []gqlError) {
+		body, err := json.Marshal(map[string]any{"query": q, "variables": vars})
+		require.NoError(t, err)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body)))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var resp struct {
+			Data   json.RawMessage `json:"data"`
+			Errors []gqlError      `json:"errors"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp.Data, resp.Errors
+	}
+
+	_, errs := query(`mutation($input: TeamInput!) { addTeam(input: $input) { name } }`, map[string]any{
+		"input": map[string]any{"name": "dash", "members": []map[string]any{
+			{"userId": "q1", "username": "Q1", "isActive": true},
+			{"userId": "q2", "username": "Q2", "isActive": true},
+			{"userId": "q3", "username": "Q3", "isActive": true},
+		}},
+	})
+	require.Empty(t, errs)
+	for _, id := range []string{"PR-Q1", "PR-Q2"} {
+		_, errs = query(`mutation($id: ID!) { createPullRequest(input: {id: $id, name: "dash", authorId: "q1"}) { id } }`,
+			map[string]any{"id": id})
+		require.Empty(t, errs)
+	}
+	_, errs = query(`mutation { createPullRequest(input: {id: "PR-Q1", name: "dup", authorId: "q1"}) { id } }`, nil)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "PR_EXISTS", errs[0].Extensions["code"])
+	_, errs = query(`mutation { mergePullRequest(id: "PR-Q2") { status mergedAt } }`, nil)
+	require.Empty(t, errs)
+
+	// Дашборд одним запросом: команда → участники → их ревью → авторы PR.
+	data, errs := query(`{
+		teams { nodes { name members { id username reviews { id status author { username } reviewers { id } } } stats { user { id } count } } }
+	}`, nil)
+	require.Empty(t, errs)
+	var dash struct {
+		Teams struct {
+			Nodes []struct {
+				Name    string
+				Members []struct {
+					ID       string
+					Username string
+					Reviews  []struct {
+						ID        string
+						Status    string
+						Author    struct{ Username string }
+						Reviewers []struct{ ID string }
+					}
+				}
+				Stats []struct {
+					User  struct{ ID string }
+					Count int
+				}
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(data, &dash))
+	require.Len(t, dash.Teams.Nodes, 1)
+	team := dash.Teams.Nodes[0]
+	assert.Equal(t, "dash", team.Name)
+	require.Len(t, team.Members, 3)
+	assert.Empty(t, team.Members[0].Reviews, "author is never a reviewer")
+	reviews := 0
+	for _, m := range team.Members[1:] {
+		for _, pr := range m.Reviews {
+			assert.Equal(t, "Q1", pr.Author.Username)
+			assert.Contains(t, []string{"OPEN", "MERGED"}, pr.Status)
+			reviews++
+		}
+	}
+	assert.Equal(t, 4, reviews)
+	assert.Len(t, team.Stats, 3)
+
+	// Пагинация по курсору.
+	data, errs = query(`{ users(teamName: "dash", first: 2) { nodes { id } pageInfo { endCursor hasNextPage } } }`, nil)
+	require.Empty(t, errs)
+	var page struct {
+		Users struct {
+			Nodes    []struct{ ID string }
+			PageInfo struct {
+				EndCursor   *string
+				HasNextPage bool
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(data, &page))
+	require.Len(t, page.Users.Nodes, 2)
+	require.True(t, page.Users.PageInfo.HasNextPage)
+	data, errs = query(`query($after: String) { users(teamName: "dash", first: 2, after: $after) { nodes { id } pageInfo { hasNextPage } } }`,
+		map[string]any{"after": *page.Users.PageInfo.EndCursor})
+	require.Empty(t, errs)
+	require.NoError(t, json.Unmarshal(data, &page))
+	require.Len(t, page.Users.Nodes, 1)
+	assert.Equal(t, "q3", page.Users.Nodes[0].ID)
+	assert.False(t, page.Users.PageInfo.HasNextPage)
+
+	data, errs = query(`{ pullRequests(teamName: "dash", status: OPEN, first: 1) { nodes { id reviewers { id } } pageInfo { hasNextPage } } }`, nil)
+	require.Empty(t, errs)
+	var prs struct {
+		PullRequests struct {
+			Nodes []struct {
+				ID        string
+				Reviewers []struct{ ID string }
+			}
+			PageInfo struct{ HasNextPage bool }
+		}
+	}
+	require.NoError(t, json.Unmarshal(data, &prs))
+	require.Len(t, prs.PullRequests.Nodes, 1)
+	assert.Equal(t, "PR-Q1", prs.PullRequests.Nodes[0].ID)
+	assert.False(t, prs.PullRequests.PageInfo.HasNextPage)
+
+	// Переназначение на слитом PR — тот же код, что в REST.
+	_, errs = query(`mutation { reassignReviewer(pullRequestId: "PR-Q2", oldUserId: "q2") { id } }`, nil)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "PR_MERGED", errs[0].Extensions["code"])
+
+	data, errs = query(`{ user(id: "missing") { id } pullRequest(id: "missing") { id } }`, nil)
+	require.Empty(t, errs)
+	assert.JSONEq(t, `{"user": null, "pullRequest": null}`, string(data))
+}
+
+func TestIntegration_Batch(t *testing.T) {
+	teardownDB()
+	router, _ := setupServer()
+	setupUser(t, router)
+
+	batch := func(atomic bool, ops ...models.BatchOperation) models.BatchResult {
+		var res models.BatchResult
+		body := models.PostBatchJSONRequestBody{Atomic: &atomic, Operations: ops}
+		require.NoError(t, json.Unmarshal(postRequest(t, router, "/batch", body, http.StatusOK), &res))
+		require.Len(t, res.Results, len(ops))
+		return res
+	}
+	create := func(id string) models.BatchOperation {
+		return models.BatchOperation{Op: models.BatchOperationOpCreate, PullRequestId: &id, PullRequestName: ptr("batch"), AuthorId: ptr("u1")}
+	}
+	merge := func(id string) models.BatchOperation {
+		return models.BatchOperation{Op: models.BatchOperationOpMerge, PullRequestId: &id}
+	}
+	setActive := func(userID string, active bool) models.BatchOperation {
+		return models.BatchOperation{Op: models.BatchOperationOpSetIsActive, UserId: &userID, IsActive: &active}
+	}
+	code := func(item models.BatchItemResult) models.ErrorResponseErrorCode {
+		require.NotNil(t, item.Error)
+		return item.Error.Code
+	}
+
+	// Без atomic ошибки не мешают остальным, а каждая операция видит предыдущие.
+	res := batch(false,
+		setActive("u3", false),
+		create("PR-B1"),
+		merge("PR-B1"),
+		models.BatchOperation{Op: models.BatchOperationOpReassign, PullRequestId: ptr("PR-B1"), OldUserId: ptr("u2")},
+		create("PR-B1"),
+		merge("PR-missing"),
+	)
+	assert.True(t, res.Committed)
+	assert.False(t, res.Results[0].User.IsActive)
+	require.NotNil(t, res.Results[1].Pr)
+	assert.Equal(t, []string{"u2"}, res.Results[1].Pr.AssignedReviewers, "deactivated u3 must not be assigned")
+	assert.Equal(t, models.PullRequestStatusMERGED, res.Results[2].Pr.Status)
+	assert.Equal(t, models.PRMERGED, code(res.Results[3]))
+	assert.Equal(t, models.PREXISTS, code(res.Results[4]))
+	assert.Equal(t, models.NOTFOUND, code(res.Results[5]))
+	for i, st := range []models.BatchItemResultStatus{"ok", "ok", "ok", "failed", "failed", "failed"} {
+		assert.Equal(t, st, res.Results[i].Status, "operation %d", i)
+	}
+
+	// atomic: первая ошибка отменяет весь пакет.
+	res = batch(true, setActive("u3", true), create("PR-B2"), create("PR-B1"), merge("PR-B2"))
+	assert.False(t, res.Committed)
+	for i, st := range []models.BatchItemResultStatus{"rolled_back", "rolled_back", "failed", "skipped"} {
+		assert.Equal(t, st, res.Results[i].Status, "operation %d", i)
+	}
+	assert.Nil(t, res.Results[0].User)
+	assert.Equal(t, models.PREXISTS, code(res.Results[2]))
+	getRequest(t, router, "/pullRequest/get?pull_request_id=PR-B2", http.StatusNotFound)
+	var user models.User
+	json.Unmarshal(getRequest(t, router, "/users/get?user_id=u3", http.StatusOK), &user)
+	assert.False(t, user.IsActive)
+
+	// Внутри atomic-пакета операции тоже видят предыдущие: u3 снова доступен.
+	res = batch(true, setActive("u3", true), create("PR-B3"), merge("PR-B3"))
+	assert.True(t, res.Committed)
+	assert.ElementsMatch(t, []string{"u2", "u3"}, res.Results[1].Pr.AssignedReviewers)
+	var pr models.PullRequest
+	json.Unmarshal(getRequest(t, router, "/pullRequest/get?pull_request_id=PR-B3", http.StatusOK), &pr)
+	assert.Equal(t, models.PullRequestStatusMERGED, pr.Status)
+
+	// Операция без нужных полей отклоняет пакет целиком.
+	postRequest(t, router, "/batch", models.PostBatchJSONRequestBody{Operations: []models.BatchOperation{
+		create("PR-B4"), {Op: models.BatchOperationOpMerge},
+	}}, http.StatusBadRequest)
+	getRequest(t, router, "/pullRequest/get?pull_request_id=PR-B4", http.StatusNotFound)
+}
+
+// TestIntegration_BatchDatabaseFailure проверяет, что сбой базы в операции
+// пакета не теряет результаты остальных операций, а в atomic-режиме отвечает
+// 500, а не результатом операции.
+func TestIntegration_BatchDatabaseFailure(t *testing.T) {
+	teardownDB()
+	router, _ := setupServer()
+	setupUser(t, router)
+
+	failInserts(t, "pull_requests", "PR-BOOM")
+
+	create := func(id string) models.BatchOperation {
+		return models.BatchOperation{Op: models.BatchOperationOpCreate, PullRequestId: &id, PullRequestName: ptr("batch"), AuthorId: ptr("u1")}
+	}
+
+	var res models.BatchResult
+	body := models.PostBatchJSONRequestBody{Operations: []models.BatchOperation{create("PR-F1"), create("PR-BOOM"), create("PR-F2")}}
+	require.NoError(t, json.Unmarshal(postRequest(t, router, "/batch", body, http.StatusOK), &res))
+	assert.True(t, res.Committed)
+	require.Len(t, res.Results, 3)
+	for i, st := range []models.BatchItemResultStatus{"ok", "failed", "ok"} {
+		assert.Equal(t, st, res.Results[i].Status, "operation %d", i)
+	}
+	require.NotNil(t, res.Results[1].Error)
+	assert.Equal(t, models.INTERNALERROR, res.Results[1].Error.Code)
+	getRequest(t, router, "/pullRequest/get?pull_request_id=PR-F1", http.StatusOK)
+	getRequest(t, router, "/pullRequest/get?pull_request_id=PR-F2", http.StatusOK)
+
+	atomic := true
+	body = models.PostBatchJSONRequestBody{Atomic: &atomic, Operations: []models.BatchOperation{create("PR-F3"), create("PR-BOOM")}}
+	postRequest(t, router, "/batch", body, http.StatusInternalServerError)
+	getRequest(t, router, "/pullRequest/get?pull_request_id=PR-F3", http.StatusNotFound)
+}
+
+func TestIntegration_ReproducibleSelection(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC)
+	team := models.Team{TeamName: "seeded", Members: []models.TeamMember{}}
+	for i := 1; i <= 6; i++ {
+		id := fmt.Sprintf("s%d", i)
+		team.Members = append(team.Members, models.TeamMember{UserId: id, Username: id, IsActive: true})
+	}
+
+	// run с preview перед каждым созданием PR делает пробный выбор.
+	run := func(preview bool) map[string][]string {
+		teardownDB()
+		svc := service.NewService(testDB, service.WithSeed(42), service.WithClock(func() time.Time { return at }))
+		require.NoError(t, svc.AddTeam(ctx, team))
+
+		assigned := map[string][]string{}
+		for i := 1; i <= 5; i++ {
+			if preview {
+				_, err := svc.PreviewAssignment(ctx, models.PostPullRequestPreviewAssignmentJSONRequestBody{AuthorId: "s1"})
+				require.NoError(t, err)
+			}
+			pr, err := svc.CreatePullRequest(ctx, models.PostPullRequestCreateJSONRequestBody{
+				PullRequestId: fmt.Sprintf("PR-S%d", i), PullRequestName: "seeded", AuthorId: "s1",
+			})
+			require.NoError(t, err)
+			assert.True(t, pr.CreatedAt.Equal(at))
+			assigned[pr.PullRequestId] = pr.AssignedReviewers
+		}
+		pr, err := svc.ReassignReviewer(ctx, models.PostPullRequestReassignJSONRequestBody{
+			PullRequestId: "PR-S1", OldUserId: assigned["PR-S1"][0],
+		})
+		require.NoError(t, err)
+		assigned["PR-S1 reassigned"] = pr.AssignedReviewers
+		return assigned
+	}
+
+	first := run(false)
+	assert.Equal(t, first, run(false), "same seed and requests must give the same assignments")
+	assert.Equal(t, first, run(true), "previews must not shift the seeds of real assignments")
+
+	// Каждое назначение воспроизводится по записанному решению.
+	rows, err := testDB.Query(`SELECT aggregate_id, data FROM events WHERE event_type = 'ReviewerAssigned' ORDER BY id`)
+	require.NoError(t, err)
+	defer rows.Close()
+	n := 0
+	for rows.Next() {
+		var (
+			prID string
+			data string
+			d    struct {
+				ReviewerID string                    `json:"reviewer_id"`
+				Selection  *models.ReviewerSelection `json:"selection"`
+			}
+		)
+		require.NoError(t, rows.Scan(&prID, &data))
+		require.NoError(t, json.Unmarshal([]byte(data), &d))
+		require.NotNil(t, d.Selection, prID)
+		assert.Equal(t, "shuffle-v1", d.Selection.Strategy)
+		assert.Contains(t, service.ReplaySelection(*d.Selection), d.ReviewerID, prID)
+		n++
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, 11, n)
+}
+
+func TestIntegration_PreviewAssignment(t *testing.T) {
+	teardownDB()
+	router, _ := setupServer()
+
+	postRequest(t, router, "/team/add", models.Team{TeamName: "Payments", Members: []models.TeamMember{
+		{UserId: "lead", Username: "Lead", IsActive: true},
+	}}, http.StatusOK)
+	postRequest(t, router, "/team/add", models.Team{TeamName: "Core", ParentTeam: ptr("Payments"), Members: []models.TeamMember{
+		{UserId: "c1", Username: "C1", IsActive: true},
+		{UserId: "c2", Username: "C2", IsActive: true},
+		{UserId: "c3", Username: "C3", IsActive: false},
+	}}, http.StatusOK)
+	postRequest(t, router, "/team/add", models.Team{TeamName: "Risk", ParentTeam: ptr("Payments"), Members: []models.TeamMember{
+		{UserId: "r1", Username: "R1", IsActive: true},
+		{UserId: "r2", Username: "R2", IsActive: true},
+	}}, http.StatusOK)
+	postRequest(t, router, "/team/setEscalationPolicy", models.PostTeamSetEscalationPolicyJSONRequestBody{
+		TeamName: "Core", EscalationPolicy: models.EscalationPolicySiblingsThenParent,
+	}, http.StatusOK)
+
+	var preview models.AssignmentPreview
+	require.NoError(t, json.Unmarshal(postRequest(t, router, "/pullRequest/previewAssignment",
+		models.PostPullRequestPreviewAssignmentJSONRequestBody{AuthorId: "c1", PullRequestId: ptr("PR-P1")}, http.StatusOK), &preview))
+	assert.Equal(t, "Core", preview.TeamName)
+	require.Len(t, preview.Reviewers, 2)
+	assert.Equal(t, "c2", preview.Reviewers[0])
+	assert.Contains(t, []string{"r1", "r2"}, preview.Reviewers[1])
+	assert.Equal(t, preview.Reviewers, service.ReplaySelection(preview.Selection))
+
+	// Родительская команда не просматривается: ревьюверов хватило раньше.
+	reasons := map[string]models.AssignmentCandidateReason{}
+	for _, c := range preview.Candidates {
+		reasons[c.UserId] = c.Reason
+		assert.Equal(t, c.Reason == models.AssignmentCandidateReasonSelected || c.Reason == models.AssignmentCandidateReasonEligible, c.Included, c.UserId)
+	}
+	other := "r1"
+	if preview.Reviewers[1] == "r1" {
+		other = "r2"
+	}
+	assert.Equal(t, map[string]models.AssignmentCandidateReason{
+		"c1":                 models.AssignmentCandidateReasonAuthor,
+		"c2":                 models.AssignmentCandidateReasonSelected,
+		"c3":                 models.AssignmentCandidateReasonInactive,
+		preview.Reviewers[1]: models.AssignmentCandidateReasonSelected,
+		other:                models.AssignmentCandidateReasonEligible,
+	}, reasons)
+	assert.Equal(t, 1, preview.Candidates[len(preview.Candidates)-1].Tier)
+
+	// Предпросмотр ничего не записывает.
+	var count int
+	require.NoError(t, testDB.QueryRow(`SELECT COUNT(*) FROM events WHERE aggregate_id = 'PR-P1'`).Scan(&count))
+	assert.Zero(t, count)
+	getRequest(t, router, "/pullRequest/get?pull_request_id=PR-P1", http.StatusNotFound)
+
+	postRequest(t, router, "/pullRequest/create", models.PostPullRequestCreateJSONRequestBody{
+		PullRequestId: "PR-P1", PullRequestName: "Preview", AuthorId: "c1",
+	}, http.StatusOK)
+	postRequest(t, router, "/pullRequest/previewAssignment",
+		models.PostPullRequestPreviewAssignmentJSONRequestBody{AuthorId: "c1", PullRequestId: ptr("PR-P1")}, http.StatusConflict)
+	postRequest(t, router, "/pullRequest/previewAssignment",
+		models.PostPullRequestPreviewAssignmentJSONRequestBody{AuthorId: "ghost"}, http.StatusNotFound)
+	postRequest(t, router, "/pullRequest/previewAssignment",
+		models.PostPullRequestPreviewAssignmentJSONRequestBody{AuthorId: "c1", TeamName: ptr("Risk")}, http.StatusBadRequest)
+}
+
+// TestIntegration_ReviewCapacityConcurrent проверяет, что параллельные
+// создания PR разными авторами не назначают ревьюверу больше ревью, чем
+// позволяет лимит, и что авторы, выбирающие друг друга, не блокируют друг
+// друга по кругу.
+func TestIntegration_ReviewCapacityConcurrent(t *testing.T) {
+	teardownDB()
+	ctx := context.Background()
+	svc := service.NewService(testDB)
+
+	// Каждый участник Race — автор своего PR и кандидат на чужие.
+	team := models.Team{TeamName: "Race", Members: []models.TeamMember{}}
+	for i := 1; i <= 6; i++ {
+		id := fmt.Sprintf("race-r%d", i)
+		team.Members = append(team.Members, models.TeamMember{UserId: id, Username: id, IsActive: true})
+	}
+	require.NoError(t, svc.AddTeam(ctx, team))
+	_, err := svc.SetReviewCapacity(ctx, models.PostTeamSetReviewCapacityJSONRequestBody{
+		TeamName: "Race", DefaultMaxOpenReviews: ptr(1), CapacityPolicy: models.CapacityPolicyQueue,
+	})
+	require.NoError(t, err)
+	// В Pair без лимита два участника: каждый может выбрать только другого.
+	require.NoError(t, svc.AddTeam(ctx, models.Team{TeamName: "Pair", Members: []models.TeamMember{
+		{UserId: "pair-a", Username: "A", IsActive: true},
+		{UserId: "pair-b", Username: "B", IsActive: true},
+	}}))
+
+	authors := []string{"pair-a", "pair-b"}
+	for _, m := range team.Members {
+		authors = append(authors, m.UserId)
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, len(authors))
+	for _, author := range authors {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.CreatePullRequest(ctx, models.PostPullRequestCreateJSONRequestBody{
+				PullRequestId: "PR-" + author, PullRequestName: "race", AuthorId: author,
+			})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	for _, pair := range [][2]string{{"pair-a", "pair-b"}, {"pair-b", "pair-a"}} {
+		pr, err := svc.GetPullRequest(ctx, "PR-"+pair[0])
+		require.NoError(t, err)
+		assert.Equal(t, []string{pair[1]}, pr.AssignedReviewers)
+	}
+
+	rows, err := testDB.Query(`SELECT reviewer_id, COUNT(*) FROM pr_reviewers GROUP BY reviewer_id`)
+	require.NoError(t, err)
+	defer rows.Close()
+	total := 0
+	for rows.Next() {
+		var (
+			id string
+			n  int
+		)
+		require.NoError(t, rows.Scan(&id, &n))
+		assert.Equal(t, 1, n, "reviewer %s is over the limit", id)
+		if strings.HasPrefix(id, "race-") {
+			total += n
+		}
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, 6, total)
+}
+
+func TestIntegration_ReviewCapacity(t *testing.T) {
+	teardownDB()
+	router, _ := setupServer()
+
+	postRequest(t, router, "/team/add", models.Team{TeamName: "Cap", Members: []models.TeamMember{
+		{UserId: "a1", Username: "A1", IsActive: true},
+		{UserId: "b1", Username: "B1", IsActive: true},
+		{UserId: "b2", Username: "B2", IsActive: true},
+		{UserId: "b3", Username: "B3", IsActive: true},
+	}}, http.StatusOK)
+	setCapacity := func(policy models.CapacityPolicy) {
+		var team models.Team
+		json.Unmarshal(postRequest(t, router, "/team/setReviewCapacity", models.PostTeamSetReviewCapacityJSONRequestBody{
+			TeamName: "Cap", DefaultMaxOpenReviews: ptr(1), CapacityPolicy: policy,
+		}, http.StatusOK), &team)
+		require.NotNil(t, team.DefaultMaxOpenReviews)
+		assert.Equal(t, 1, *team.DefaultMaxOpenReviews)
+		assert.Equal(t, policy, *team.CapacityPolicy)
+	}
+	create := func(id string, status int) models.PullRequest {
+		var pr models.PullRequest
+		json.Unmarshal(postRequest(t, router, "/pullRequest/create", models.PostPullRequestCreateJSONRequestBody{
+			PullRequestId: id, PullRequestName: "capacity", AuthorId: "a1",
+		}, status), &pr)
+		return pr
+	}
+
+	setCapacity(models.CapacityPolicyLeastLoaded)
+	var user models.User
+	json.Unmarshal(postRequest(t, router, "/users/setMaxOpenReviews", models.PostUsersSetMaxOpenReviewsJSONRequestBody{
+		UserId: "b3", MaxOpenReviews: ptr(0),
+	}, http.StatusOK), &user)
+	require.NotNil(t, user.MaxOpenReviews)
+	assert.Equal(t, 0, *user.MaxOpenReviews)
+
+	// b3 не может брать ревью, b1 и b2 после PR-C1 достигают лимита команды.
+	assert.ElementsMatch(t, []string{"b1", "b2"}, create("PR-C1", http.StatusOK).AssignedReviewers)
+
+	setCapacity(models.CapacityPolicyFail)
+	resp := postRequest(t, router, "/pullRequest/create", models.PostPullRequestCreateJSONRequestBody{
+		PullRequestId: "PR-C2", PullRequestName: "capacity", AuthorId: "a1",
+	}, http.StatusConflict)
+	assert.Contains(t, string(resp), string(models.NOCANDIDATE))
+	getRequest(t, router, "/pullRequest/get?pull_request_id=PR-C2", http.StatusNotFound)
+
+	// Предпросмотр не отклоняется, а показывает, почему выбор пуст.
+	var failPreview models.AssignmentPreview
+	json.Unmarshal(postRequest(t, router, "/pullRequest/previewAssignment",
+		models.PostPullRequestPreviewAssignmentJSONRequestBody{AuthorId: "a1"}, http.StatusOK), &failPreview)
+	assert.Empty(t, failPreview.Reviewers)
+	assert.Empty(t, failPreview.Selection.Candidates)
+	failReasons := map[string]models.AssignmentCandidateReason{}
+	for _, c := range failPreview.Candidates {
+		failReasons[c.UserId] = c.Reason
+	}
+	assert.Equal(t, map[string]models.AssignmentCandidateReason{
+		"a1": models.AssignmentCandidateReasonAuthor,
+		"b1": models.AssignmentCandidateReasonAtCapacity,
+		"b2": models.AssignmentCandidateReasonAtCapacity,
+		"b3": models.AssignmentCandidateReasonAtCapacity,
+	}, failReasons)
+
+	setCapacity(models.CapacityPolicyQueue)
+	assert.Empty(t, create("PR-C2", http.StatusOK).AssignedReviewers)
+
+	// Сверх лимита назначаются наименее загруженные: b3 (0 ревью), затем b1 (по user_id).
+	setCapacity(models.CapacityPolicyLeastLoaded)
+	var preview models.AssignmentPreview
+	json.Unmarshal(postRequest(t, router, "/pullRequest/previewAssignment",
+		models.PostPullRequestPreviewAssignmentJSONRequestBody{AuthorId: "a1"}, http.StatusOK), &preview)
+	assert.Equal(t, []string{"b3", "b1"}, preview.Reviewers)
+	assert.Equal(t, []string{"b3", "b1"}, preview.Selection.Overflow)
+	reasons := map[string]models.AssignmentCandidateReason{}
+	for _, c := range preview.Candidates {
+		reasons[c.UserId] = c.Reason
+	}
+	assert.Equal(t, models.AssignmentCandidateReasonAtCapacity, reasons["b2"])
+	assert.ElementsMatch(t, []string{"b3", "b1"}, create("PR-C3", http.StatusOK).AssignedReviewers)
+
+	// После мержа PR-C1 у b2 освобождается место.
+	postRequest(t, router, "/pullRequest/merge", models.PostPullRequestMergeJSONRequestBody{PullRequestId: "PR-C1"}, http.StatusOK)
+	var pr models.PullRequest
+	json.Unmarshal(postRequest(t, router, "/pullRequest/reassign", models.PostPullRequestReassignJSONRequestBody{
+		PullRequestId: "PR-C3", OldUserId: "b3",
+	}, http.StatusOK), &pr)
+	assert.ElementsMatch(t, []string{"b1", "b2"}, pr.AssignedReviewers)
+
+	setCapacity(models.CapacityPolicyFail)
+	resp = postRequest(t, router, "/pullRequest/reassign", models.PostPullRequestReassignJSONRequestBody{
+		PullRequestId: "PR-C3", OldUserId: "b1",
+	}, http.StatusBadRequest)
+	assert.Contains(t, string(resp), string(models.NOCANDIDATE))
+
+	setCapacity(models.CapacityPolicyLeastLoaded)
+	json.Unmarshal(postRequest(t, router, "/pullRequest/reassign", models.PostPullRequestReassignJSONRequestBody{
+		PullRequestId: "PR-C3", OldUserId: "b1",
+	}, http.StatusOK), &pr)
+	assert.ElementsMatch(t, []string{"b2", "b3"}, pr.AssignedReviewers)
+
+	// По queue ревьювер снимается, а слот ждёт освободившегося кандидата.
+	setCapacity(models.CapacityPolicyQueue)
+	postRequest(t, router, "/users/setMaxOpenReviews", models.PostUsersSetMaxOpenReviewsJSONRequestBody{
+		UserId: "b1", MaxOpenReviews: ptr(0),
+	}, http.StatusOK)
+	json.Unmarshal(postRequest(t, router, "/pullRequest/reassign", models.PostPullRequestReassignJSONRequestBody{
+		PullRequestId: "PR-C3", OldUserId: "b2",
+	}, http.StatusOK), &pr)
+	assert.Equal(t, []string{"b3"}, pr.AssignedReviewers)
+	assert.Equal(t, 1, pr.PendingReviewers)
+
+	// Без собственного лимита действует лимит команды.
+	var cleared models.User
+	json.Unmarshal(postRequest(t, router, "/users/setMaxOpenReviews", models.PostUsersSetMaxOpenReviewsJSONRequestBody{UserId: "b3"}, http.StatusOK), &cleared)
+	assert.Equal(t, "b3", cleared.UserId)
+	assert.Nil(t, cleared.MaxOpenReviews)
+}
+
+func TestIntegration_PendingReviewers(t *testing.T) {
+	teardownDB()
+	router, svc := setupServer()
+	ctx := context.Background()
+
+	postRequest(t, router, "/team/add", models.Team{TeamName: "Small", Members: []models.TeamMember{
+		{UserId: "p1", Username: "P1", IsActive: true},
+		{UserId: "p2", Username: "P2", IsActive: true},
+		{UserId: "p3", Username: "P3", IsActive: false},
+	}}, http.StatusOK)
+	get := func(id string) models.PullRequest {
+		var pr models.PullRequest
+		require.NoError(t, json.Unmarshal(getRequest(t, router, "/pullRequest/get?pull_request_id="+id, http.StatusOK), &pr))
+		return pr
+	}
+
+	var pr models.PullRequest
+	json.Unmarshal(postRequest(t, router, "/pullRequest/create", models.PostPullRequestCreateJSONRequestBody{
+		PullRequestId: "PR-Q1", PullRequestName: "pending", AuthorId: "p1",
+	}, http.StatusOK), &pr)
+	assert.Equal(t, []string{"p2"}, pr.AssignedReviewers)
+	assert.Equal(t, 1, pr.PendingReviewers)
+	created := time.Now()
+
+	// Кандидатов нет — слот остаётся ожидающим.
+	n, err := svc.FillPendingReviewers(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	postRequest(t, router, "/users/setIsActive", models.PostUsersSetIsActiveJSONRequestBody{UserId: "p3", IsActive: true}, http.StatusOK)
+	n, err = svc.FillPendingReviewers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	pr = get("PR-Q1")
+	assert.ElementsMatch(t, []string{"p2", "p3"}, pr.AssignedReviewers)
+	assert.Zero(t, pr.PendingReviewers)
+
+	// История и пересборка проекций сохраняют ожидающие слоты.
+	asOf, err := svc.GetPullRequestAsOf(ctx, "PR-Q1", created)
+	require.NoError(t, err)
+	assert.Equal(t, 1, asOf.PendingReviewers)
+
+	// С политикой queue занятые ревьюверы не назначаются, а освободившись —
+	// получают ожидающий PR.
+	postRequest(t, router, "/team/setReviewCapacity", models.PostTeamSetReviewCapacityJSONRequestBody{
+		TeamName: "Small", DefaultMaxOpenReviews: ptr(1), CapacityPolicy: models.CapacityPolicyQueue,
+	}, http.StatusOK)
+	json.Unmarshal(postRequest(t, router, "/pullRequest/create", models.PostPullRequestCreateJSONRequestBody{
+		PullRequestId: "PR-Q2", PullRequestName: "queued", AuthorId: "p1",
+	}, http.StatusOK), &pr)
+	assert.Empty(t, pr.AssignedReviewers)
+	assert.Equal(t, 2, pr.PendingReviewers)
+
+	n, err = svc.FillPendingReviewers(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	postRequest(t, router, "/pullRequest/merge", models.PostPullRequestMergeJSONRequestBody{PullRequestId: "PR-Q1"}, http.StatusOK)
+	n, err = svc.FillPendingReviewers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	_, err = svc.RebuildProjections(ctx)
+	require.NoError(t, err)
+	pr = get("PR-Q2")
+	assert.ElementsMatch(t, []string{"p2", "p3"}, pr.AssignedReviewers)
+	assert.Zero(t, pr.PendingReviewers)
+	assert.Zero(t, get("PR-Q1").PendingReviewers)
+}
+
+// TestIntegration_PendingReviewersContinueOnError проверяет, что сбой на
+// одном PR не мешает заполнить ожидающие слоты остальных.
+func TestIntegration_PendingReviewersContinueOnError(t *testing.T) {
+	teardownDB()
+	router, svc := setupServer()
+	ctx := context.Background()
+
+	postRequest(t, router, "/team/add", models.Team{TeamName: "Fill", Members: []models.TeamMember{
+		{UserId: "f1", Username: "F1", IsActive: true},
+		{UserId: "f2", Username: "F2", IsActive: false},
+		{UserId: "f3", Username: "F3", IsActive: false},
+	}}, http.StatusOK)
+	for _, id := range []string{"PR-FAIL", "PR-FILL"} {
+		var pr models.PullRequest
+		json.Unmarshal(postRequest(t, router, "/pullRequest/create", models.PostPullRequestCreateJSONRequestBody{
+			PullRequestId: id, PullRequestName: "pending", AuthorId: "f1",
+		}, http.StatusOK), &pr)
+		assert.Equal(t, 2, pr.PendingReviewers)
+	}
+	postRequest(t, router, "/users/setIsActive", models.PostUsersSetIsActiveJSONRequestBody{UserId: "f2", IsActive: true}, http.StatusOK)
+
+	// Старший PR-FAIL обрабатывается первым и падает.
+	failInserts(t, "pr_reviewers", "PR-FAIL")
+	n, err := svc.FillPendingReviewers(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "PR-FAIL")
+	assert.Equal(t, 1, n)
+
+	var pr models.PullRequest
+	require.NoError(t, json.Unmarshal(getRequest(t, router, "/pullRequest/get?pull_request_id=PR-FILL", http.StatusOK), &pr))
+	assert.Equal(t, []string{"f2"}, pr.AssignedReviewers)
+	assert.Equal(t, 1, pr.PendingReviewers)
+}
+
+func TestIntegration_Migrator(t *testing.T) {
+	teardownDB()
+	m, err := database.NewMigrator(testDB, "prdb_test", testMigrationsURL)
+	require.NoError(t, err)
+	defer m.Close()
+
+	require.NoError(t, m.Check())
+	status, err := m.Status()
+	require.NoError(t, err)
+	latest := status.Latest
+	assert.Equal(t, latest, status.Version)
+	assert.True(t, status.Migrations[len(status.Migrations)-1].Applied)
+
+	require.NoError(t, m.Down(1))
+	assert.ErrorIs(t, m.Check(), database.ErrSchemaOutdated)
+	status, err = m.Status()
+	require.NoError(t, err)
+	assert.False(t, status.Migrations[len(status.Migrations)-1].Applied)
+	require.NoError(t, m.Up())
+	require.NoError(t, m.Check())
+
+	_, err = testDB.Exec("UPDATE schema_migrations SET dirty = true")
+	require.NoError(t, err)
+	assert.ErrorIs(t, m.Check(), database.ErrSchemaDirty)
+
+	require.NoError(t, m.Force(int(latest)+1))
+	assert.ErrorIs(t, m.Check(), database.ErrSchemaTooNew)
+
+	require.NoError(t, m.Force(int(latest)))
+	require.NoError(t, m.Check())
+}
+
 func postRequest(t *testing.T, router *chi.Mux, path string, body any, expectedStatus int) []byte {
 	var buf bytes.Buffer
 	if body != nil {
@@ -206,6 +2132,94 @@ func postRequest(t *testing.T, router *chi.Mux, path string, body any, expectedS
 	return rec.Body.Bytes()
 }
 
+func postWithKey(t *testing.T, router *chi.Mux, path, key string, body any, expectedStatus int) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(body)
+
+	req := httptest.NewRequest(http.MethodPost, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, expectedStatus, rec.Code, "Path: %s, Response: %s", path, rec.Body.String())
+	return rec
+}
+
+func patchRequest(t *testing.T, router *chi.Mux, path string, body any, expectedStatus int) []byte {
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(body)
+
+	req := httptest.NewRequest(http.MethodPatch, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, expectedStatus, rec.Code, "Path: %s, Response: %s", path, rec.Body.String())
+	return rec.Body.Bytes()
+}
+
+func postRaw(t *testing.T, router *chi.Mux, path, contentType, body string, expectedStatus int) []byte {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, expectedStatus, rec.Code, "Path: %s, Response: %s", path, rec.Body.String())
+	return rec.Body.Bytes()
+}
+
+func scimRequest(t *testing.T, router *chi.Mux, method, path, body string, expectedStatus int) []byte {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/scim+json")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, expectedStatus, rec.Code, "Path: %s, Response: %s", path, rec.Body.String())
+	return rec.Body.Bytes()
+}
+
+func getRequest(t *testing.T, router *chi.Mux, path string, expectedStatus int) []byte {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, expectedStatus, rec.Code, "Path: %s, Response: %s", path, rec.Body.String())
+	return rec.Body.Bytes()
+}
+
+// failInserts ставит на table триггер, который имитирует сбой базы при
+// вставке строки с pull_request_id = prID. Триггер снимается в конце теста.
+func failInserts(t *testing.T, table, prID string) {
+	t.Helper()
+	if database.DialectOf(testDB) == database.SQLite {
+		_, err := testDB.Exec(fmt.Sprintf(`CREATE TRIGGER fail_inserts BEFORE INSERT ON %s
+			WHEN NEW.pull_request_id = '%s' BEGIN SELECT RAISE(ABORT, 'injected failure'); END`, table, prID))
+		require.NoError(t, err)
+		t.Cleanup(func() { testDB.Exec(`DROP TRIGGER fail_inserts`) })
+		return
+	}
+	_, err := testDB.Exec(fmt.Sprintf(`CREATE FUNCTION fail_inserts() RETURNS trigger AS $$
+		BEGIN
+			IF NEW.pull_request_id = '%s' THEN RAISE EXCEPTION 'injected failure'; END IF;
+			RETURN NEW;
+		END $$ LANGUAGE plpgsql`, prID))
+	require.NoError(t, err)
+	t.Cleanup(func() { testDB.Exec(`DROP FUNCTION fail_inserts()`) })
+	_, err = testDB.Exec(fmt.Sprintf(`CREATE TRIGGER fail_inserts BEFORE INSERT ON %s
+		FOR EACH ROW EXECUTE FUNCTION fail_inserts()`, table))
+	require.NoError(t, err)
+	t.Cleanup(func() { testDB.Exec(fmt.Sprintf(`DROP TRIGGER fail_inserts ON %s`, table)) })
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
 func setupUser(t *testing.T, router *chi.Mux) {
 	team := models.Team{
 		TeamName: "T1",